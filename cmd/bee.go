@@ -19,6 +19,7 @@ import (
 	"github.com/beego/bee/v2/cmd/commands"
 	_ "github.com/beego/bee/v2/cmd/commands/api"
 	_ "github.com/beego/bee/v2/cmd/commands/bale"
+	_ "github.com/beego/bee/v2/cmd/commands/beeconfig"
 	_ "github.com/beego/bee/v2/cmd/commands/beefix"
 	_ "github.com/beego/bee/v2/cmd/commands/beegopro"
 	_ "github.com/beego/bee/v2/cmd/commands/dev"
@@ -30,6 +31,7 @@ import (
 	_ "github.com/beego/bee/v2/cmd/commands/new"
 	_ "github.com/beego/bee/v2/cmd/commands/pack"
 	_ "github.com/beego/bee/v2/cmd/commands/rs"
+	_ "github.com/beego/bee/v2/cmd/commands/routes"
 	_ "github.com/beego/bee/v2/cmd/commands/run"
 	_ "github.com/beego/bee/v2/cmd/commands/server"
 	_ "github.com/beego/bee/v2/cmd/commands/update"
@@ -25,6 +25,7 @@ import (
 	"github.com/beego/bee/v2/cmd/commands"
 	"github.com/beego/bee/v2/cmd/commands/version"
 	"github.com/beego/bee/v2/generate"
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	beeLogger "github.com/beego/bee/v2/logger"
 	"github.com/beego/bee/v2/utils"
 )
@@ -38,7 +39,7 @@ var CmdApiapp = &commands.Command{
   now default support generate a go modules project.
 
   {{"Example:"|bold}}
-      $ bee api [appname] [-tables=""] [-driver=mysql] [-conn="root:@tcp(127.0.0.1:3306)/test"]  [-gopath=false] [-beego=v1.12.3]
+      $ bee api [appname] [-tables=""] [-driver=mysql] [-conn="root:@tcp(127.0.0.1:3306)/test"] [-schema=public]  [-gopath=false] [-beego=v1.12.3]
 
   If 'conn' argument is empty, the command will generate an example API application. Otherwise the command
   will connect to your database and generate models based on the existing tables.
@@ -558,8 +559,11 @@ func init() {
 	CmdApiapp.Flag.Var(&generate.Tables, "tables", "List of table names separated by a comma.")
 	CmdApiapp.Flag.Var(&generate.SQLDriver, "driver", "Database driver. Either mysql, postgres or sqlite.")
 	CmdApiapp.Flag.Var(&generate.SQLConn, "conn", "Connection string used by the driver to connect to a database instance.")
+	CmdApiapp.Flag.Var(&generate.Schema, "schema", "PostgreSQL schema of the tables. Default is all the schemas, or the appcode.schema of bee.json.")
+	CmdApiapp.Flag.Var(&generate.Arch, "arch", "Architecture of the code generated from the database. Either flat (default) or layered.")
 	CmdApiapp.Flag.Var(&gopath, "gopath", "Support go path,default false")
 	CmdApiapp.Flag.Var(&beegoVersion, "beego", "set beego version,only take effect by go mod")
+	CmdApiapp.AddDryRunFlag()
 	commands.AvailableCommands = append(commands.AvailableCommands, CmdApiapp)
 }
 
@@ -613,17 +617,17 @@ func createAPI(cmd *commands.Command, args []string) int {
 
 	beeLogger.Log.Info("Creating API...")
 
-	os.MkdirAll(appPath, 0755)
+	generated.MkdirAll(appPath)
 	if gopath != `true` { //generate first for calc model name
 		fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "go.mod"), "\x1b[0m")
 		utils.WriteToFile(path.Join(appPath, "go.mod"), fmt.Sprintf(goMod, packPath, utils.GetGoVersionSkipMinor(), beegoVersion.String()))
 	}
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", appPath, "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "conf"), 0755)
+	generated.MkdirAll(path.Join(appPath, "conf"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "conf"), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "controllers"), 0755)
+	generated.MkdirAll(path.Join(appPath, "controllers"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "controllers"), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "tests"), 0755)
+	generated.MkdirAll(path.Join(appPath, "tests"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "tests"), "\x1b[0m")
 
 	if generate.SQLConn != "" {
@@ -651,16 +655,16 @@ func createAPI(cmd *commands.Command, args []string) int {
 		beeLogger.Log.Infof("Using '%s' as 'driver'", generate.SQLDriver)
 		beeLogger.Log.Infof("Using '%s' as 'conn'", generate.SQLConn)
 		beeLogger.Log.Infof("Using '%s' as 'tables'", generate.Tables)
-		generate.GenerateAppcode(string(generate.SQLDriver), string(generate.SQLConn), "3", string(generate.Tables), appPath)
+		generate.GenerateAppcode(string(generate.SQLDriver), string(generate.SQLConn), "3", string(generate.Arch), string(generate.Tables), appPath, generate.AppcodeOptionsFromConfig(generate.Schema.String()))
 	} else {
 		fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "conf", "app.conf"), "\x1b[0m")
 		confContent := strings.Replace(apiconf, "{{.Appname}}", appName, -1)
 		confContent = strings.Replace(confContent, "{{.SQLConnStr}}", "", -1)
 		utils.WriteToFile(path.Join(appPath, "conf", "app.conf"), confContent)
 
-		os.Mkdir(path.Join(appPath, "models"), 0755)
+		generated.MkdirAll(path.Join(appPath, "models"))
 		fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "models"), "\x1b[0m")
-		os.Mkdir(path.Join(appPath, "routers"), 0755)
+		generated.MkdirAll(path.Join(appPath, "routers"))
 		fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "routers")+string(path.Separator), "\x1b[0m")
 
 		fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "controllers", "object.go"), "\x1b[0m")
@@ -59,6 +59,9 @@ func init() {
 }
 
 func runBale(cmd *commands.Command, args []string) int {
+	if err := config.Resolved("bale"); err != nil {
+		beeLogger.Log.Fatalf("Invalid configuration: %s", err)
+	}
 	// 首先删除并创建一个名为 bale 的目录，然后遍历配置中的 Dirs 路径，将指定目录中的所有静态文件进行打包和压缩
 	os.RemoveAll("bale")
 	os.Mkdir("bale", os.ModePerm)
@@ -0,0 +1,56 @@
+package beeconfig
+
+// defaultBeefile is the Beefile written by 'bee config init'
+const defaultBeefile = `# Beefile - configuration of the bee tool.
+# Run 'bee config validate' to check it and 'bee config show' to see the effective values.
+# Every string supports ${VAR}, ${VAR:-default} and ${file:/path/to/secret} interpolation.
+
+# Version of the configuration file format, upgraded by 'bee config migrate'.
+version: 0
+
+# Run 'go install' before 'go build' in 'bee run'.
+go_install: true
+
+# Extensions of the source files watched by 'bee run'.
+watch_ext: [".go"]
+
+# Extensions of the static files which reload the browser when enable_reload is true.
+watch_ext_static: [".html", ".tpl", ".js", ".css"]
+
+# Directory structure of the application.
+dir_structure:
+  watch_all: false
+  controllers: "controllers"
+  models: "models"
+  # Other directories to watch.
+  others: []
+
+# Arguments passed to the application started by 'bee run'.
+cmd_args: []
+
+# Environment variables (KEY=value) set for the application started by 'bee run'.
+envs: []
+
+# Reload the browser when static files change.
+enable_reload: false
+
+# Show desktop notifications when the build fails.
+enable_notification: true
+
+# Default database used by 'bee migrate' and 'bee generate'.
+database:
+  driver: "mysql"
+  conn: "${DB_USER:-root}:${DB_PASSWORD:-}@tcp(${DB_HOST:-127.0.0.1}:3306)/${DB_NAME:-test}"
+  dir: "database/migrations"
+
+# Named database profiles, selected with 'bee migrate -db=<name>' or 'bee migrate -db=all'.
+databases: {}
+#  analytics:
+#    driver: "postgres"
+#    conn: "postgres://postgres:${file:/run/secrets/analytics_password}@127.0.0.1:5432/analytics?sslmode=disable"
+#    dir: "database/analytics"
+
+# Custom commands run by 'bee rs <name>'.
+scripts: {}
+#  test: "go test ./..."
+`
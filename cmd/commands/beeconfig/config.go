@@ -0,0 +1,203 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package beeconfig implements the 'bee config' command
+package beeconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/beego/bee/v2/cmd/commands"
+	"github.com/beego/bee/v2/config"
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
+)
+
+var CmdConfig = &commands.Command{
+	UsageLine: "config [Command]",
+	Short:     "Validates, shows and upgrades the bee configuration file",
+	Long: `The command 'config' manages the bee configuration of the current directory.
+
+  The configuration is merged from, in order: the user-global ~/.bee/config.yaml,
+  the project bee.json and Beefile, and the untracked Beefile.local.
+  Maps are merged key by key, lists and other values of later files replace earlier ones.
+
+  ▶ {{"To check the configuration for errors (exits with a non-zero code on errors):"|bold}}
+
+    $ bee config validate
+
+  ▶ {{"To show the effective configuration and the file each setting comes from:"|bold}}
+
+    $ bee config show [-format=yaml|json]
+
+  ▶ {{"To write a commented default Beefile:"|bold}}
+
+    $ bee config init [-force]
+
+  ▶ {{"To upgrade the configuration file to the latest version:"|bold}}
+
+    $ bee config migrate
+
+  ▶ {{"To print the JSON Schema of bee.json:"|bold}}
+
+    $ bee config schema > bee.schema.json
+
+  Reference the schema from bee.json with {{"\"$schema\": \"./bee.schema.json\""|bold}} to get validation in your editor.
+`,
+	Run: runConfig,
+}
+
+var (
+	format utils.DocValue
+	force  bool
+)
+
+func init() {
+	CmdConfig.Flag.Var(&format, "format", "Output format of 'show'. Either yaml or json.")
+	CmdConfig.Flag.BoolVar(&force, "force", false, "Overwrite the existing Beefile with 'init'.")
+	commands.AvailableCommands = append(commands.AvailableCommands, CmdConfig)
+}
+
+func runConfig(cmd *commands.Command, args []string) int {
+	if len(args) == 0 {
+		cmd.Usage()
+	}
+	cmd.Flag.Parse(args[1:])
+
+	switch args[0] {
+	case "validate":
+		return validate()
+	case "show":
+		return show()
+	case "init":
+		return initBeefile()
+	case "migrate":
+		return upgrade()
+	case "schema":
+		fmt.Fprint(os.Stdout, string(config.Schema))
+		return 0
+	default:
+		beeLogger.Log.Fatalf("Unknown config command '%s'. Run: bee help config", args[0])
+	}
+	return 0
+}
+
+// validate reports the problems of the configuration and fails on errors
+func validate() int {
+	if len(config.LoadedFiles()) == 0 {
+		beeLogger.Log.Warnf("No %s or %s found, using the default configuration", config.JSONFile, config.YAMLFile)
+	}
+	errs := 0
+	for _, p := range config.Validate() {
+		if p.Warning {
+			beeLogger.Log.Warn(p.String())
+			continue
+		}
+		errs++
+		beeLogger.Log.Error(p.String())
+	}
+	if errs > 0 {
+		beeLogger.Log.Errorf("Configuration is invalid: %d error(s)", errs)
+		return 1
+	}
+	beeLogger.Log.Success("Configuration is valid")
+	return 0
+}
+
+// show prints the effective configuration with the source of each setting
+func show() int {
+	settings := config.Settings()
+	out := os.Stdout
+	switch format {
+	case "json":
+		values := make(map[string]interface{}, len(settings))
+		srcs := make(map[string]string, len(settings))
+		for _, s := range settings {
+			values[s.Key] = s.Value
+			srcs[s.Key] = s.Source
+		}
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"files":   config.LoadedFiles(),
+			"sources": srcs,
+			"config":  values,
+		}, "", "  ")
+		if err != nil {
+			beeLogger.Log.Fatalf("Could not encode the configuration: %s", err)
+		}
+		fmt.Fprintln(out, string(data))
+	case "", "yaml":
+		fmt.Fprintln(out, "# Files, in loading order:")
+		for _, f := range config.LoadedFiles() {
+			fmt.Fprintf(out, "#   %s\n", f)
+		}
+		for _, s := range settings {
+			data, err := yaml.Marshal(map[string]interface{}{s.Key: s.Value})
+			if err != nil {
+				beeLogger.Log.Fatalf("Could not encode '%s': %s", s.Key, err)
+			}
+			fmt.Fprintf(out, "\n# from: %s\n%s", s.Source, data)
+		}
+	default:
+		beeLogger.Log.Fatalf("Unknown format '%s', expecting yaml or json", format)
+	}
+	return 0
+}
+
+// initBeefile writes a commented default Beefile into the current directory
+func initBeefile() int {
+	currpath, _ := os.Getwd()
+	path := filepath.Join(currpath, config.YAMLFile)
+	if utils.IsExist(path) && !force {
+		beeLogger.Log.Errorf("'%s' already exists", path)
+		beeLogger.Log.Hint("Use -force to overwrite it.")
+		return 1
+	}
+	if err := ioutil.WriteFile(path, []byte(defaultBeefile), 0644); err != nil {
+		beeLogger.Log.Fatalf("Could not write '%s': %s", path, err)
+	}
+	beeLogger.Log.Successf("'%s' created", path)
+	if utils.IsExist(filepath.Join(currpath, config.JSONFile)) {
+		beeLogger.Log.Warnf("%s also exists, the values in %s override it", config.JSONFile, config.YAMLFile)
+	}
+	return 0
+}
+
+// upgrade upgrades the configuration files of the current directory to the latest version
+func upgrade() int {
+	files := config.LoadedFiles()
+	if len(files) == 0 {
+		beeLogger.Log.Warnf("No %s or %s found, nothing to migrate", config.JSONFile, config.YAMLFile)
+		return 0
+	}
+	code := 0
+	for _, path := range files {
+		from, to, err := config.Upgrade(path)
+		switch {
+		case err != nil:
+			beeLogger.Log.Errorf("Could not migrate '%s': %s", path, err)
+			code = 1
+		case from == to:
+			beeLogger.Log.Infof("'%s' is already at version %d", path, to)
+		default:
+			beeLogger.Log.Successf("'%s' migrated from version %d to %d (backup: %s.bak)", path, from, to, path)
+		}
+	}
+	return code
+}
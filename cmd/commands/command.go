@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	"github.com/beego/bee/v2/logger/colors"
 	"github.com/beego/bee/v2/utils"
 )
@@ -106,3 +107,9 @@ func (c *Command) Options() map[string]string {
 	})
 	return options
 }
+
+// AddDryRunFlag registers the -dry-run flag shared by the commands generating code.
+// 指定 -dry-run 时所有文件都写入内存，命令结束后打印将要新建的文件和已存在文件的 diff，磁盘不会被修改
+func (c *Command) AddDryRunFlag() {
+	c.Flag.BoolVar(&generated.DryRun, "dry-run", false, "Show the files which would be created and the diff of the existing ones, without writing anything.")
+}
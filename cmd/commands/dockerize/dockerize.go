@@ -15,6 +15,7 @@
 package dockerize
 
 import (
+	"bytes"
 	"flag"
 	"os"
 	"path"
@@ -24,6 +25,7 @@ import (
 
 	"github.com/beego/bee/v2/cmd/commands"
 	"github.com/beego/bee/v2/cmd/commands/version"
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	beeLogger "github.com/beego/bee/v2/logger"
 	"github.com/beego/bee/v2/utils"
 )
@@ -92,6 +94,7 @@ func init() {
 	fs.StringVar(&baseImage, "baseimage", "golang:1.20.2", "Set the base image of the Docker container.")
 	fs.StringVar(&expose, "expose", "8080", "Port(s) to expose for the Docker container.")
 	CmdDockerize.Flag = *fs
+	CmdDockerize.AddDryRunFlag()
 	commands.AvailableCommands = append(commands.AvailableCommands, CmdDockerize)
 }
 
@@ -144,13 +147,11 @@ func dockerizeApp(cmd *commands.Command, args []string) int {
 func generateDockerfile(df Dockerfile) {
 	t := template.Must(template.New("dockerBuildTemplate").Parse(dockerBuildTemplate)).Funcs(utils.BeeFuncMap())
 
-	f, err := os.Create("Dockerfile")
-	if err != nil {
+	var buf bytes.Buffer
+	t.Execute(&buf, df)
+	if err := generated.WriteFile("Dockerfile", buf.Bytes()); err != nil {
 		beeLogger.Log.Fatalf("Error writing Dockerfile: %v", err.Error())
 	}
-	defer utils.CloseFile(f)
-
-	t.Execute(f, df)
 
 	beeLogger.Log.Success("Dockerfile generated.")
 }
@@ -158,13 +159,11 @@ func generateDockerfile(df Dockerfile) {
 func generatecomposefile(df Composefile) {
 	t := template.Must(template.New("composeBuildTemplate").Parse(composeBuildTemplate)).Funcs(utils.BeeFuncMap())
 
-	f, err := os.Create("docker-compose.yaml")
-	if err != nil {
+	var buf bytes.Buffer
+	t.Execute(&buf, df)
+	if err := generated.WriteFile("docker-compose.yaml", buf.Bytes()); err != nil {
 		beeLogger.Log.Fatalf("Error writing docker-compose.yaml: %v", err.Error())
 	}
-	defer utils.CloseFile(f)
-
-	t.Execute(f, df)
 
 	beeLogger.Log.Success("docker-compose.yaml generated.")
 }
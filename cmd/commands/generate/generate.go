@@ -14,8 +14,11 @@
 package generate
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/beego/bee/v2/cmd/commands"
 	"github.com/beego/bee/v2/cmd/commands/version"
@@ -32,6 +35,7 @@ var CmdGenerate = &commands.Command{
 	Long: `▶ {{"To scaffold out your entire application:"|bold}}
 
      $ bee generate scaffold [scaffoldname] [-fields="title:string,body:text"] [-driver=mysql] [-conn="root:@tcp(127.0.0.1:3306)/test"]
+     $ bee generate scaffold [scaffoldname] [-fields="title:string,body:text"] -yes [-skip=views,migrate]
 
   ▶ {{"To generate a Model based on fields:"|bold}}
 
@@ -43,7 +47,9 @@ var CmdGenerate = &commands.Command{
 
   ▶ {{"To generate a CRUD view:"|bold}}
 
-     $ bee generate view [viewpath]
+     $ bee generate view [viewpath] [-fields="name:type"]
+
+     The fields are read from the model file when -fields is not set.
 
   ▶ {{"To generate a migration file for making database schema updates:"|bold}}
 
@@ -51,19 +57,44 @@ var CmdGenerate = &commands.Command{
 
   ▶ {{"To generate swagger doc file:"|bold}}
 
-     $ bee generate docs
+     $ bee generate docs [-spec=swagger2|openapi3]
+
+  ▶ {{"To validate the annotations of the controllers:"|bold}}
+
+     $ bee generate docs -check
+
+  ▶ {{"To compare the generated docs with a previous version:"|bold}}
+
+     $ bee generate docs -diff=swagger/swagger.json
+
+  ▶ {{"To generate a typed API client:"|bold}}
+
+     $ bee generate client [-lang=go|ts] [-output=client]
 
     ▶ {{"To generate swagger doc file:"|bold}}
 
      $ bee generate routers [-ctrlDir=/path/to/controller/directory] [-routersFile=/path/to/routers/file.go] [-routersPkg=myPackage]
 
-  ▶ {{"To generate a test case:"|bold}}
+  ▶ {{"To generate the tests of a controller and of a model:"|bold}}
+
+     $ bee generate test [name]
+
+  ▶ {{"To export the templates of the generators into .bee/templates, to customize them:"|bold}}
 
-     $ bee generate test [routerfile]
+     $ bee generate templates export [-force]
+     $ bee generate templates list
 
   ▶ {{"To generate appcode based on an existing database:"|bold}}
 
-     $ bee generate appcode [-tables=""] [-driver=mysql] [-conn="root:@tcp(127.0.0.1:3306)/test"] [-level=3]
+     $ bee generate appcode [-tables=""] [-driver=mysql] [-conn="root:@tcp(127.0.0.1:3306)/test"] [-level=3] [-arch=flat|layered] [-schema=public]
+
+  ▶ {{"To generate the protobuf messages and the gRPC services of an existing database, served next to the HTTP server:"|bold}}
+
+     $ bee generate grpc [-tables=""] [-driver=mysql] [-conn="root:@tcp(127.0.0.1:3306)/test"] [-schema=public]
+
+  ▶ {{"To show the files a generator would write, and the diff of the existing ones, without writing them:"|bold}}
+
+     $ bee generate scaffold post -fields="title:string" -yes -dry-run
 `,
 	PreRun: func(cmd *commands.Command, args []string) { version.ShowShortVersionBanner() },
 	Run:    GenerateCode,
@@ -73,9 +104,19 @@ func init() {
 	CmdGenerate.Flag.Var(&generate.Tables, "tables", "List of table names separated by a comma.")
 	CmdGenerate.Flag.Var(&generate.SQLDriver, "driver", "Database SQLDriver. Either mysql, postgres or sqlite.")
 	CmdGenerate.Flag.Var(&generate.SQLConn, "conn", "Connection string used by the SQLDriver to connect to a database instance.")
+	CmdGenerate.Flag.Var(&generate.Schema, "schema", "PostgreSQL schema of the tables. Default is all the schemas, or the appcode.schema of bee.json.")
 	CmdGenerate.Flag.Var(&generate.Level, "level", "Either 1, 2 or 3. i.e. 1=models; 2=models and controllers; 3=models, controllers and routers.")
-	CmdGenerate.Flag.Var(&generate.Fields, "fields", "List of table Fields.")
+	CmdGenerate.Flag.Var(&generate.Arch, "arch", "Architecture of the appcode. Either flat (default) or layered, i.e. models with repositories and services.")
+	CmdGenerate.Flag.Var(&generate.Fields, "fields", "List of table Fields, e.g. title:string(64):unique,body:text?,author:ref(user).")
 	CmdGenerate.Flag.Var(&generate.DDL, "ddl", "Generate DDL Migration")
+	CmdGenerate.Flag.Var(&generate.DocsSpec, "spec", "Specification of the generated docs. Either swagger2 (default) or openapi3.")
+	CmdGenerate.Flag.Var(&generate.ClientLang, "lang", "Language of the generated client. Either go (default) or ts.")
+	CmdGenerate.Flag.Var(&generate.ClientOutput, "output", "Output directory of the generated client. Default is client.")
+	CmdGenerate.Flag.Var(&generate.DocsDiff, "diff", "Previous swagger or openapi JSON document to compare the generated docs with.")
+	CmdGenerate.Flag.BoolVar(&generate.DocsCheck, "check", false, "Validate the controller annotations without generating the docs.")
+	CmdGenerate.Flag.BoolVar(&generate.ScaffoldYes, "yes", false, "Run all the steps of the scaffold without asking.")
+	CmdGenerate.Flag.Var(&generate.ScaffoldSkip, "skip", "Steps of the scaffold not to run, separated by a comma: model, controller, views, migration, migrate, routes.")
+	CmdGenerate.Flag.BoolVar(&generate.TemplatesForce, "force", false, "Overwrite the templates already exported.")
 
 	// bee generate routers
 	CmdGenerate.Flag.Var(&generate.ControllerDirectory, "ctrlDir",
@@ -85,6 +126,7 @@ func init() {
 	CmdGenerate.Flag.Var(&generate.RouterPkg, "routersPkg",
 		`router's package. Default is routers, it means that "package routers" in the generated file`)
 
+	CmdGenerate.AddDryRunFlag()
 	commands.AvailableCommands = append(commands.AvailableCommands, CmdGenerate)
 }
 
@@ -99,19 +141,27 @@ func GenerateCode(cmd *commands.Command, args []string) int {
 	case "scaffold":
 		scaffold(cmd, args, currpath)
 	case "docs":
-		swaggergen.GenerateDocs(currpath)
+		docs(cmd, args, currpath)
+	case "client":
+		client(cmd, args, currpath)
 	case "appcode":
 		appCode(cmd, args, currpath)
+	case "grpc":
+		grpcCode(cmd, args, currpath)
 	case "migration":
 		migration(cmd, args, currpath)
 	case "controller":
-		controller(args, currpath)
+		controller(cmd, args, currpath)
 	case "model":
 		model(cmd, args, currpath)
 	case "view":
-		view(args, currpath)
+		view(cmd, args, currpath)
 	case "routers":
 		genRouters(cmd, args)
+	case "test":
+		test(cmd, args, currpath)
+	case "templates":
+		templates(cmd, args, currpath)
 	default:
 		beeLogger.Log.Fatal("Command is missing")
 	}
@@ -129,6 +179,74 @@ func genRouters(cmd *commands.Command, args []string) {
 	generate.GenRouters()
 }
 
+func templates(cmd *commands.Command, args []string, currpath string) {
+	if len(args) < 2 {
+		beeLogger.Log.Fatal("Wrong number of arguments. Run: bee help generate")
+	}
+	if err := cmd.Flag.Parse(args[2:]); err != nil {
+		beeLogger.Log.Fatalf("Error while parsing flags: %v", err.Error())
+	}
+	switch args[1] {
+	case "export":
+		if err := generate.ExportTemplates(currpath, generate.TemplatesForce); err != nil {
+			beeLogger.Log.Fatalf("Could not export the templates: %s", err)
+		}
+	case "list":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, t := range generate.Templates {
+			fmt.Fprintf(w, "%s\t%s\n", filepath.Join(generate.TemplatesDir, filepath.FromSlash(t.Name)), t.Description)
+		}
+		w.Flush()
+	default:
+		beeLogger.Log.Fatalf("Unknown templates command '%s'. Must be either export or list", args[1])
+	}
+}
+
+func docs(cmd *commands.Command, args []string, currpath string) {
+	if err := cmd.Flag.Parse(args[1:]); err != nil {
+		beeLogger.Log.Fatalf("Error while parsing flags: %v", err.Error())
+	}
+	if generate.DocsCheck {
+		problems := swaggergen.CheckDocs(currpath)
+		for _, p := range problems {
+			beeLogger.Log.Error(p)
+		}
+		if len(problems) > 0 {
+			beeLogger.Log.Fatalf("Found %d problem(s) in the annotations", len(problems))
+		}
+		beeLogger.Log.Success("No problem found in the annotations")
+		os.Exit(0)
+	}
+	if generate.DocsDiff != "" {
+		changes, err := swaggergen.DiffDocs(currpath, generate.DocsSpec.String(), generate.DocsDiff.String())
+		if err != nil {
+			beeLogger.Log.Fatalf("Could not compare the docs with '%s': %s", generate.DocsDiff, err)
+		}
+		breaking := 0
+		for _, c := range changes {
+			if c.Breaking {
+				breaking++
+				beeLogger.Log.Errorf("breaking: %s", c)
+			} else {
+				beeLogger.Log.Infof("non-breaking: %s", c)
+			}
+		}
+		if breaking > 0 {
+			beeLogger.Log.Fatalf("Found %d breaking change(s) and %d non-breaking change(s)", breaking, len(changes)-breaking)
+		}
+		beeLogger.Log.Infof("No breaking change, %d non-breaking change(s)", len(changes))
+		return
+	}
+	swaggergen.GenerateDocs(currpath, generate.DocsSpec.String())
+}
+
+func client(cmd *commands.Command, args []string, currpath string) {
+	if err := cmd.Flag.Parse(args[1:]); err != nil {
+		beeLogger.Log.Fatalf("Error while parsing flags: %v", err.Error())
+	}
+	swaggergen.GenerateClient(currpath, generate.ClientLang.String(), generate.ClientOutput.String())
+}
+
 func scaffold(cmd *commands.Command, args []string, currpath string) {
 	if len(args) < 2 {
 		beeLogger.Log.Fatal("Wrong number of arguments. Run: bee help generate")
@@ -136,12 +254,14 @@ func scaffold(cmd *commands.Command, args []string, currpath string) {
 
 	cmd.Flag.Parse(args[2:])
 	if generate.SQLDriver == "" {
+		resolved("database.driver")
 		generate.SQLDriver = utils.DocValue(config.Conf.Database.Driver)
 		if generate.SQLDriver == "" {
 			generate.SQLDriver = "mysql"
 		}
 	}
 	if generate.SQLConn == "" {
+		resolved("database.conn")
 		generate.SQLConn = utils.DocValue(config.Conf.Database.Conn)
 		if generate.SQLConn == "" {
 			generate.SQLConn = "root:@tcp(127.0.0.1:3306)/test"
@@ -151,19 +271,86 @@ func scaffold(cmd *commands.Command, args []string, currpath string) {
 		beeLogger.Log.Hint("Fields option should not be empty, i.e. -Fields=\"title:string,body:text\"")
 		beeLogger.Log.Fatal("Wrong number of arguments. Run: bee help generate")
 	}
+	resolved("scaffold")
+	opts := generate.ScaffoldOptions{
+		Yes:         generate.ScaffoldYes || config.Conf.Scaffold.Yes,
+		Skip:        map[string]bool{},
+		RoutersFile: config.Conf.Scaffold.RoutersFile,
+		Namespace:   config.Conf.Scaffold.Namespace,
+	}
+	skip := config.Conf.Scaffold.Skip
+	if generate.ScaffoldSkip != "" {
+		skip = strings.Split(generate.ScaffoldSkip.String(), ",")
+	}
+	for _, step := range skip {
+		step = strings.TrimSpace(step)
+		if !isScaffoldStep(step) {
+			beeLogger.Log.Fatalf("Unknown scaffold step '%s'. Must be one of %s", step, strings.Join(generate.ScaffoldSteps, ", "))
+		}
+		opts.Skip[step] = true
+	}
 	sname := args[1]
-	generate.GenerateScaffold(sname, generate.Fields.String(), currpath, generate.SQLDriver.String(), generate.SQLConn.String())
+	generate.GenerateScaffold(sname, generate.Fields.String(), currpath, generate.SQLDriver.String(), generate.SQLConn.String(), opts)
+}
+
+func isScaffoldStep(step string) bool {
+	for _, s := range generate.ScaffoldSteps {
+		if s == step {
+			return true
+		}
+	}
+	return false
 }
 
 func appCode(cmd *commands.Command, args []string, currpath string) {
 	cmd.Flag.Parse(args[1:])
+	databaseDefaults()
+	if generate.Level == "" {
+		generate.Level = "3"
+	}
+	config.MaskConnPassword(generate.SQLConn.String())
+	beeLogger.Log.Infof("Using '%s' as 'SQLDriver'", generate.SQLDriver)
+	beeLogger.Log.Infof("Using '%s' as 'SQLConn'", generate.SQLConn)
+	beeLogger.Log.Infof("Using '%s' as 'Tables'", generate.Tables)
+	beeLogger.Log.Infof("Using '%s' as 'Level'", generate.Level)
+	if generate.Arch == "" {
+		generate.Arch = generate.ArchFlat
+	}
+	beeLogger.Log.Infof("Using '%s' as 'Arch'", generate.Arch)
+	opts := appcodeOptionsFromFlags()
+	generate.GenerateAppcode(generate.SQLDriver.String(), generate.SQLConn.String(), generate.Level.String(), generate.Arch.String(), generate.Tables.String(), currpath, opts)
+}
+
+func grpcCode(cmd *commands.Command, args []string, currpath string) {
+	cmd.Flag.Parse(args[1:])
+	databaseDefaults()
+	config.MaskConnPassword(generate.SQLConn.String())
+	beeLogger.Log.Infof("Using '%s' as 'SQLDriver'", generate.SQLDriver)
+	beeLogger.Log.Infof("Using '%s' as 'SQLConn'", generate.SQLConn)
+	beeLogger.Log.Infof("Using '%s' as 'Tables'", generate.Tables)
+	opts := appcodeOptionsFromFlags()
+	generate.GenerateGrpc(generate.SQLDriver.String(), generate.SQLConn.String(), generate.Tables.String(), currpath, opts)
+}
+
+// resolved exits when the ${...} references of the configuration field could not be expanded
+func resolved(field string) {
+	if err := config.Resolved(field); err != nil {
+		beeLogger.Log.Fatalf("Invalid configuration: %s", err)
+	}
+}
+
+// databaseDefaults sets the driver and the connection of the database read by appcode
+// and grpc, from bee.json or the defaults of the driver, unless they are set by the options
+func databaseDefaults() {
 	if generate.SQLDriver == "" {
+		resolved("database.driver")
 		generate.SQLDriver = utils.DocValue(config.Conf.Database.Driver)
 		if generate.SQLDriver == "" {
 			generate.SQLDriver = "mysql"
 		}
 	}
 	if generate.SQLConn == "" {
+		resolved("database.conn")
 		generate.SQLConn = utils.DocValue(config.Conf.Database.Conn)
 		if generate.SQLConn == "" {
 			if generate.SQLDriver == "mysql" {
@@ -173,14 +360,16 @@ func appCode(cmd *commands.Command, args []string, currpath string) {
 			}
 		}
 	}
-	if generate.Level == "" {
-		generate.Level = "3"
+}
+
+// appcodeOptionsFromFlags returns the options of appcode and grpc: the naming rules
+// of bee.json, and the schema of the -schema option or of bee.json
+func appcodeOptionsFromFlags() generate.AppcodeOptions {
+	opts := generate.AppcodeOptionsFromConfig(generate.Schema.String())
+	if opts.Schema != "" {
+		beeLogger.Log.Infof("Using '%s' as 'Schema'", opts.Schema)
 	}
-	beeLogger.Log.Infof("Using '%s' as 'SQLDriver'", generate.SQLDriver)
-	beeLogger.Log.Infof("Using '%s' as 'SQLConn'", generate.SQLConn)
-	beeLogger.Log.Infof("Using '%s' as 'Tables'", generate.Tables)
-	beeLogger.Log.Infof("Using '%s' as 'Level'", generate.Level)
-	generate.GenerateAppcode(generate.SQLDriver.String(), generate.SQLConn.String(), generate.Level.String(), generate.Tables.String(), currpath)
+	return opts
 }
 
 func migration(cmd *commands.Command, args []string, currpath string) {
@@ -202,13 +391,20 @@ func migration(cmd *commands.Command, args []string, currpath string) {
 	generate.GenerateMigration(mname, upsql, downsql, currpath)
 }
 
-func controller(args []string, currpath string) {
-	if len(args) == 2 {
-		cname := args[1]
-		generate.GenerateController(cname, currpath)
-	} else {
+func test(cmd *commands.Command, args []string, currpath string) {
+	if len(args) < 2 {
+		beeLogger.Log.Fatal("Wrong number of arguments. Run: bee help generate")
+	}
+	cmd.Flag.Parse(args[2:])
+	generate.GenerateTest(args[1], currpath)
+}
+
+func controller(cmd *commands.Command, args []string, currpath string) {
+	if len(args) < 2 {
 		beeLogger.Log.Fatal("Wrong number of arguments. Run: bee help generate")
 	}
+	cmd.Flag.Parse(args[2:])
+	generate.GenerateController(args[1], currpath)
 }
 
 func model(cmd *commands.Command, args []string, currpath string) {
@@ -224,11 +420,10 @@ func model(cmd *commands.Command, args []string, currpath string) {
 	generate.GenerateModel(sname, generate.Fields.String(), currpath)
 }
 
-func view(args []string, currpath string) {
-	if len(args) == 2 {
-		cname := args[1]
-		generate.GenerateView(cname, currpath)
-	} else {
+func view(cmd *commands.Command, args []string, currpath string) {
+	if len(args) < 2 {
 		beeLogger.Log.Fatal("Wrong number of arguments. Run: bee help generate")
 	}
+	cmd.Flag.Parse(args[2:])
+	generate.GenerateView(args[1], generate.Fields.String(), currpath)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/beego/bee/v2/cmd/commands/api"
 	"github.com/beego/bee/v2/cmd/commands/version"
 	"github.com/beego/bee/v2/generate"
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	beeLogger "github.com/beego/bee/v2/logger"
 	"github.com/beego/bee/v2/utils"
 )
@@ -62,6 +63,7 @@ func init() {
 	CmdHproseapp.Flag.Var(&generate.SQLConn, "conn", "Connection string used by the driver to connect to a database instance.")
 	CmdHproseapp.Flag.Var(&gopath, "gopath", "Support go path,default false")
 	CmdHproseapp.Flag.Var(&beegoVersion, "beego", "set beego version,only take effect by go mod")
+	CmdHproseapp.AddDryRunFlag()
 	commands.AvailableCommands = append(commands.AvailableCommands, CmdHproseapp)
 }
 
@@ -110,13 +112,13 @@ func createhprose(cmd *commands.Command, args []string) int {
 	}
 	beeLogger.Log.Info("Creating Hprose application...")
 
-	os.MkdirAll(apppath, 0755)
+	generated.MkdirAll(apppath)
 	if gopath != `true` { //generate first for calc model name
 		fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(apppath, "go.mod"), "\x1b[0m")
 		utils.WriteToFile(path.Join(apppath, "go.mod"), fmt.Sprintf(goMod, packpath, utils.GetGoVersionSkipMinor(), beegoVersion.String()))
 	}
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", apppath, "\x1b[0m")
-	os.Mkdir(path.Join(apppath, "conf"), 0755)
+	generated.MkdirAll(path.Join(apppath, "conf"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(apppath, "conf"), "\x1b[0m")
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(apppath, "conf", "app.conf"), "\x1b[0m")
 	utils.WriteToFile(path.Join(apppath, "conf", "app.conf"),
@@ -146,7 +148,7 @@ func createhprose(cmd *commands.Command, args []string) int {
 			),
 		)
 	} else {
-		os.Mkdir(path.Join(apppath, "models"), 0755)
+		generated.MkdirAll(path.Join(apppath, "models"))
 		fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(apppath, "models"), "\x1b[0m")
 
 		fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(apppath, "models", "object.go"), "\x1b[0m")
@@ -15,9 +15,12 @@ package migrate
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -56,6 +59,14 @@ var CmdMigrate = &commands.Command{
   ▶ {{"To update your schema:"|bold}}
 
     $ bee migrate refresh [-driver=mysql] [-conn="root:@tcp(127.0.0.1:3306)/test"] [-dir="path/to/migration"]
+
+  ▶ {{"To run the migrations of a database profile declared under 'databases' in bee.json or Beefile:"|bold}}
+
+    $ bee migrate [Command] -db=analytics
+
+  ▶ {{"To run the migrations of every database profile in sequence:"|bold}}
+
+    $ bee migrate [Command] -db=all
 `,
 	PreRun: func(cmd *commands.Command, args []string) { version.ShowShortVersionBanner() },
 	Run:    RunMigration,
@@ -64,11 +75,35 @@ var CmdMigrate = &commands.Command{
 var mDriver utils.DocValue
 var mConn utils.DocValue
 var mDir utils.DocValue
+var mDB utils.DocValue
+
+// errNothingToRollback is returned when a rollback is requested but no migration has been applied
+var errNothingToRollback = errors.New("there is nothing to rollback")
+
+// allProfiles is the -db value which selects every configured database profile
+const allProfiles = "all"
+
+// migrationGoals maps the migrate sub-commands to the task run by the migration binary
+var migrationGoals = map[string]string{
+	"":         "upgrade",
+	"rollback": "rollback",
+	"reset":    "reset",
+	"refresh":  "refresh",
+}
+
+// migrationTarget is a database that migrations are run against
+type migrationTarget struct {
+	Profile string
+	Driver  string
+	Conn    string
+	Dir     string
+}
 
 func init() {
 	CmdMigrate.Flag.Var(&mDriver, "driver", "Database driver. Either mysql, postgres or sqlite.")
 	CmdMigrate.Flag.Var(&mConn, "conn", "Connection string used by the driver to connect to a database instance.")
 	CmdMigrate.Flag.Var(&mDir, "dir", "The directory where the migration files are stored")
+	CmdMigrate.Flag.Var(&mDB, "db", "Name of the database profile to use, or 'all' to migrate every profile in sequence.")
 	commands.AvailableCommands = append(commands.AvailableCommands, CmdMigrate)
 }
 
@@ -80,71 +115,153 @@ func RunMigration(cmd *commands.Command, args []string) int {
 
 	// Getting command line arguments
 	// 如果命令行参数 args 非空，则调用 cmd.Flag.Parse(args[1:]) 来解析后续参数
+	mcmd := ""
 	if len(args) != 0 {
+		mcmd = args[0]
 		cmd.Flag.Parse(args[1:])
 	}
-	// 如果没有指定数据库驱动 (mDriver)，则从配置文件中获取或者默认为 mysql
-	if mDriver == "" {
-		mDriver = utils.DocValue(config.Conf.Database.Driver)
-		if mDriver == "" {
-			mDriver = "mysql"
-		}
+	goal, ok := migrationGoals[mcmd]
+	if !ok {
+		beeLogger.Log.Fatal("Command is missing")
 	}
-	// 如果没有指定数据库连接字符串 (mConn)，则从配置文件中获取或者默认设置为 root:@tcp(127.0.0.1:3306)/test
-	if mConn == "" {
-		mConn = utils.DocValue(config.Conf.Database.Conn)
-		if mConn == "" {
-			mConn = "root:@tcp(127.0.0.1:3306)/test"
+
+	if mDB == allProfiles {
+		if mDriver != "" || mConn != "" || mDir != "" {
+			beeLogger.Log.Warn("The -driver, -conn and -dir options are ignored when -db=all")
 		}
+		return migrateAll(goal, currpath)
 	}
-	// 如果没有指定迁移文件存放目录 (mDir)，则默认使用 database/migrations
-	if mDir == "" {
-		mDir = utils.DocValue(config.Conf.Database.Dir)
-		if mDir == "" {
-			mDir = utils.DocValue(path.Join(currpath, "database", "migrations"))
-		}
+
+	target, err := newMigrationTarget(mDB.String(), currpath)
+	if err != nil {
+		beeLogger.Log.Fatalf("%s", err)
+	}
+	// 命令行参数优先于配置文件中的设置
+	if mDriver != "" {
+		target.Driver = mDriver.String()
+	}
+	if mConn != "" {
+		target.Conn = mConn.String()
+		config.MaskConnPassword(target.Conn)
+	}
+	if mDir != "" {
+		target.Dir = absMigrationDir(currpath, mDir.String())
 	}
 
-	beeLogger.Log.Infof("Using '%s' as 'driver'", mDriver)
+	if mDB != "" {
+		beeLogger.Log.Infof("Using '%s' as 'db'", target.Profile)
+	}
+	beeLogger.Log.Infof("Using '%s' as 'driver'", target.Driver)
 	//Log sensitive connection information only when DEBUG is set to true.
-	beeLogger.Log.Debugf("Conn: %s", utils.FILE(), utils.LINE(), mConn)
-	beeLogger.Log.Infof("Using '%s' as 'dir'", mDir)
-	driverStr, connStr, dirStr := string(mDriver), string(mConn), string(mDir)
+	beeLogger.Log.Debugf("Conn: %s", utils.FILE(), utils.LINE(), target.Conn)
+	beeLogger.Log.Infof("Using '%s' as 'dir'", target.Dir)
 
-	dirRune := []rune(dirStr)
+	logMigrationGoal(goal)
+	if err := migrate(goal, currpath, target.Driver, target.Conn, target.Dir); err != nil {
+		beeLogger.Log.Fatalf("%s", err)
+	}
+	beeLogger.Log.Success("Migration successful!")
+	return 0
+}
 
-	//  如果迁移目录不是绝对路径，则将其与当前工作目录结合，生成完整路径
-	if dirRune[0] != '/' && dirRune[1] != ':' {
-		dirStr = path.Join(currpath, dirStr)
+// migrateAll runs the migration goal against every configured database profile
+// and prints an aggregate report. It returns a non-zero exit code if any of them failed.
+// 依次对所有数据库 profile 执行迁移，单个 profile 失败不会中断后续 profile 的迁移
+func migrateAll(goal, currpath string) int {
+	names := config.DatabaseProfileNames()
+	failed := map[string]error{}
+	skipped := map[string]bool{}
+	for _, name := range names {
+		beeLogger.Log.Infof("Migrating database profile '%s'", name)
+		target, err := newMigrationTarget(name, currpath)
+		if err == nil {
+			beeLogger.Log.Infof("Using '%s' as 'driver'", target.Driver)
+			beeLogger.Log.Debugf("Conn: %s", utils.FILE(), utils.LINE(), target.Conn)
+			beeLogger.Log.Infof("Using '%s' as 'dir'", target.Dir)
+			logMigrationGoal(goal)
+			err = migrate(goal, currpath, target.Driver, target.Conn, target.Dir)
+		}
+		if err == errNothingToRollback {
+			beeLogger.Log.Warnf("Database profile '%s' has nothing to rollback", name)
+			skipped[name] = true
+		} else if err != nil {
+			beeLogger.Log.Errorf("Database profile '%s' failed: %s", name, err)
+			failed[name] = err
+		}
 	}
 
-	if len(args) == 0 {
-		// run all outstanding migrations
-		beeLogger.Log.Info("Running all outstanding migrations")
-		MigrateUpdate(currpath, driverStr, connStr, dirStr)
-	} else {
-		mcmd := args[0]
-		switch mcmd {
-		case "rollback":
-			beeLogger.Log.Info("Rolling back the last migration operation")
-			MigrateRollback(currpath, driverStr, connStr, dirStr)
-		case "reset":
-			beeLogger.Log.Info("Reseting all migrations")
-			MigrateReset(currpath, driverStr, connStr, dirStr)
-		case "refresh":
-			beeLogger.Log.Info("Refreshing all migrations")
-			MigrateRefresh(currpath, driverStr, connStr, dirStr)
-		default:
-			beeLogger.Log.Fatal("Command is missing")
+	// Aggregate report
+	beeLogger.Log.Infof("Migration report (%s):", goal)
+	for _, name := range names {
+		if err, ok := failed[name]; ok {
+			beeLogger.Log.Errorf("  %-20s FAILED  %s", name, err)
+		} else if skipped[name] {
+			beeLogger.Log.Warnf("  %-20s SKIPPED %s", name, errNothingToRollback)
+		} else {
+			beeLogger.Log.Successf("  %-20s OK", name)
 		}
 	}
-	beeLogger.Log.Success("Migration successful!")
+	if len(failed) > 0 {
+		beeLogger.Log.Errorf("%d of %d database profiles failed to migrate", len(failed), len(names))
+		return 2
+	}
+	beeLogger.Log.Successf("All %d database profiles migrated successfully!", len(names))
 	return 0
 }
 
+// newMigrationTarget builds the migration target of the named database profile,
+// falling back to the default driver, connection string and directory.
+func newMigrationTarget(profile, currpath string) (migrationTarget, error) {
+	db, err := config.DatabaseProfile(profile)
+	if err != nil {
+		return migrationTarget{}, err
+	}
+	if profile == "" {
+		profile = config.DefaultDatabaseProfile
+	}
+	target := migrationTarget{Profile: profile, Driver: db.Driver, Conn: db.Conn, Dir: db.Dir}
+	// 如果没有指定数据库驱动，则默认为 mysql
+	if target.Driver == "" {
+		target.Driver = "mysql"
+	}
+	// 如果没有指定数据库连接字符串，则默认设置为 root:@tcp(127.0.0.1:3306)/test
+	if target.Conn == "" {
+		target.Conn = "root:@tcp(127.0.0.1:3306)/test"
+	}
+	// 如果没有指定迁移文件存放目录，则默认使用 database/migrations
+	if target.Dir == "" {
+		target.Dir = path.Join(currpath, "database", "migrations")
+	}
+	target.Dir = absMigrationDir(currpath, target.Dir)
+	return target, nil
+}
+
+// absMigrationDir joins a relative migration directory with the current path
+// 如果迁移目录不是绝对路径，则将其与当前工作目录结合，生成完整路径
+func absMigrationDir(currpath, dir string) string {
+	dirRune := []rune(dir)
+	if len(dirRune) > 1 && (dirRune[0] == '/' || dirRune[1] == ':') {
+		return dir
+	}
+	return path.Join(currpath, dir)
+}
+
+func logMigrationGoal(goal string) {
+	switch goal {
+	case "upgrade":
+		beeLogger.Log.Info("Running all outstanding migrations")
+	case "rollback":
+		beeLogger.Log.Info("Rolling back the last migration operation")
+	case "reset":
+		beeLogger.Log.Info("Reseting all migrations")
+	case "refresh":
+		beeLogger.Log.Info("Refreshing all migrations")
+	}
+}
+
 // migrate generates source code, build it, and invoke the binary who does the actual migration
 // 处理迁移流程，通过生成源文件、编译二进制文件并执行迁移
-func migrate(goal, currpath, driver, connStr, dir string) {
+func migrate(goal, currpath, driver, connStr, dir string) error {
 	// 如果传入的迁移目录 (dir) 为空，则默认将迁移目录设置为 database/migrations，并与当前工作目录 (currpath) 合并成完整路径
 	if dir == "" {
 		dir = path.Join(currpath, "database", "migrations")
@@ -158,35 +275,52 @@ func migrate(goal, currpath, driver, connStr, dir string) {
 	source := binary + ".go"
 
 	// Connect to database
-	// 使用传入的数据库驱动 (driver) 和连接字符串 (connStr) 打开数据库连接。如果连接失败，记录日志并终止程序
+	// 使用传入的数据库驱动 (driver) 和连接字符串 (connStr) 打开数据库连接
 	db, err := sql.Open(driver, connStr)
 	if err != nil {
-		beeLogger.Log.Fatalf("Could not connect to database using '%s': %s", connStr, err)
+		return fmt.Errorf("could not connect to database using '%s': %s", driver, err)
 	}
 	defer db.Close()
 
 	// 检查迁移表的存在性和结构： 调用 checkForSchemaUpdateTable 函数检查数据库中是否存在用于管理迁移的表（如 migrations）。如果没有该表，则会创建一个
-	checkForSchemaUpdateTable(db, driver)
+	if err := checkForSchemaUpdateTable(db, driver); err != nil {
+		return err
+	}
 	// 获取最新的迁移信息： 调用 getLatestMigration 函数获取数据库中最新的迁移记录，返回迁移文件名和时间戳。这些信息会在生成迁移源文件时使用
-	latestName, latestTime := getLatestMigration(db, goal)
+	latestName, latestTime, err := getLatestMigration(db, goal)
+	if err != nil {
+		return err
+	}
+	// 删除临时文件： 迁移操作完成后（无论成功与否），删除临时生成的源代码文件和二进制文件。
+	// 源文件一经创建即注册删除，写入失败时也不会遗留
+	if err := checkDir(dir); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, source), os.O_CREATE|os.O_EXCL|os.O_RDWR, 0666)
+	if err != nil {
+		return fmt.Errorf("could not create file: %s", err)
+	}
+	defer removeTempFile(dir, source)
+	defer removeTempFile(dir, binary)
 	// 生成迁移源文件： 根据获取到的迁移信息，调用 writeMigrationSourceFile 函数生成用于执行迁移操作的 Go 源代码文件
-	writeMigrationSourceFile(dir, source, driver, connStr, latestTime, latestName, goal)
+	if err := writeMigrationSourceFile(f, driver, connStr, latestTime, latestName, goal); err != nil {
+		return err
+	}
 	// 编译迁移二进制文件： 调用 buildMigrationBinary 函数，通过 go build 编译生成一个二进制文件，用于执行迁移操作
-	buildMigrationBinary(dir, binary)
+	if err := buildMigrationBinary(dir, binary); err != nil {
+		return err
+	}
 	// 执行迁移二进制文件： 调用 runMigrationBinary 函数运行刚刚编译好的二进制文件，执行迁移操作（如升级、回滚等）
-	runMigrationBinary(dir, binary)
-	// 删除临时文件： 迁移操作完成后，删除临时生成的源代码文件和二进制文件
-	removeTempFile(dir, source)
-	removeTempFile(dir, binary)
+	return runMigrationBinary(dir, binary)
 }
 
 // checkForSchemaUpdateTable checks the existence of migrations table.
 // It checks for the proper table structures and creates the table using MYSQL_MIGRATION_DDL if it does not exist.
 // 确保迁移表存在并且是最新的
-func checkForSchemaUpdateTable(db *sql.DB, driver string) {
+func checkForSchemaUpdateTable(db *sql.DB, driver string) error {
 	showTableSQL := showMigrationsTableSQL(driver)
 	if rows, err := db.Query(showTableSQL); err != nil {
-		beeLogger.Log.Fatalf("Could not show migrations table: %s", err)
+		return fmt.Errorf("could not show migrations table: %s", err)
 	} else if !rows.Next() {
 		// No migrations table, create new ones
 		createTableSQL := createMigrationsTableSQL(driver)
@@ -194,40 +328,41 @@ func checkForSchemaUpdateTable(db *sql.DB, driver string) {
 		beeLogger.Log.Infof("Creating 'migrations' table...")
 
 		if _, err := db.Query(createTableSQL); err != nil {
-			beeLogger.Log.Fatalf("Could not create migrations table: %s", err)
+			return fmt.Errorf("could not create migrations table: %s", err)
 		}
 	}
 
 	// Checking that migrations table schema are expected
 	selectTableSQL := selectMigrationsTableSQL(driver)
 	if rows, err := db.Query(selectTableSQL); err != nil {
-		beeLogger.Log.Fatalf("Could not show columns of migrations table: %s", err)
+		return fmt.Errorf("could not show columns of migrations table: %s", err)
 	} else {
 		for rows.Next() {
 			var fieldBytes, typeBytes, nullBytes, keyBytes, defaultBytes, extraBytes []byte
 			if err := rows.Scan(&fieldBytes, &typeBytes, &nullBytes, &keyBytes, &defaultBytes, &extraBytes); err != nil {
-				beeLogger.Log.Fatalf("Could not read column information: %s", err)
+				return fmt.Errorf("could not read column information: %s", err)
 			}
 			fieldStr, typeStr, nullStr, keyStr, defaultStr, extraStr :=
 				string(fieldBytes), string(typeBytes), string(nullBytes), string(keyBytes), string(defaultBytes), string(extraBytes)
 			if fieldStr == "id_migration" {
 				if keyStr != "PRI" || extraStr != "auto_increment" {
 					beeLogger.Log.Hint("Expecting KEY: PRI, EXTRA: auto_increment")
-					beeLogger.Log.Fatalf("Column migration.id_migration type mismatch: KEY: %s, EXTRA: %s", keyStr, extraStr)
+					return fmt.Errorf("column migration.id_migration type mismatch: KEY: %s, EXTRA: %s", keyStr, extraStr)
 				}
 			} else if fieldStr == "name" {
 				if !strings.HasPrefix(typeStr, "varchar") || nullStr != "YES" {
 					beeLogger.Log.Hint("Expecting TYPE: varchar, NULL: YES")
-					beeLogger.Log.Fatalf("Column migration.name type mismatch: TYPE: %s, NULL: %s", typeStr, nullStr)
+					return fmt.Errorf("column migration.name type mismatch: TYPE: %s, NULL: %s", typeStr, nullStr)
 				}
 			} else if fieldStr == "created_at" {
 				if typeStr != "timestamp" || (!strings.EqualFold(defaultStr, "CURRENT_TIMESTAMP") && !strings.EqualFold(defaultStr, "CURRENT_TIMESTAMP()")) {
 					beeLogger.Log.Hint("Expecting TYPE: timestamp, DEFAULT: CURRENT_TIMESTAMP || CURRENT_TIMESTAMP()")
-					beeLogger.Log.Fatalf("Column migration.timestamp type mismatch: TYPE: %s, DEFAULT: %s", typeStr, defaultStr)
+					return fmt.Errorf("column migration.timestamp type mismatch: TYPE: %s, DEFAULT: %s", typeStr, defaultStr)
 				}
 			}
 		}
 	}
+	return nil
 }
 
 func driverImportStatement(driver string) string {
@@ -275,96 +410,90 @@ func selectMigrationsTableSQL(driver string) string {
 }
 
 // getLatestMigration retrives latest migration with status 'update'
-func getLatestMigration(db *sql.DB, goal string) (file string, createdAt int64) {
+func getLatestMigration(db *sql.DB, goal string) (file string, createdAt int64, err error) {
 	sql := "SELECT name FROM migrations where status = 'update' ORDER BY id_migration DESC LIMIT 1"
-	if rows, err := db.Query(sql); err != nil {
-		beeLogger.Log.Fatalf("Could not retrieve migrations: %s", err)
-	} else {
-		if rows.Next() {
-			if err := rows.Scan(&file); err != nil {
-				beeLogger.Log.Fatalf("Could not read migrations in database: %s", err)
-			}
-			createdAtStr := file[len(file)-15:]
-			if t, err := time.Parse("20060102_150405", createdAtStr); err != nil {
-				beeLogger.Log.Fatalf("Could not parse time: %s", err)
-			} else {
-				createdAt = t.Unix()
-			}
-		} else {
-			// migration table has no 'update' record, no point rolling back
-			if goal == "rollback" {
-				beeLogger.Log.Fatal("There is nothing to rollback")
-			}
-			file, createdAt = "", 0
+	rows, err := db.Query(sql)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not retrieve migrations: %s", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&file); err != nil {
+			return "", 0, fmt.Errorf("could not read migrations in database: %s", err)
+		}
+		createdAtStr := file[len(file)-15:]
+		t, err := time.Parse("20060102_150405", createdAtStr)
+		if err != nil {
+			return "", 0, fmt.Errorf("could not parse time: %s", err)
 		}
+		return file, t.Unix(), nil
 	}
-	return
+	// migration table has no 'update' record, no point rolling back
+	if goal == "rollback" {
+		return "", 0, errNothingToRollback
+	}
+	return "", 0, nil
 }
 
 // writeMigrationSourceFile create the source file based on MIGRATION_MAIN_TPL
 // 根据模板生成迁移的 Go 文件
-func writeMigrationSourceFile(dir, source, driver, connStr string, latestTime int64, latestName string, task string) {
-	changeDir(dir)
-	if f, err := os.OpenFile(source, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0666); err != nil {
-		beeLogger.Log.Fatalf("Could not create file: %s", err)
-	} else {
-		content := strings.Replace(MigrationMainTPL, "{{DBDriver}}", driver, -1)
-		content = strings.Replace(content, "{{DriverRepo}}", driverImportStatement(driver), -1)
-		content = strings.Replace(content, "{{ConnStr}}", connStr, -1)
-		content = strings.Replace(content, "{{LatestTime}}", strconv.FormatInt(latestTime, 10), -1)
-		content = strings.Replace(content, "{{LatestName}}", latestName, -1)
-		content = strings.Replace(content, "{{Task}}", task, -1)
-		if _, err := f.WriteString(content); err != nil {
-			beeLogger.Log.Fatalf("Could not write to file: %s", err)
-		}
-		utils.CloseFile(f)
+func writeMigrationSourceFile(f *os.File, driver, connStr string, latestTime int64, latestName string, task string) error {
+	defer utils.CloseFile(f)
+	content := strings.Replace(MigrationMainTPL, "{{DBDriver}}", driver, -1)
+	content = strings.Replace(content, "{{DriverRepo}}", driverImportStatement(driver), -1)
+	content = strings.Replace(content, "{{ConnStr}}", connStr, -1)
+	content = strings.Replace(content, "{{LatestTime}}", strconv.FormatInt(latestTime, 10), -1)
+	content = strings.Replace(content, "{{LatestName}}", latestName, -1)
+	content = strings.Replace(content, "{{Task}}", task, -1)
+	if _, err := f.WriteString(content); err != nil {
+		return fmt.Errorf("could not write to file: %s", err)
 	}
+	return nil
 }
 
-// buildMigrationBinary changes directory to database/migrations folder and go-build the source
-// 编译迁移的 Go 源文件为二进制文件
-func buildMigrationBinary(dir, binary string) {
-	changeDir(dir)
-	_ = exec.Command("go", "mod", "tidy").Run()
+// buildMigrationBinary go-builds the source in the database/migrations folder
+// 在迁移目录中编译迁移的 Go 源文件为二进制文件，不改变当前工作目录
+func buildMigrationBinary(dir, binary string) error {
+	tidy := exec.Command("go", "mod", "tidy")
+	tidy.Dir = dir
+	_ = tidy.Run()
 	cmd := exec.Command("go", "build", "-o", binary)
+	cmd.Dir = dir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		beeLogger.Log.Errorf("Could not build migration binary: %s", err)
 		formatShellErrOutput(string(out))
-		removeTempFile(dir, binary)
-		removeTempFile(dir, binary+".go")
-		os.Exit(2)
+		return fmt.Errorf("could not build migration binary: %s", err)
 	}
+	return nil
 }
 
 // runMigrationBinary runs the migration program who does the actual work
 // 执行编译后的迁移二进制文件
-func runMigrationBinary(dir, binary string) {
-	changeDir(dir)
+func runMigrationBinary(dir, binary string) error {
 	cmd := exec.Command("./" + binary)
-	if out, err := cmd.CombinedOutput(); err != nil {
-		formatShellOutput(string(out))
-		beeLogger.Log.Errorf("Could not run migration binary: %s", err)
-		removeTempFile(dir, binary)
-		removeTempFile(dir, binary+".go")
-		os.Exit(2)
-	} else {
-		formatShellOutput(string(out))
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	formatShellOutput(string(out))
+	if err != nil {
+		return fmt.Errorf("could not run migration binary: %s", err)
 	}
+	return nil
 }
 
-// changeDir changes working directory to dir.
-// It exits the system when encouter an error
-func changeDir(dir string) {
-	if err := os.Chdir(dir); err != nil {
-		beeLogger.Log.Fatalf("Could not find migration directory: %s", err)
+// checkDir checks that the migration directory exists.
+// 迁移在该目录中编译和运行而不切换当前工作目录，-db=all 时后续 profile 的相对路径不受影响
+func checkDir(dir string) error {
+	if info, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("could not find migration directory: %s", err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("could not find migration directory: %s is not a directory", dir)
 	}
+	return nil
 }
 
 // removeTempFile removes a file in dir
 // 在迁移完成后清理临时生成的文件
 func removeTempFile(dir, file string) {
-	changeDir(dir)
-	if err := os.Remove(file); err != nil {
+	if err := os.Remove(filepath.Join(dir, file)); err != nil && !os.IsNotExist(err) {
 		beeLogger.Log.Warnf("Could not remove temporary file: %s", err)
 	}
 }
@@ -455,20 +584,27 @@ CREATE TABLE migrations (
 
 // MigrateUpdate does the schema update
 func MigrateUpdate(currpath, driver, connStr, dir string) {
-	migrate("upgrade", currpath, driver, connStr, dir)
+	mustMigrate("upgrade", currpath, driver, connStr, dir)
 }
 
 // MigrateRollback rolls back the latest migration
 func MigrateRollback(currpath, driver, connStr, dir string) {
-	migrate("rollback", currpath, driver, connStr, dir)
+	mustMigrate("rollback", currpath, driver, connStr, dir)
 }
 
 // MigrateReset rolls back all migrations
 func MigrateReset(currpath, driver, connStr, dir string) {
-	migrate("reset", currpath, driver, connStr, dir)
+	mustMigrate("reset", currpath, driver, connStr, dir)
 }
 
 // MigrateRefresh rolls back all migrations and start over again
 func MigrateRefresh(currpath, driver, connStr, dir string) {
-	migrate("refresh", currpath, driver, connStr, dir)
+	mustMigrate("refresh", currpath, driver, connStr, dir)
+}
+
+// mustMigrate runs the migration and exits when it fails
+func mustMigrate(goal, currpath, driver, connStr, dir string) {
+	if err := migrate(goal, currpath, driver, connStr, dir); err != nil {
+		beeLogger.Log.Fatalf("%s", err)
+	}
 }
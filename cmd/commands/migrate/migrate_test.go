@@ -0,0 +1,104 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/beego/bee/v2/config"
+)
+
+func TestAbsMigrationDir(t *testing.T) {
+	testCases := []struct {
+		dir      string
+		expected string
+	}{
+		{dir: "database/migrations", expected: "/app/database/migrations"},
+		{dir: "./db/../migrations", expected: "/app/migrations"},
+		{dir: "/srv/migrations", expected: "/srv/migrations"},
+		{dir: `C:\migrations`, expected: `C:\migrations`},
+		{dir: "m", expected: "/app/m"},
+	}
+	for _, tc := range testCases {
+		if actual := absMigrationDir("/app", tc.dir); actual != tc.expected {
+			t.Errorf("absMigrationDir(%q) = %q, expected %q", tc.dir, actual, tc.expected)
+		}
+	}
+}
+
+func TestNewMigrationTarget(t *testing.T) {
+	saved := config.Conf
+	t.Cleanup(func() { config.Conf = saved })
+	config.Conf.Database = config.Database{Driver: "postgres", Conn: "postgres://app"}
+	config.Conf.Databases = map[string]config.Database{
+		"test":      {Conn: "postgres://test", Dir: "db/test"},
+		"analytics": {Driver: "mysql", Dir: "/srv/analytics"},
+	}
+
+	testCases := []struct {
+		profile  string
+		expected migrationTarget
+		err      string
+	}{
+		{
+			profile:  "",
+			expected: migrationTarget{Profile: "default", Driver: "postgres", Conn: "postgres://app", Dir: "/app/database/migrations"},
+		},
+		{
+			profile:  "default",
+			expected: migrationTarget{Profile: "default", Driver: "postgres", Conn: "postgres://app", Dir: "/app/database/migrations"},
+		},
+		{
+			profile:  "test",
+			expected: migrationTarget{Profile: "test", Driver: "postgres", Conn: "postgres://test", Dir: "/app/db/test"},
+		},
+		{
+			profile:  "analytics",
+			expected: migrationTarget{Profile: "analytics", Driver: "mysql", Conn: "root:@tcp(127.0.0.1:3306)/test", Dir: "/srv/analytics"},
+		},
+		{
+			profile: "unknown",
+			err:     "unknown database profile 'unknown', available profiles: [analytics default test]",
+		},
+	}
+	for _, tc := range testCases {
+		actual, err := newMigrationTarget(tc.profile, "/app")
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%q: expected error %q, got %v", tc.profile, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tc.profile, err)
+		} else if actual != tc.expected {
+			t.Errorf("%q: expected %+v, got %+v", tc.profile, tc.expected, actual)
+		}
+	}
+
+	// -db=all migrates every profile, the top-level database included
+	var profiles []string
+	for _, name := range config.DatabaseProfileNames() {
+		target, err := newMigrationTarget(name, "/app")
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %s", name, err)
+		}
+		profiles = append(profiles, target.Profile)
+	}
+	if expected := "[analytics default test]"; fmt.Sprint(profiles) != expected {
+		t.Errorf("expected the profiles %s, got %v", expected, profiles)
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/beego/bee/v2/cmd/commands"
 	"github.com/beego/bee/v2/cmd/commands/version"
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	beeLogger "github.com/beego/bee/v2/logger"
 	"github.com/beego/bee/v2/logger/colors"
 	"github.com/beego/bee/v2/utils"
@@ -262,6 +263,7 @@ func init() {
 	CmdNew.Flag.Var(&gopath, "gopath", "Support go path,default false")
 	// beegoVersion: 用来指定 Beego 的版本，仅在使用 Go Modules 时生效
 	CmdNew.Flag.Var(&beegoVersion, "beego", "set beego version,only take effect by go mod")
+	CmdNew.AddDryRunFlag()
 	commands.AvailableCommands = append(commands.AvailableCommands, CmdNew)
 }
 
@@ -313,33 +315,33 @@ func CreateApp(cmd *commands.Command, args []string) int {
 	}
 
 	// 创建 Beego 项目所需的基本目录和文件
-	os.MkdirAll(appPath, 0755)
+	generated.MkdirAll(appPath)
 	if gopath != `true` {
 		fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "go.mod"), "\x1b[0m")
 		utils.WriteToFile(path.Join(appPath, "go.mod"), fmt.Sprintf(goMod, packPath, utils.GetGoVersionSkipMinor(), beegoVersion.String()))
 	}
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", appPath+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "conf"), 0755)
+	generated.MkdirAll(path.Join(appPath, "conf"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "conf")+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "controllers"), 0755)
+	generated.MkdirAll(path.Join(appPath, "controllers"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "controllers")+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "models"), 0755)
+	generated.MkdirAll(path.Join(appPath, "models"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "models")+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "routers"), 0755)
+	generated.MkdirAll(path.Join(appPath, "routers"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "routers")+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "tests"), 0755)
+	generated.MkdirAll(path.Join(appPath, "tests"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "tests")+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "static"), 0755)
+	generated.MkdirAll(path.Join(appPath, "static"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "static")+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "static", "js"), 0755)
+	generated.MkdirAll(path.Join(appPath, "static", "js"))
 	utils.WriteToFile(path.Join(appPath, "static", "js", "reload.min.js"), reloadJsClient)
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "static", "js")+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "static", "css"), 0755)
+	generated.MkdirAll(path.Join(appPath, "static", "css"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "static", "css")+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "static", "img"), 0755)
+	generated.MkdirAll(path.Join(appPath, "static", "img"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "static", "img")+string(path.Separator), "\x1b[0m")
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "views")+string(path.Separator), "\x1b[0m")
-	os.Mkdir(path.Join(appPath, "views"), 0755)
+	generated.MkdirAll(path.Join(appPath, "views"))
 	fmt.Fprintf(output, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", path.Join(appPath, "conf", "app.conf"), "\x1b[0m")
 	utils.WriteToFile(path.Join(appPath, "conf", "app.conf"), strings.Replace(appconf, "{{.Appname}}", path.Base(args[0]), -1))
 
@@ -0,0 +1,248 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package routes implements the 'bee routes' command
+package routes
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/beego/bee/v2/cmd/commands"
+	"github.com/beego/bee/v2/generate"
+	"github.com/beego/bee/v2/generate/swaggergen"
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
+)
+
+// 不运行应用，通过 swaggergen 的路由发现（命名空间、Include、Router）和 gen_routes 解析的 @Filter 注释
+// 列出应用注册的所有路由，并标记被之后注册的路由遮蔽的路由。
+
+var CmdRoutes = &commands.Command{
+	UsageLine: "routes [-format=table|json|csv] [-ctrlDir=controllers]",
+	Short:     "Prints the route table of the application",
+	Long: `The command 'routes' prints the routes registered by the routers package,
+  without running the application: the HTTP method, the path, the controller
+  method and the filters declared with @Filter, with their position.
+
+  ▶ {{"To print the routes as a table:"|bold}}
+
+    $ bee routes
+
+  ▶ {{"To print the routes as JSON or CSV:"|bold}}
+
+    $ bee routes -format=json
+    $ bee routes -format=csv > routes.csv
+
+  Routes whose requests all match a route registered after them, with the
+  same method, are flagged as shadowed: beego dispatches them to the last route.
+`,
+	Run: printRoutes,
+}
+
+var (
+	format  utils.DocValue
+	ctrlDir utils.DocValue
+)
+
+func init() {
+	CmdRoutes.Flag.Var(&format, "format", "Output format. Either table (default), json or csv.")
+	CmdRoutes.Flag.Var(&ctrlDir, "ctrlDir", "Controller directory, for the @Filter annotations. Default: controllers")
+	commands.AvailableCommands = append(commands.AvailableCommands, CmdRoutes)
+}
+
+// route is a row of the route table
+type route struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Controller string   `json:"controller"`
+	Func       string   `json:"func"`
+	Filters    []filter `json:"filters,omitempty"`
+	Source     string   `json:"source"`
+	ShadowedBy string   `json:"shadowedBy,omitempty"`
+}
+
+type filter struct {
+	Pos     string `json:"pos"`
+	Pattern string `json:"pattern"`
+	Filter  string `json:"filter"`
+}
+
+func printRoutes(cmd *commands.Command, args []string) int {
+	if err := cmd.Flag.Parse(args); err != nil {
+		beeLogger.Log.Fatalf("Error while parsing flags: %v", err.Error())
+	}
+	if format == "" {
+		format = "table"
+	}
+	if format != "table" && format != "json" && format != "csv" {
+		beeLogger.Log.Fatalf("Unknown format '%s'. Must be either table, json or csv", format)
+	}
+	if format != "table" {
+		// keep the output parsable
+		beeLogger.Log.SetOutput(os.Stderr)
+	}
+	dir := ctrlDir.String()
+	if dir == "" {
+		dir = "controllers"
+	}
+	currpath, _ := os.Getwd()
+
+	filters := map[string]map[string][]generate.ControllerFilter{}
+	if utils.IsExist(dir) {
+		var err error
+		if filters, err = generate.ControllerFilters(dir); err != nil {
+			beeLogger.Log.Fatalf("Could not parse the controllers: %s", err)
+		}
+	}
+
+	var routes []*route
+	for _, r := range swaggergen.Routes(currpath) {
+		rt := &route{
+			Method:     r.Method,
+			Path:       r.Path,
+			Controller: path.Base(r.Package) + "." + r.Controller,
+			Func:       r.Func,
+			Source:     r.Source,
+		}
+		for _, f := range filters[r.Package+":"+r.Controller][r.Func] {
+			rt.Filters = append(rt.Filters, filter{Pos: strings.TrimPrefix(f.Pos, "beego."), Pattern: f.Pattern, Filter: f.Filter})
+		}
+		routes = append(routes, rt)
+	}
+	shadowed := flagShadowed(routes)
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(routes, "", "  ")
+		if err != nil {
+			beeLogger.Log.Fatalf("Could not encode the routes: %s", err)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"method", "path", "controller", "func", "filters", "source", "shadowed_by"})
+		for _, r := range routes {
+			w.Write([]string{r.Method, r.Path, r.Controller, r.Func, filtersText(r.Filters), r.Source, r.ShadowedBy})
+		}
+		w.Flush()
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tFILTERS\tSOURCE")
+		for _, r := range routes {
+			fmt.Fprintf(w, "%s\t%s\t%s.%s\t%s\t%s\n", r.Method, r.Path, r.Controller, r.Func, filtersText(r.Filters), r.Source)
+		}
+		w.Flush()
+	}
+
+	for _, r := range routes {
+		if r.ShadowedBy != "" {
+			beeLogger.Log.Warnf("%s %s (%s.%s, %s) is shadowed by %s", r.Method, r.Path, r.Controller, r.Func, r.Source, r.ShadowedBy)
+		}
+	}
+	if shadowed > 0 {
+		beeLogger.Log.Warnf("%d route(s) are shadowed by routes registered after them", shadowed)
+	}
+	return 0
+}
+
+func filtersText(filters []filter) string {
+	var parts []string
+	for _, f := range filters {
+		parts = append(parts, fmt.Sprintf("%s %s %s", f.Pos, f.Pattern, f.Filter))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// paramName matches the name of a path parameter, e.g. :id in :id:int or :id([0-9]+)
+var paramName = regexp.MustCompile(`^:\w+`)
+
+// paramTypes are the constraints of the typed parameters, e.g. :id:int
+var paramTypes = strings.NewReplacer("::int", ":([0-9]+)", "::string", `:([\w]+)`)
+
+// flagShadowed flags the routes whose requests are dispatched to a route
+// registered after them, and returns their number. The routes ending at the
+// same node of the beego router tree are matched from the last registered one.
+func flagShadowed(routes []*route) int {
+	shadowed := 0
+	for i, r := range routes {
+		for j := len(routes) - 1; j > i; j-- {
+			next := routes[j]
+			if next.Method != r.Method || !covers(next.Path, r.Path) {
+				continue
+			}
+			if next.Controller == r.Controller && next.Func == r.Func && next.Path == r.Path {
+				// the same route registered twice
+				r.ShadowedBy = fmt.Sprintf("the same route at %s", next.Source)
+			} else {
+				r.ShadowedBy = fmt.Sprintf("%s %s (%s.%s, %s)", next.Method, next.Path, next.Controller, next.Func, next.Source)
+			}
+			shadowed++
+			break
+		}
+	}
+	return shadowed
+}
+
+// covers reports whether the requests matching the pattern b are dispatched
+// to the pattern a when a is registered after b: the static segments are
+// equal, and a has an unconstrained parameter or the same parameter where b
+// has a parameter, or a ends with a wildcard where b ends with a parameter.
+// The static segments take precedence over the parameters, and the longer
+// patterns over a wildcard, whatever their order.
+func covers(a, b string) bool {
+	as, bs := segments(a), segments(b)
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		switch {
+		case as[i] == bs[i]:
+		case as[i] == "*" && i == len(as)-1 && isParam(bs[i]):
+			// * matches the rest of the path
+		case as[i] == ":" && isParam(bs[i]) && bs[i] != "*":
+			// an unconstrained parameter matches a segment, not the rest of the path
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// segments returns the segments of a path with the names of the parameters
+// removed, e.g. [user : :([0-9]+)] for /user/:name/:id:int, *.* is a wildcard too
+func segments(p string) []string {
+	var segs []string
+	for _, s := range strings.Split(p, "/") {
+		if s == "" {
+			continue
+		}
+		if s == "*.*" {
+			s = "*"
+		}
+		segs = append(segs, paramTypes.Replace(paramName.ReplaceAllString(s, ":")))
+	}
+	return segs
+}
+
+// isParam reports whether a segment is a parameter or a wildcard
+func isParam(seg string) bool {
+	return strings.HasPrefix(seg, ":") || seg == "*"
+}
@@ -0,0 +1,110 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+func TestCovers(t *testing.T) {
+	testCases := []struct {
+		first   string // registered first
+		last    string // registered last
+		covered bool   // all the requests of first are dispatched to last
+		request string // dispatched to last when covered, to first otherwise
+	}{
+		// static
+		{first: "/user", last: "/user", covered: true, request: "/user"},
+		{first: "/user", last: "/user/", covered: true, request: "/user"},
+		{first: "/v1/user/:id", last: "/v2/user/:id", covered: false, request: "/v1/user/5"},
+		{first: "/user/profile", last: "/user/:id", covered: false, request: "/user/profile"},
+		{first: "/user/:id", last: "/user/profile", covered: false, request: "/user/5"},
+		// parameters and regexps
+		{first: "/user/:id", last: "/user/:name", covered: true, request: "/user/5"},
+		{first: "/user/:id:int", last: "/user/:name", covered: true, request: "/user/5"},
+		{first: "/user/:name", last: "/user/:id:int", covered: false, request: "/user/abc"},
+		{first: "/user/:id:int", last: "/user/:uid([0-9]+)", covered: true, request: "/user/5"},
+		{first: "/user/:name:string", last: "/user/:login([\\w]+)", covered: true, request: "/user/abc"},
+		{first: "/user/:id([0-9]+)", last: "/user/:name([a-z]+)", covered: false, request: "/user/5"},
+		{first: "/user/:id/posts", last: "/user/:uid/posts", covered: true, request: "/user/5/posts"},
+		{first: "/user/:id/posts", last: "/user/:uid/comments", covered: false, request: "/user/5/posts"},
+		// wildcards
+		{first: "/user/:id", last: "/user/*", covered: true, request: "/user/5"},
+		{first: "/user/*", last: "/user/*", covered: true, request: "/user/5/6"},
+		{first: "/user/*", last: "/user/:id", covered: false, request: "/user/5/6"},
+		{first: "/user/*", last: "/user/*.*", covered: true, request: "/user/avatar.png"},
+		{first: "/user/:id/posts", last: "/user/*", covered: false, request: "/user/5/posts"},
+		{first: "/user/profile", last: "/user/*", covered: false, request: "/user/profile"},
+		{first: "/user", last: "/user/*", covered: false, request: "/user"},
+		{first: "/files/*.*", last: "/files/:name", covered: false, request: "/files/a/b.txt"},
+		{first: "/user/:id", last: "/*", covered: false, request: "/user/5"},
+	}
+	for _, tc := range testCases {
+		if actual := covers(tc.last, tc.first); actual != tc.covered {
+			t.Errorf("covers(%q, %q) = %v, expected %v", tc.last, tc.first, actual, tc.covered)
+		}
+
+		// the expectation is the dispatch of the beego router
+		tree := web.NewTree()
+		tree.AddRouter(tc.first, tc.first)
+		tree.AddRouter(tc.last, tc.last)
+		ctx := context.NewContext()
+		ctx.Reset(nil, nil)
+		expected := tc.first
+		if tc.covered {
+			expected = tc.last
+		}
+		if dispatched := tree.Match(tc.request, ctx); dispatched != expected {
+			t.Errorf("%s with %s then %s: expected the router to dispatch to %s, got %v", tc.request, tc.first, tc.last, expected, dispatched)
+		}
+	}
+}
+
+func TestFlagShadowed(t *testing.T) {
+	routes := []*route{
+		{Method: "GET", Path: "/user/:id:int", Controller: "controllers.UserController", Func: "Get", Source: "routers/router.go:10"},
+		{Method: "POST", Path: "/user/:id:int", Controller: "controllers.UserController", Func: "Post", Source: "routers/router.go:11"},
+		{Method: "GET", Path: "/user/profile", Controller: "controllers.UserController", Func: "Profile", Source: "routers/router.go:12"},
+		{Method: "GET", Path: "/static/*", Controller: "controllers.StaticController", Func: "Get", Source: "routers/router.go:13"},
+		{Method: "GET", Path: "/user/:uid", Controller: "controllers.ObjectController", Func: "Get", Source: "routers/users.go:15"},
+		{Method: "PUT", Path: "/user/:uid", Controller: "controllers.ObjectController", Func: "Put", Source: "routers/users.go:16"},
+		{Method: "GET", Path: "/static/*", Controller: "controllers.StaticController", Func: "Get", Source: "routers/static.go:8"},
+		{Method: "GET", Path: "/user/:name", Controller: "controllers.UserController", Func: "Find", Source: "routers/users.go:20"},
+	}
+	expected := []string{
+		// the last of the routes covering it
+		"GET /user/:name (controllers.UserController.Find, routers/users.go:20)",
+		// the other methods do not shadow it
+		"",
+		// the static segments take precedence
+		"",
+		"the same route at routers/static.go:8",
+		"GET /user/:name (controllers.UserController.Find, routers/users.go:20)",
+		"",
+		"",
+		"",
+	}
+	if shadowed := flagShadowed(routes); shadowed != 3 {
+		t.Errorf("expected 3 shadowed routes, got %d", shadowed)
+	}
+	for i, r := range routes {
+		if r.ShadowedBy != expected[i] {
+			t.Errorf("%s %s (%s): expected shadowed by %q, got %q", r.Method, r.Path, r.Source, expected[i], r.ShadowedBy)
+		}
+	}
+}
@@ -66,6 +66,9 @@ func runScript(cmd *commands.Command, args []string) int {
 
 	// 检查 script 是否在 bee.json 或 Beefile 配置中的 Scripts 字段中定义。如果定义了，c 是脚本对应的命令字符串。
 	if c, exist := config.Conf.Scripts[script]; exist {
+		if err := config.Resolved("scripts." + script); err != nil {
+			beeLogger.Log.Fatalf("Invalid configuration: %s", err)
+		}
 		command := customCommand{
 			Name:    script,
 			Command: c,
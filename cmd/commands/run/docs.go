@@ -1,90 +1,34 @@
 package run
 
 import (
-	"archive/zip"
-	"io"
 	"net/http"
-	"os"
-	"strings"
+	path "path/filepath"
 
+	"github.com/beego/bee/v2/internal/pkg/swaggerui"
 	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
 )
 
-var (
-	swaggerVersion = "4.6.1"
-	swaggerlink    = "https://codeload.github.com/beego/swagger/zip/refs/tags/v" + swaggerVersion
-)
+// 写入 bee 内嵌的 Swagger UI，或者在 bee 的开发服务器上直接提供文档，不需要联网下载
 
-// 从指定的请求路径下载文件
-func downloadFromURL(url, fileName string) {
-	var down bool
-	if fd, err := os.Stat(fileName); err != nil && os.IsNotExist(err) {
-		down = true
-	} else if fd.Size() == int64(0) {
-		down = true
-	} else {
-		beeLogger.Log.Infof("'%s' already exists", fileName)
+// writeSwaggerUI writes the embedded Swagger UI into the swagger directory of the
+// application, unless the index and the script of Swagger UI are already there
+func writeSwaggerUI(appPath string) {
+	dir := path.Join(appPath, "swagger")
+	if utils.IsExist(path.Join(dir, "index.html")) && utils.IsExist(path.Join(dir, "swagger-ui-bundle.js")) {
 		return
 	}
-	if down {
-		beeLogger.Log.Infof("Downloading '%s' to '%s'...", url, fileName)
-		output, err := os.Create(fileName)
-		if err != nil {
-			beeLogger.Log.Errorf("Error while creating '%s': %s", fileName, err)
-			return
-		}
-		defer output.Close()
-
-		response, err := http.Get(url)
-		if err != nil {
-			beeLogger.Log.Errorf("Error while downloading '%s': %s", url, err)
-			return
-		}
-		defer response.Body.Close()
-
-		n, err := io.Copy(output, response.Body)
-		if err != nil {
-			beeLogger.Log.Errorf("Error while downloading '%s': %s", url, err)
-			return
-		}
-		beeLogger.Log.Successf("%d bytes downloaded!", n)
+	beeLogger.Log.Infof("Writing Swagger UI %s to '%s'...", swaggerui.Version, dir)
+	if err := swaggerui.WriteTo(dir); err != nil {
+		beeLogger.Log.Errorf("Error while writing Swagger UI: %s", err)
+		return
 	}
+	beeLogger.Log.Success("Swagger UI written!")
 }
 
-// 解压并删除压缩文件
-func unzipAndDelete(src string) error {
-	beeLogger.Log.Infof("Unzipping '%s'...", src)
-	r, err := zip.OpenReader(src)
-	if err != nil {
-		return err
-	}
-	defer r.Close()
-
-	rp := strings.NewReplacer("swagger-"+swaggerVersion, "swagger")
-	for _, f := range r.File {
-		rc, err := f.Open()
-		if err != nil {
-			return err
-		}
-		defer rc.Close()
-
-		fname := rp.Replace(f.Name)
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(fname, f.Mode())
-		} else {
-			f, err := os.OpenFile(
-				fname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-
-			_, err = io.Copy(f, rc)
-			if err != nil {
-				return err
-			}
-		}
-	}
-	beeLogger.Log.Successf("Done! Deleting '%s'...", src)
-	return os.RemoveAll(src)
+// serveSwaggerUI serves the embedded Swagger UI and the documents of the
+// application at /swagger/ on the bee server
+func serveSwaggerUI(appPath string) {
+	http.Handle("/swagger/", http.StripPrefix("/swagger", swaggerui.Handler(path.Join(appPath, "swagger"))))
+	beeLogger.Log.Infof("Serving the docs at http://localhost%s/swagger/", reloadAddress)
 }
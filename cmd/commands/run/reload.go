@@ -160,10 +160,10 @@ func startReloadServer() {
 		handleWsRequest(broker, w, r)
 	})
 
-	go startServer() // 启动 HTTP 服务器
 	beeLogger.Log.Infof("Reload server listening at %s", reloadAddress)
 }
 
+// startServer 启动 bee 的 HTTP 服务器，提供热重载和文档
 func startServer() {
 	err := http.ListenAndServe(reloadAddress, nil)
 	if err != nil {
@@ -30,7 +30,7 @@ import (
 // 这段代码实现了 Beego 框架的 run 命令，用于启动本地开发服务器并监控文件变化。它在开发过程中自动重新编译和重启应用。
 
 var CmdRun = &commands.Command{
-	UsageLine: "run [appname] [watchall] [-main=*.go] [-downdoc=true]  [-gendoc=true] [-vendor=true] [-e=folderToExclude] [-ex=extraPackageToWatch] [-tags=goBuildTags] [-runmode=BEEGO_RUNMODE]",
+	UsageLine: "run [appname] [watchall] [-main=*.go] [-downdoc=true] [-servedoc=true] [-gendoc=true] [-vendor=true] [-e=folderToExclude] [-ex=extraPackageToWatch] [-tags=goBuildTags] [-runmode=BEEGO_RUNMODE]",
 	Short:     "Run the application by starting a local development server",
 	Long: `
 Run command will supervise the filesystem of the application for any changes, and recompile/restart it.
@@ -43,6 +43,7 @@ Run command will supervise the filesystem of the application for any changes, an
 var (
 	mainFiles utils.ListOpts
 	downdoc   utils.DocValue
+	servedoc  utils.DocValue
 	gendoc    utils.DocValue
 	// The flags list of the paths excluded from watching
 	excludedPaths utils.StrFlags
@@ -74,8 +75,10 @@ func init() {
 	CmdRun.Flag.Var(&mainFiles, "main", "Specify main go files.")
 	// gendoc: 是否启用自动生成文档
 	CmdRun.Flag.Var(&gendoc, "gendoc", "Enable auto-generate the docs.")
-	// downdoc: 是否启用自动下载 Swagger 文件
-	CmdRun.Flag.Var(&downdoc, "downdoc", "Enable auto-download of the swagger file if it does not exist.")
+	// downdoc: 如果不存在，将 bee 内嵌的 Swagger UI 写入应用的 swagger 目录
+	CmdRun.Flag.Var(&downdoc, "downdoc", "Write the embedded Swagger UI into the swagger directory if it is missing.")
+	// servedoc: 在 bee 的服务器上提供 Swagger UI 和文档，不写入应用目录
+	CmdRun.Flag.Var(&servedoc, "servedoc", "Serve the embedded Swagger UI and the docs at /swagger/ on the bee server.")
 	// e: 排除某些路径
 	CmdRun.Flag.Var(&excludedPaths, "e", "List of paths to exclude.")
 	// vendor: 是否监控 vendor 文件夹
@@ -149,6 +152,15 @@ func RunApp(cmd *commands.Command, args []string) int {
 		beeLogger.Log.Warnf("Using '%s' as 'runmode'", os.Getenv("BEEGO_RUNMODE"))
 	}
 
+	// 配置中无法插值的值不能用于启动应用
+	fields := []string{"envs", "dir_structure"}
+	if runargs == "" {
+		fields = append(fields, "cmd_args")
+	}
+	if err := config.Resolved(fields...); err != nil {
+		beeLogger.Log.Fatalf("Invalid configuration: %s", err)
+	}
+
 	// 读取应用程序目录，并开始监控文件
 	var paths []string
 	readAppDirectories(appPath, &paths)
@@ -188,20 +200,22 @@ func RunApp(cmd *commands.Command, args []string) int {
 			files = append(files, arg)
 		}
 	}
-	// 如果启用了 downdoc（下载文档），并且应用目录下没有 Swagger 文档，则会从指定的 URL 下载 Swagger 文档，并解压到本地
+	// 如果启用了 downdoc，并且应用目录下没有 Swagger UI，则写入 bee 内嵌的 Swagger UI
 	if downdoc == "true" {
-		if _, err := os.Stat(path.Join(appPath, "swagger", "index.html")); err != nil {
-			if os.IsNotExist(err) {
-				downloadFromURL(swaggerlink, "swagger.zip")
-				unzipAndDelete("swagger.zip")
-			}
-		}
+		writeSwaggerUI(appPath)
 	}
 
 	// Start the Reload server (if enabled) 如果启用了热重载（EnableReload），则启动重载服务器
 	if config.Conf.EnableReload {
 		startReloadServer()
 	}
+	// 如果启用了 servedoc，则在同一个服务器上提供文档
+	if servedoc == "true" {
+		serveSwaggerUI(appPath)
+	}
+	if config.Conf.EnableReload || servedoc == "true" {
+		go startServer() // 启动 HTTP 服务器
+	}
 	// 开始监控文件并自动构建
 	if gendoc == "true" { // 如果启用了文档生成（gendoc），则监控文件并启用自动构建
 		NewWatcher(paths, files, true)
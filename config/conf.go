@@ -15,15 +15,22 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/beego/bee/v2/internal/pkg/utils"
 	beeLogger "github.com/beego/bee/v2/logger"
 )
 
+// confVer is the current version of the configuration file format.
+// Older files are upgraded by `bee config migrate`.
 const confVer = 0
 
 const (
@@ -31,7 +38,11 @@ const (
 	GitRemotePath = "github.com/beego/bee/v2"
 )
 
-var Conf = struct {
+// Conf holds the bee tool configuration loaded by LoadConfig
+var Conf = newConf()
+
+// conf describes the content of bee.json and Beefile
+type conf struct {
 	Version            int
 	WatchExts          []string  `json:"watch_ext" yaml:"watch_ext"`
 	WatchExtsStatic    []string  `json:"watch_ext_static" yaml:"watch_ext_static"`
@@ -40,28 +51,46 @@ var Conf = struct {
 	CmdArgs            []string  `json:"cmd_args" yaml:"cmd_args"`
 	Envs               []string
 	Bale               bale
-	Database           database
-	EnableReload       bool              `json:"enable_reload" yaml:"enable_reload"`
-	EnableNotification bool              `json:"enable_notification" yaml:"enable_notification"`
-	Scripts            map[string]string `json:"scripts" yaml:"scripts"`
-}{
-	WatchExts:       []string{".go"},
-	WatchExtsStatic: []string{".html", ".tpl", ".js", ".css"},
-	GoInstall:       true,
-	DirStruct: dirStruct{
-		Others: []string{},
-	},
-	CmdArgs: []string{},
-	Envs:    []string{},
-	Bale: bale{
-		Dirs:   []string{},
-		IngExt: []string{},
-	},
-	Database: database{
-		Driver: "mysql",
-	},
-	EnableNotification: true,
-	Scripts:            map[string]string{},
+	Database           Database
+	Databases          map[string]Database `json:"databases" yaml:"databases"` // Named database profiles
+	EnableReload       bool                `json:"enable_reload" yaml:"enable_reload"`
+	EnableNotification bool                `json:"enable_notification" yaml:"enable_notification"`
+	Scripts            map[string]string   `json:"scripts" yaml:"scripts"`
+	Scaffold           scaffold            `json:"scaffold" yaml:"scaffold"`
+	Appcode            appcode             `json:"appcode" yaml:"appcode"`
+}
+
+// newConf returns the default configuration
+func newConf() conf {
+	return conf{
+		Version:         confVer,
+		WatchExts:       []string{".go"},
+		WatchExtsStatic: []string{".html", ".tpl", ".js", ".css"},
+		GoInstall:       true,
+		DirStruct: dirStruct{
+			Others: []string{},
+		},
+		CmdArgs: []string{},
+		Envs:    []string{},
+		Bale: bale{
+			Dirs:   []string{},
+			IngExt: []string{},
+		},
+		Database: Database{
+			Driver: "mysql",
+		},
+		Databases:          map[string]Database{},
+		EnableNotification: true,
+		Scripts:            map[string]string{},
+		Scaffold: scaffold{
+			Skip: []string{},
+		},
+		Appcode: appcode{
+			StripPrefix: []string{},
+			Models:      map[string]string{},
+			Fields:      map[string]map[string]string{},
+		},
+	}
 }
 
 // dirStruct describes the application's directory structure
@@ -79,63 +108,167 @@ type bale struct {
 	IngExt []string `json:"ignore_ext" yaml:"ignore_ext"`
 }
 
-// database holds the database connection information
-type database struct {
+// scaffold holds the defaults of `bee generate scaffold`
+type scaffold struct {
+	Yes         bool     // Run all the steps without asking
+	Skip        []string // Steps not to run: model, controller, views, migration, migrate, routes
+	RoutersFile string   `json:"routers_file" yaml:"routers_file"` // File in which the routes are registered
+	Namespace   string   // Namespace in which the routes are registered, the first one by default
+}
+
+// appcode holds the naming rules of `bee generate appcode`
+type appcode struct {
+	Schema      string                       // PostgreSQL schema of the tables, all the schemas by default
+	StripPrefix []string                     `json:"strip_prefix" yaml:"strip_prefix"` // Prefixes removed from the table names
+	Singularize bool                         // Name the models after the singular of the tables
+	Models      map[string]string            // Custom model names, by table
+	Fields      map[string]map[string]string // Custom field names, by table and column
+}
+
+// Database holds the connection information of a database profile
+type Database struct {
 	Driver string
 	Conn   string
 	Dir    string
 }
 
+// DefaultDatabaseProfile is the name under which the top-level
+// "database" section is exposed as a database profile.
+const DefaultDatabaseProfile = "default"
+
+// DatabaseProfile returns the database profile with the given name.
+// An empty name or "default" refers to the top-level "database" section,
+// unless a profile named "default" is declared under "databases".
+// 根据名称查找数据库配置，未声明驱动的 profile 会继承顶层 database 的驱动
+func DatabaseProfile(name string) (Database, error) {
+	if name == "" {
+		name = DefaultDatabaseProfile
+	}
+	if db, ok := Conf.Databases[name]; ok {
+		field := "databases." + name
+		if err := Resolved(field); err != nil {
+			return Database{}, err
+		}
+		if db.Driver == "" {
+			if err := Resolved("database.driver"); err != nil {
+				return Database{}, err
+			}
+			db.Driver = Conf.Database.Driver
+		}
+		return db, nil
+	}
+	if name == DefaultDatabaseProfile {
+		if err := Resolved("database"); err != nil {
+			return Database{}, err
+		}
+		return Conf.Database, nil
+	}
+	return Database{}, fmt.Errorf("unknown database profile '%s', available profiles: %v", name, DatabaseProfileNames())
+}
+
+// DatabaseProfileNames returns the names of all configured database profiles, sorted.
+// The top-level "database" section is listed as "default" when it has a
+// connection string or migration directory, or when no named profiles exist.
+func DatabaseProfileNames() []string {
+	names := make([]string, 0, len(Conf.Databases)+1)
+	for name := range Conf.Databases {
+		names = append(names, name)
+	}
+	if _, ok := Conf.Databases[DefaultDatabaseProfile]; !ok {
+		if len(Conf.Databases) == 0 || Conf.Database.Conn != "" || Conf.Database.Dir != "" {
+			names = append(names, DefaultDatabaseProfile)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Names of the configuration files looked up in the current path
+const (
+	JSONFile = "bee.json"
+	YAMLFile = "Beefile"
+)
+
+var (
+	// loadedFiles lists the configuration files read by LoadConfig, in order
+	loadedFiles []string
+	// sources maps each top-level configuration key to the file which set it
+	sources = map[string]string{}
+	// loadProblems collects the errors found while loading the configuration
+	loadProblems []Problem
+	// unresolved maps the fields whose references could not be expanded to the error
+	unresolved = map[string]error{}
+)
+
 // LoadConfig loads the bee tool configuration.
-// It looks for Beefile or bee.json in the current path,
-// and falls back to default configuration in case not found.
-// 用于加载并解析当前工作目录中的配置文件（bee.json 或 Beefile），并对配置进行相应的处理
+// It merges, in order, the user-global ~/.bee/config.yaml, the project
+// bee.json and Beefile, and the untracked Beefile.local of the current path,
+// and falls back to default configuration in case none is found.
+// 用于加载并解析全局配置、当前工作目录中的配置文件（bee.json 或 Beefile）及本地覆盖配置，并对配置进行相应的处理
 func LoadConfig() {
+	// 重新加载时从默认配置开始，避免重复解析和插值
+	Conf = newConf()
+	loadedFiles, sources, loadProblems, unresolved = nil, map[string]string{}, nil, map[string]error{}
+
 	// 获取当前工作目录
 	currentPath, err := os.Getwd()
 	if err != nil {
 		beeLogger.Log.Error(err.Error())
 	}
 
-	// 打开当前工作目录
-	dir, err := os.Open(currentPath)
-	if err != nil {
-		beeLogger.Log.Error(err.Error())
+	// 按固定顺序逐层解析并合并配置文件
+	merged := map[string]interface{}{}
+	for _, path := range configFiles(currentPath) {
+		raw, err := parseRaw(path)
+		if err != nil {
+			loadProblems = append(loadProblems, Problem{File: path, Message: err.Error()})
+			beeLogger.Log.Errorf("Failed to parse %s: %s", path, err)
+			beeLogger.Log.Hint("Run `bee config validate` for details.")
+			continue
+		}
+		mergeRaw(merged, raw, reflect.TypeOf(Conf), path, "")
+		loadedFiles = append(loadedFiles, path)
+	}
+	if err := decodeRaw(merged, &Conf); err != nil {
+		loadProblems = append(loadProblems, Problem{Message: err.Error()})
+		beeLogger.Log.Errorf("Failed to load configuration: %s", err)
 	}
-	defer dir.Close()
 
-	// 读取目录中的文件列表
-	files, err := dir.Readdir(-1) // 读取目录中的所有文件，并返回文件列表
-	if err != nil {
-		beeLogger.Log.Error(err.Error())
+	if utils.IsExist(filepath.Join(currentPath, JSONFile)) && utils.IsExist(filepath.Join(currentPath, YAMLFile)) {
+		loadProblems = append(loadProblems, Problem{
+			File:    filepath.Join(currentPath, YAMLFile),
+			Message: fmt.Sprintf("both %s and %s exist, values in %s override the ones in %s", JSONFile, YAMLFile, YAMLFile, JSONFile),
+			Warning: true,
+		})
+		beeLogger.Log.Warnf("Both %s and %s found, values in %s take precedence", JSONFile, YAMLFile, YAMLFile)
 	}
 
-	// 遍历文件列表并解析配置文件
-	for _, file := range files {
-		switch file.Name() {
-		case "bee.json":
-			{
-				err = parseJSON(filepath.Join(currentPath, file.Name()), &Conf)
-				if err != nil {
-					beeLogger.Log.Errorf("Failed to parse JSON file: %s", err)
-				}
-				break
-			}
-		case "Beefile":
-			{
-				err = parseYAML(filepath.Join(currentPath, file.Name()), &Conf)
-				if err != nil {
-					beeLogger.Log.Errorf("Failed to parse YAML file: %s", err)
-				}
-				break
-			}
-		}
+	// Expand environment variables and secret files 展开环境变量及 secret 文件引用
+	secrets, errs, fields := interpolateConf(&Conf)
+	unresolved = fields
+	for _, secret := range secrets {
+		beeLogger.Log.AddSecret(secret)
+	}
+	for _, err := range errs {
+		loadProblems = append(loadProblems, Problem{Message: err.Error()})
+		beeLogger.Log.Errorf("Failed to interpolate configuration: %s", err)
+	}
+	if len(errs) > 0 {
+		beeLogger.Log.Hint("The commands using these values fail until they are fixed.")
+	}
+	// 数据库密码即使是明文写在配置文件中，也不应出现在日志中
+	MaskConnPassword(Conf.Database.Conn)
+	for _, db := range Conf.Databases {
+		MaskConnPassword(db.Conn)
 	}
 
 	// Check format version 检查配置文件版本
-	if Conf.Version != confVer {
+	if Conf.Version < confVer {
 		beeLogger.Log.Warn("Your configuration file is outdated. Please do consider updating it.")
-		beeLogger.Log.Hint("Check the latest version of bee's configuration file.")
+		beeLogger.Log.Hint("Run `bee config migrate` to upgrade it to the latest version.")
+	} else if Conf.Version > confVer {
+		beeLogger.Log.Warnf("Your configuration file version %d is newer than the one supported by this bee (%d).", Conf.Version, confVer)
+		beeLogger.Log.Hint("Run `bee update` to update bee.")
 	}
 
 	// Set variables
@@ -149,6 +282,44 @@ func LoadConfig() {
 	}
 }
 
+// MaskConnPassword registers the password of a database connection
+// string so that it is masked in the log output.
+func MaskConnPassword(conn string) {
+	if p := dsnPassword(conn); p != "" {
+		beeLogger.Log.AddSecret(p)
+	}
+}
+
+// parseRaw parses a configuration file into a generic map
+func parseRaw(path string) (map[string]interface{}, error) {
+	raw := map[string]interface{}{}
+	var err error
+	if isYAML(path) {
+		err = parseYAML(path, &raw)
+	} else {
+		err = parseJSON(path, &raw)
+	}
+	return raw, err
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	base := filepath.Base(path)
+	return base == YAMLFile || base == LocalFile || ext == ".yaml" || ext == ".yml"
+}
+
+// LoadedFiles returns the configuration files read by LoadConfig, in merge order.
+func LoadedFiles() []string {
+	return loadedFiles
+}
+
+// Sources maps each top-level configuration key to the file which set it,
+// or to the comma separated files merged into it.
+// Keys missing from the map keep their default value.
+func Sources() map[string]string {
+	return sources
+}
+
 // 从指定路径读取一个 JSON 文件，并将其内容解析到传入的 interface{} 类型的变量中
 func parseJSON(path string, v interface{}) error {
 	var (
@@ -0,0 +1,97 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDatabaseProfileNames(t *testing.T) {
+	testCases := []struct {
+		name     string
+		beefile  string
+		expected []string
+	}{
+		{
+			name:     "no profiles",
+			beefile:  "database:\n  driver: mysql\n",
+			expected: []string{"default"},
+		},
+		{
+			name:     "named profiles only",
+			beefile:  "databases:\n  test:\n    conn: root@/test\n  analytics:\n    conn: root@/analytics\n",
+			expected: []string{"analytics", "test"},
+		},
+		{
+			name:     "named profiles and a default connection",
+			beefile:  "database:\n  conn: root@/app\ndatabases:\n  test:\n    conn: root@/test\n",
+			expected: []string{"default", "test"},
+		},
+		{
+			name:     "named profiles and a default directory",
+			beefile:  "database:\n  dir: db/migrations\ndatabases:\n  test:\n    conn: root@/test\n",
+			expected: []string{"default", "test"},
+		},
+		{
+			name:     "profile named default",
+			beefile:  "database:\n  conn: root@/app\ndatabases:\n  default:\n    conn: root@/default\n",
+			expected: []string{"default"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loadTestConfig(t, map[string]string{YAMLFile: tc.beefile})
+			if actual := DatabaseProfileNames(); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDatabaseProfile(t *testing.T) {
+	loadTestConfig(t, map[string]string{
+		YAMLFile: "database:\n  driver: postgres\n  conn: postgres://app\n  dir: db/migrations\n" +
+			"databases:\n  test:\n    conn: postgres://test\n  analytics:\n    driver: mysql\n    conn: root@/analytics\n    dir: db/analytics\n",
+	})
+
+	testCases := []struct {
+		profile  string
+		expected Database
+		err      string
+	}{
+		{profile: "", expected: Database{Driver: "postgres", Conn: "postgres://app", Dir: "db/migrations"}},
+		{profile: "default", expected: Database{Driver: "postgres", Conn: "postgres://app", Dir: "db/migrations"}},
+		// the driver is inherited from the top-level database, not the directory
+		{profile: "test", expected: Database{Driver: "postgres", Conn: "postgres://test"}},
+		{profile: "analytics", expected: Database{Driver: "mysql", Conn: "root@/analytics", Dir: "db/analytics"}},
+		{profile: "all", err: "unknown database profile 'all', available profiles: [analytics default test]"},
+		{profile: "unknown", err: "unknown database profile 'unknown', available profiles: [analytics default test]"},
+	}
+	for _, tc := range testCases {
+		actual, err := DatabaseProfile(tc.profile)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%q: expected error %q, got %v", tc.profile, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tc.profile, err)
+		} else if actual != tc.expected {
+			t.Errorf("%q: expected %+v, got %+v", tc.profile, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+package config
+
+import (
+	"reflect"
+
+	beeLogger "github.com/beego/bee/v2/logger"
+)
+
+// DefaultSource is the source of the settings which are not set by any file
+const DefaultSource = "default"
+
+// Setting is a top-level configuration key with its effective value
+type Setting struct {
+	Key    string
+	Value  interface{} // 使用配置文件中的字段名表示的值，敏感信息已屏蔽
+	Source string      // 设置该值的配置文件，未设置时为 "default"
+}
+
+// Settings returns the effective configuration, in declaration order,
+// with the file each key comes from. Secrets are masked.
+func Settings() []Setting {
+	v := reflect.ValueOf(Conf)
+	t := v.Type()
+	settings := make([]Setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key := fieldName(t.Field(i))
+		source, ok := sources[key]
+		if !ok {
+			source = DefaultSource
+		}
+		settings = append(settings, Setting{Key: key, Value: plain(v.Field(i)), Source: source})
+	}
+	return settings
+}
+
+// plain converts a configuration value into maps, slices and basic
+// values keyed by the names used in the configuration files.
+func plain(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.String:
+		return beeLogger.Log.Mask(v.String())
+	case reflect.Struct:
+		m := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			m[fieldName(v.Type().Field(i))] = plain(v.Field(i))
+		}
+		return m
+	case reflect.Slice:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = plain(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = plain(iter.Value())
+		}
+		return m
+	default:
+		return v.Interface()
+	}
+}
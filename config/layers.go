@@ -0,0 +1,190 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 配置按以下顺序分层加载，后加载的覆盖先加载的：
+//
+//  1. ~/.bee/config.yaml   用户全局配置（例如通知、重载端口等个人偏好）
+//  2. bee.json, Beefile    项目配置，提交到代码仓库
+//  3. Beefile.local        本地覆盖配置，不应提交到代码仓库
+//
+// 合并规则：
+//   - 标量值（字符串、数字、布尔值）：后者覆盖前者
+//   - 对象（例如 database、dir_structure、databases、scripts）：按键递归合并
+//   - 列表（例如 watch_ext、cmd_args、envs）：后者整体替换前者
+
+const (
+	// GlobalFile is the user-global configuration file, relative to the home directory
+	GlobalFile = ".bee/config.yaml"
+	// LocalFile is the untracked configuration file overriding the project one
+	LocalFile = "Beefile.local"
+)
+
+// GlobalFilePath returns the path of the user-global configuration file,
+// or an empty string if the home directory is unknown.
+func GlobalFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, filepath.FromSlash(GlobalFile))
+}
+
+// configFiles returns the existing configuration files of all layers, in loading order
+func configFiles(currentPath string) []string {
+	candidates := []string{
+		GlobalFilePath(),
+		filepath.Join(currentPath, JSONFile),
+		filepath.Join(currentPath, YAMLFile),
+		filepath.Join(currentPath, LocalFile),
+	}
+	var files []string
+	for _, path := range candidates {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// mergeRaw merges the decoded configuration file src into dst following
+// the layering rules, t being the type of the configuration value.
+// The keys of dst are the YAML names of the fields, decoded by decodeRaw.
+// The file which sets a top-level key is recorded in sources.
+func mergeRaw(dst, src map[string]interface{}, t reflect.Type, file, prefix string) {
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := src[key]
+		elemType, name, source := childType(t, key)
+		if elemType == nil {
+			// 未知字段由 Validate 报告，这里原样保留
+			dst[name] = normalize(value)
+			continue
+		}
+		m, isMap := toStringMap(value)
+		merge := isMap && isMergeable(elemType)
+		if prefix == "" {
+			if merge {
+				addSource(source, file)
+			} else {
+				sources[source] = file
+			}
+		}
+		if merge {
+			sub, ok := dst[name].(map[string]interface{})
+			if !ok {
+				sub = map[string]interface{}{}
+			}
+			mergeRaw(sub, m, elemType, file, joinField(prefix, source))
+			dst[name] = sub
+			continue
+		}
+		dst[name] = normalize(value)
+	}
+}
+
+// childType returns the type of the value stored under key in a value of type t,
+// its YAML name and its name in bee.json. Struct fields are matched case-insensitively,
+// by their JSON or YAML name.
+func childType(t reflect.Type, key string) (reflect.Type, string, string) {
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if strings.EqualFold(fieldName(f), key) || strings.EqualFold(yamlName(f), key) {
+				return f.Type, yamlName(f), fieldName(f)
+			}
+		}
+		return nil, key, key
+	case reflect.Map:
+		return t.Elem(), key, key
+	}
+	return nil, key, key
+}
+
+// yamlName returns the name of the struct field as written in Beefile
+func yamlName(f reflect.StructField) string {
+	if tag := f.Tag.Get("yaml"); tag != "" {
+		return strings.Split(tag, ",")[0]
+	}
+	return strings.ToLower(f.Name)
+}
+
+func isMergeable(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+}
+
+// normalize converts the maps decoded from YAML to map[string]interface{}
+func normalize(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}, map[string]interface{}:
+		m, _ := toStringMap(value)
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[k] = normalize(v)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, v := range value {
+			out[i] = normalize(v)
+		}
+		return out
+	}
+	return v
+}
+
+// addSource records that file sets the top-level key. Keys merged from
+// several files list all of them, in loading order.
+func addSource(key, file string) {
+	prev, ok := sources[key]
+	if !ok || prev == file {
+		sources[key] = file
+		return
+	}
+	for _, f := range strings.Split(prev, ", ") {
+		if f == file {
+			return
+		}
+	}
+	sources[key] = prev + ", " + file
+}
+
+// decodeRaw decodes the merged configuration layers into conf, with the yaml
+// tags of its fields: the keys of the JSON files are renamed by mergeRaw.
+func decodeRaw(merged map[string]interface{}, conf interface{}) error {
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, conf)
+}
@@ -0,0 +1,92 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigLayers(t *testing.T) {
+	project, home := loadTestConfig(t, map[string]string{
+		"~/" + GlobalFile: "enable_notification: false\nenable_reload: true\ncmd_args: [-global]\ndatabase:\n  driver: postgres\n  conn: postgres://global\n",
+		JSONFile:          `{"version": 0, "go_install": false, "cmd_args": ["-json"], "database": {"conn": "root@/json"}, "scripts": {"lint": "golint ./..."}}`,
+		YAMLFile:          "cmd_args: [-beefile]\ndatabase:\n  dir: db/migrations\nscripts:\n  test: go test ./...\n",
+		LocalFile:         "go_install: true\ndatabase:\n  conn: root@/local\nscripts:\n  test: go test -race ./...\n",
+	})
+	global := filepath.Join(home, filepath.FromSlash(GlobalFile))
+	json, yaml, local := filepath.Join(project, JSONFile), filepath.Join(project, YAMLFile), filepath.Join(project, LocalFile)
+
+	if expected := []string{global, json, yaml, local}; !reflect.DeepEqual(LoadedFiles(), expected) {
+		t.Errorf("expected the files %v, got %v", expected, LoadedFiles())
+	}
+
+	// Beefile.local > Beefile > bee.json > ~/.bee/config.yaml
+	if Conf.EnableNotification || !Conf.EnableReload {
+		t.Errorf("expected the settings of the global file, got %+v", Conf)
+	}
+	if !Conf.GoInstall {
+		t.Error("expected go_install of Beefile.local")
+	}
+	if expected := []string{"-beefile"}; !reflect.DeepEqual(Conf.CmdArgs, expected) {
+		t.Errorf("expected the lists replaced by the last file %v, got %v", expected, Conf.CmdArgs)
+	}
+	if expected := (Database{Driver: "postgres", Conn: "root@/local", Dir: "db/migrations"}); Conf.Database != expected {
+		t.Errorf("expected the objects merged by key %+v, got %+v", expected, Conf.Database)
+	}
+	if expected := map[string]string{"lint": "golint ./...", "test": "go test -race ./..."}; !reflect.DeepEqual(Conf.Scripts, expected) {
+		t.Errorf("expected the scripts %v, got %v", expected, Conf.Scripts)
+	}
+
+	expected := map[string]string{
+		"enable_notification": global,
+		"enable_reload":       global,
+		"version":             json,
+		"go_install":          local,
+		"cmd_args":            yaml,
+		"database":            global + ", " + json + ", " + yaml + ", " + local,
+		"scripts":             json + ", " + yaml + ", " + local,
+	}
+	if !reflect.DeepEqual(Sources(), expected) {
+		t.Errorf("expected the sources %v, got %v", expected, Sources())
+	}
+}
+
+func TestDecodeRaw(t *testing.T) {
+	type dir struct {
+		Path string `json:"json_path" yaml:"yaml_path"`
+	}
+	type value struct {
+		Name  string `json:"json_name" yaml:"yaml_name"`
+		Count int
+		Dirs  map[string]dir `json:"json_dirs" yaml:"yaml_dirs"`
+	}
+
+	// the JSON and YAML names of the fields are both accepted, case-insensitively
+	defer func() { sources = map[string]string{} }()
+	merged := map[string]interface{}{}
+	mergeRaw(merged, map[string]interface{}{"json_name": "json", "Count": float64(2), "json_dirs": map[string]interface{}{"a": map[string]interface{}{"json_path": "/a"}}}, reflect.TypeOf(value{}), JSONFile, "")
+	mergeRaw(merged, map[string]interface{}{"YAML_NAME": "yaml", "yaml_dirs": map[interface{}]interface{}{"b": map[interface{}]interface{}{"yaml_path": "/b"}}}, reflect.TypeOf(value{}), YAMLFile, "")
+
+	var v value
+	if err := decodeRaw(merged, &v); err != nil {
+		t.Fatal(err)
+	}
+	expected := value{Name: "yaml", Count: 2, Dirs: map[string]dir{"a": {Path: "/a"}, "b": {Path: "/b"}}}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %+v, got %+v", expected, v)
+	}
+}
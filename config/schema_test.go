@@ -0,0 +1,92 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type schemaNode struct {
+	Ref                  string                 `json:"$ref"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *schemaNode            `json:"items"`
+	Enum                 []string               `json:"enum"`
+	Maximum              *int                   `json:"maximum"`
+	Definitions          map[string]*schemaNode `json:"definitions"`
+}
+
+func TestSchema(t *testing.T) {
+	var root schemaNode
+	if err := json.Unmarshal(Schema, &root); err != nil {
+		t.Fatalf("invalid schema: %s", err)
+	}
+	resolve := func(n *schemaNode) *schemaNode {
+		if strings.HasPrefix(n.Ref, "#/definitions/") {
+			def, ok := root.Definitions[strings.TrimPrefix(n.Ref, "#/definitions/")]
+			if !ok {
+				t.Fatalf("unknown reference %s", n.Ref)
+			}
+			return def
+		}
+		return n
+	}
+
+	// the properties of the objects are the fields of the configuration
+	var check func(n *schemaNode, typ reflect.Type, field string)
+	check = func(n *schemaNode, typ reflect.Type, field string) {
+		n = resolve(n)
+		if typ.Kind() != reflect.Struct {
+			return
+		}
+		var fields, properties []string
+		for i := 0; i < typ.NumField(); i++ {
+			name := fieldName(typ.Field(i))
+			fields = append(fields, name)
+			p, ok := n.Properties[name]
+			if ok {
+				check(p, typ.Field(i).Type, joinField(field, name))
+			}
+		}
+		for name := range n.Properties {
+			if name != schemaKey {
+				properties = append(properties, name)
+			}
+		}
+		sort.Strings(fields)
+		sort.Strings(properties)
+		if !reflect.DeepEqual(fields, properties) {
+			t.Errorf("%s: expected the properties %v, got %v", field, fields, properties)
+		}
+		if string(n.AdditionalProperties) != "false" {
+			t.Errorf("%s: expected no additional properties", field)
+		}
+	}
+	check(&root, reflect.TypeOf(Conf), "")
+
+	if max := root.Properties["version"].Maximum; max == nil || *max != confVer {
+		t.Errorf("expected the maximum version %d", confVer)
+	}
+	if enum := root.Definitions["database"].Properties["driver"].Enum; !reflect.DeepEqual(enum, supportedDrivers) {
+		t.Errorf("expected the drivers %v, got %v", supportedDrivers, enum)
+	}
+	if enum := root.Properties["scaffold"].Properties["skip"].Items.Enum; !reflect.DeepEqual(enum, scaffoldSteps) {
+		t.Errorf("expected the scaffold steps %v, got %v", scaffoldSteps, enum)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// upgrades[i] upgrades a configuration file from version i to version i+1.
+// It returns true if it changed anything besides the version number.
+// 每个升级步骤将配置文件从版本 i 升级到 i+1。只有配置格式发生不兼容的变化时才增加
+// confVer 并加入对应的升级步骤，新增的配置项（如 "databases"）不需要升级。
+var upgrades = []func(raw map[string]interface{}) bool{}
+
+var (
+	jsonVersionPattern = regexp.MustCompile(`("version"\s*:\s*)\d+`)
+	yamlVersionPattern = regexp.MustCompile(`(?m)^(version\s*:\s*)\d+`)
+)
+
+// Upgrade upgrades the configuration file at path to the latest version.
+// The original file is kept next to it with a .bak suffix.
+// It returns the version of the file before and after the upgrade.
+func Upgrade(path string) (from, to int, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	raw := map[string]interface{}{}
+	if isYAML(path) {
+		err = yaml.Unmarshal(data, &raw)
+	} else {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	from, err = rawVersion(raw)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > confVer {
+		return from, from, fmt.Errorf("version %d is newer than the latest version supported by this bee (%d)", from, confVer)
+	}
+	if from == confVer {
+		return from, from, nil
+	}
+
+	changed := false
+	for v := from; v < confVer; v++ {
+		if upgrades[v](raw) {
+			changed = true
+		}
+	}
+	raw["version"] = confVer
+
+	var out []byte
+	if changed {
+		// 结构发生变化时重新序列化整个文件（YAML 中的注释会丢失）
+		if isYAML(path) {
+			out, err = yaml.Marshal(raw)
+		} else {
+			out, err = json.MarshalIndent(raw, "", "\t")
+		}
+		if err != nil {
+			return from, from, err
+		}
+	} else {
+		// 只修改版本号，保留原文件的格式和注释
+		out = setVersion(data, isYAML(path))
+	}
+
+	if err := ioutil.WriteFile(path+".bak", data, 0644); err != nil {
+		return from, from, fmt.Errorf("could not back up '%s': %s", path, err)
+	}
+	if err := ioutil.WriteFile(path, out, 0644); err != nil {
+		return from, from, err
+	}
+	return from, confVer, nil
+}
+
+// rawVersion returns the version of a decoded configuration file.
+// Files without version, such as the global or local ones, are up to date.
+func rawVersion(raw map[string]interface{}) (int, error) {
+	switch v := raw["version"].(type) {
+	case nil:
+		return confVer, nil
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("invalid version '%v'", v)
+	}
+}
+
+// setVersion sets the version number in the original file content
+func setVersion(data []byte, isYAML bool) []byte {
+	version := []byte("${1}" + strconv.Itoa(confVer))
+	pattern := jsonVersionPattern
+	if isYAML {
+		pattern = yamlVersionPattern
+	}
+	if pattern.Match(data) {
+		return pattern.ReplaceAll(data, version)
+	}
+	if isYAML {
+		return append([]byte(fmt.Sprintf("version: %d\n", confVer)), data...)
+	}
+	// 在 JSON 对象的第一个 { 之后插入版本号
+	if i := bytes.IndexByte(data, '{'); i >= 0 {
+		entry := fmt.Sprintf("\n\t\"version\": %d", confVer)
+		if !bytes.HasPrefix(bytes.TrimSpace(data[i+1:]), []byte("}")) {
+			entry += ","
+		}
+		return append(append(append([]byte{}, data[:i+1]...), entry...), data[i+1:]...)
+	}
+	return data
+}
@@ -0,0 +1,95 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpgradeSteps(t *testing.T) {
+	if len(upgrades) != confVer {
+		t.Fatalf("expected an upgrade step to each of the %d versions, got %d steps", confVer, len(upgrades))
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	testCases := []struct {
+		file     string
+		content  string
+		from, to int
+		err      string
+	}{
+		{file: JSONFile, content: "{\n\t\"version\": 0\n}\n", from: 0, to: 0},
+		{file: YAMLFile, content: "# comment\nversion: 0\n", from: 0, to: 0},
+		{file: LocalFile, content: "go_install: false\n", from: confVer, to: confVer},
+		{file: JSONFile, content: `{"version": 1}`, from: 1, to: 1, err: "version 1 is newer than the latest version supported by this bee (0)"},
+		{file: YAMLFile, content: "version: one\n", err: "invalid version 'one'"},
+		{file: JSONFile, content: `{"version": }`, err: "invalid character '}' looking for beginning of value"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.file+" "+tc.content, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			from, to, err := Upgrade(path)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if from != tc.from || to != tc.to {
+				t.Errorf("expected the upgrade from %d to %d, got from %d to %d", tc.from, tc.to, from, to)
+			}
+
+			// up to date files are neither rewritten nor backed up
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tc.content {
+				t.Errorf("expected the file unchanged, got:\n%s", data)
+			}
+			if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+				t.Errorf("expected no backup, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSetVersion(t *testing.T) {
+	testCases := []struct {
+		content  string
+		yaml     bool
+		expected string
+	}{
+		{content: "{\n\t\"version\": 3,\n\t\"go_install\": false\n}", expected: "{\n\t\"version\": 0,\n\t\"go_install\": false\n}"},
+		{content: "{\n\t\"go_install\": false\n}", expected: "{\n\t\"version\": 0,\n\t\"go_install\": false\n}"},
+		{content: "{}", expected: "{\n\t\"version\": 0}"},
+		{content: "# comment\nversion: 3 # format\ngo_install: false\n", yaml: true, expected: "# comment\nversion: 0 # format\ngo_install: false\n"},
+		{content: "go_install: false\n", yaml: true, expected: "version: 0\ngo_install: false\n"},
+	}
+
+	for _, tc := range testCases {
+		if got := string(setVersion([]byte(tc.content), tc.yaml)); got != tc.expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+package config
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Problem is an issue found in the configuration files
+type Problem struct {
+	File    string // 出问题的配置文件，为空表示与具体文件无关
+	Field   string // 出问题的字段，例如 database.driver
+	Message string
+	Warning bool // 警告不会导致 `bee config validate` 失败
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.File != "" {
+		b.WriteString(p.File)
+		b.WriteString(": ")
+	}
+	if p.Field != "" {
+		b.WriteString(p.Field)
+		b.WriteString(": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// scaffoldSteps lists the steps of `bee generate scaffold`
+var scaffoldSteps = []string{"model", "controller", "views", "migration", "migrate", "routes"}
+
+// supportedDrivers lists the database drivers understood by bee
+var supportedDrivers = []string{"mysql", "postgres", "sqlite3"}
+
+// schemaKey allows bee.json to reference its JSON schema
+const schemaKey = "$schema"
+
+// Validate checks the configuration loaded by LoadConfig and returns the problems found,
+// errors first. Callers should treat any problem which is not a warning as fatal.
+// 校验已加载的配置：解析错误、未知字段、版本、数据库驱动等
+func Validate() []Problem {
+	problems := append([]Problem{}, loadProblems...)
+
+	// Unknown keys, usually typos 未知字段通常是拼写错误
+	for _, path := range loadedFiles {
+		raw, err := parseRaw(path)
+		if err != nil {
+			continue
+		}
+		for _, key := range unknownKeys(raw, reflect.TypeOf(Conf), "") {
+			problems = append(problems, Problem{File: path, Field: key, Message: "unknown configuration key"})
+		}
+	}
+
+	if Conf.Version < confVer {
+		problems = append(problems, Problem{
+			File:    sources["version"],
+			Field:   "version",
+			Message: fmt.Sprintf("version %d is outdated, run `bee config migrate` to upgrade to version %d", Conf.Version, confVer),
+			Warning: true,
+		})
+	} else if Conf.Version > confVer {
+		problems = append(problems, Problem{
+			File:    sources["version"],
+			Field:   "version",
+			Message: fmt.Sprintf("version %d is not supported by this bee, the latest supported version is %d", Conf.Version, confVer),
+		})
+	}
+
+	for field, exts := range map[string][]string{"watch_ext": Conf.WatchExts, "watch_ext_static": Conf.WatchExtsStatic} {
+		for _, ext := range exts {
+			if !strings.HasPrefix(ext, ".") {
+				problems = append(problems, Problem{
+					File:    sources[field],
+					Field:   field,
+					Message: fmt.Sprintf("extension '%s' should start with a dot, e.g. '.%s'", ext, ext),
+					Warning: true,
+				})
+			}
+		}
+	}
+
+	problems = append(problems, validateDatabase("database", sources["database"], Conf.Database)...)
+	names := make([]string, 0, len(Conf.Databases))
+	for name := range Conf.Databases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := "databases." + name
+		if name == "all" {
+			problems = append(problems, Problem{File: sources["databases"], Field: field, Message: "'all' is reserved by `bee migrate -db=all` and cannot be used as a profile name"})
+		}
+		db := Conf.Databases[name]
+		if db.Driver == "" {
+			db.Driver = Conf.Database.Driver
+		}
+		problems = append(problems, validateDatabase(field, sources["databases"], db)...)
+		if db.Conn == "" {
+			problems = append(problems, Problem{File: sources["databases"], Field: field + ".conn", Message: "no connection string, the default one is used", Warning: true})
+		}
+	}
+
+	for name, script := range Conf.Scripts {
+		if strings.TrimSpace(script) == "" {
+			problems = append(problems, Problem{File: sources["scripts"], Field: "scripts." + name, Message: "empty script", Warning: true})
+		}
+	}
+
+	for _, step := range Conf.Scaffold.Skip {
+		known := false
+		for _, s := range scaffoldSteps {
+			known = known || s == step
+		}
+		if !known {
+			problems = append(problems, Problem{
+				File:    sources["scaffold"],
+				Field:   "scaffold.skip",
+				Message: fmt.Sprintf("unknown step '%s', expecting one of %s", step, strings.Join(scaffoldSteps, ", ")),
+			})
+		}
+	}
+
+	// 自定义的模型名和字段名必须是导出的 Go 标识符
+	tables := make([]string, 0, len(Conf.Appcode.Models))
+	for table := range Conf.Appcode.Models {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		if name := Conf.Appcode.Models[table]; !isExported(name) {
+			problems = append(problems, Problem{File: sources["appcode"], Field: "appcode.models." + table, Message: fmt.Sprintf("'%s' is not an exported Go identifier", name)})
+		}
+	}
+	tables = tables[:0]
+	for table := range Conf.Appcode.Fields {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		columns := make([]string, 0, len(Conf.Appcode.Fields[table]))
+		for column := range Conf.Appcode.Fields[table] {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		for _, column := range columns {
+			if name := Conf.Appcode.Fields[table][column]; !isExported(name) {
+				problems = append(problems, Problem{File: sources["appcode"], Field: "appcode.fields." + table + "." + column, Message: fmt.Sprintf("'%s' is not an exported Go identifier", name)})
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return !problems[i].Warning && problems[j].Warning })
+	return problems
+}
+
+// isExported reports whether name is an exported Go identifier
+func isExported(name string) bool {
+	return token.IsIdentifier(name) && token.IsExported(name)
+}
+
+func validateDatabase(field, file string, db Database) []Problem {
+	if db.Driver == "" {
+		return nil
+	}
+	for _, d := range supportedDrivers {
+		if db.Driver == d {
+			return nil
+		}
+	}
+	return []Problem{{
+		File:    file,
+		Field:   field + ".driver",
+		Message: fmt.Sprintf("unsupported driver '%s', expecting one of %s", db.Driver, strings.Join(supportedDrivers, ", ")),
+	}}
+}
+
+// unknownKeys returns the keys of raw which have no matching field in t
+func unknownKeys(raw interface{}, t reflect.Type, prefix string) []string {
+	var unknown []string
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := toStringMap(raw)
+		if !ok {
+			return nil
+		}
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			fields[strings.ToLower(fieldName(t.Field(i)))] = t.Field(i).Type
+			fields[strings.ToLower(yamlName(t.Field(i)))] = t.Field(i).Type
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if prefix == "" && key == schemaKey {
+				continue
+			}
+			ft, ok := fields[strings.ToLower(key)]
+			if !ok {
+				unknown = append(unknown, joinField(prefix, key))
+				continue
+			}
+			unknown = append(unknown, unknownKeys(m[key], ft, joinField(prefix, key))...)
+		}
+	case reflect.Map:
+		m, ok := toStringMap(raw)
+		if !ok {
+			return nil
+		}
+		for key, value := range m {
+			unknown = append(unknown, unknownKeys(value, t.Elem(), joinField(prefix, key))...)
+		}
+		sort.Strings(unknown)
+	}
+	return unknown
+}
+
+// toStringMap converts the maps decoded from JSON or YAML to map[string]interface{}
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[fmt.Sprint(k)] = v
+		}
+		return out, true
+	}
+	return nil, false
+}
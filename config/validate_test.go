@@ -0,0 +1,139 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadTestConfig writes the configuration files, by path relative to a new
+// project directory, and loads them from it. The home directory is a new
+// directory too, the files under "~/" are written in it.
+func loadTestConfig(t *testing.T, files map[string]string) (project, home string) {
+	t.Helper()
+	project, home = t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	for name, content := range files {
+		path := filepath.Join(project, filepath.FromSlash(name))
+		if rel := filepath.ToSlash(name); len(rel) > 2 && rel[:2] == "~/" {
+			path = filepath.Join(home, filepath.FromSlash(rel[2:]))
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	LoadConfig()
+	t.Cleanup(LoadConfig)
+	return project, home
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		expected []Problem // File is the base name of the file
+	}{
+		{
+			name: "valid",
+			files: map[string]string{
+				YAMLFile: "version: 0\nwatch_ext: [\".go\"]\ndatabase:\n  driver: postgres\ndatabases:\n  test:\n    conn: postgres://localhost/test\n",
+			},
+		},
+		{
+			name: "unknown keys",
+			files: map[string]string{
+				YAMLFile: "watch_exts: [\".go\"]\ndatabase:\n  driver: mysql\n  connection: root@/test\n",
+			},
+			expected: []Problem{
+				{File: YAMLFile, Field: "database.connection", Message: "unknown configuration key"},
+				{File: YAMLFile, Field: "watch_exts", Message: "unknown configuration key"},
+			},
+		},
+		{
+			name:  "newer version",
+			files: map[string]string{JSONFile: `{"version": 1}`},
+			expected: []Problem{
+				{File: JSONFile, Field: "version", Message: "version 1 is not supported by this bee, the latest supported version is 0"},
+			},
+		},
+		{
+			name: "databases",
+			files: map[string]string{
+				YAMLFile: "database:\n  driver: oracle\ndatabases:\n  all:\n    conn: root@/all\n  test:\n    driver: sqlite\n",
+			},
+			expected: []Problem{
+				{File: YAMLFile, Field: "database.driver", Message: "unsupported driver 'oracle', expecting one of mysql, postgres, sqlite3"},
+				{File: YAMLFile, Field: "databases.all", Message: "'all' is reserved by `bee migrate -db=all` and cannot be used as a profile name"},
+				{File: YAMLFile, Field: "databases.all.driver", Message: "unsupported driver 'oracle', expecting one of mysql, postgres, sqlite3"},
+				{File: YAMLFile, Field: "databases.test.driver", Message: "unsupported driver 'sqlite', expecting one of mysql, postgres, sqlite3"},
+				{File: YAMLFile, Field: "databases.test.conn", Message: "no connection string, the default one is used", Warning: true},
+			},
+		},
+		{
+			name: "watch_ext, scaffold and appcode",
+			files: map[string]string{
+				YAMLFile: "watch_ext: [go]\nscaffold:\n  skip: [tests]\nappcode:\n  models:\n    post: post\n  fields:\n    post:\n      user_id: Author\n      body: my-body\n",
+			},
+			expected: []Problem{
+				{File: YAMLFile, Field: "scaffold.skip", Message: "unknown step 'tests', expecting one of model, controller, views, migration, migrate, routes"},
+				{File: YAMLFile, Field: "appcode.models.post", Message: "'post' is not an exported Go identifier"},
+				{File: YAMLFile, Field: "appcode.fields.post.body", Message: "'my-body' is not an exported Go identifier"},
+				{File: YAMLFile, Field: "watch_ext", Message: "extension 'go' should start with a dot, e.g. '.go'", Warning: true},
+			},
+		},
+		{
+			name: "parse error and both files",
+			files: map[string]string{
+				JSONFile: `{"version": 0,}`,
+				YAMLFile: "version: 0\n",
+			},
+			expected: []Problem{
+				{File: JSONFile, Message: "invalid character '}' looking for beginning of object key string"},
+				{File: YAMLFile, Message: "both bee.json and Beefile exist, values in Beefile override the ones in bee.json", Warning: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loadTestConfig(t, tc.files)
+			var problems []Problem
+			for _, p := range Validate() {
+				if p.File != "" {
+					p.File = filepath.Base(p.File)
+				}
+				problems = append(problems, p)
+			}
+			if !reflect.DeepEqual(problems, tc.expected) {
+				t.Errorf("expected:\n%v\ngot:\n%v", tc.expected, problems)
+			}
+		})
+	}
+}
@@ -19,10 +19,27 @@ import "github.com/beego/bee/v2/utils"
 var SQLDriver utils.DocValue
 var SQLConn utils.DocValue
 var Level utils.DocValue
+var Arch utils.DocValue
 var Tables utils.DocValue
+var Schema utils.DocValue
 var Fields utils.DocValue
 var DDL utils.DocValue
 
+// bee generate docs
+var DocsSpec utils.DocValue
+var DocsCheck bool
+var DocsDiff utils.DocValue
+
+// bee generate client
+var ClientLang utils.DocValue
+var ClientOutput utils.DocValue
+
+// bee generate scaffold
+var ScaffoldYes bool
+var ScaffoldSkip utils.DocValue
+
+// bee generate templates
+var TemplatesForce bool
 
 // bee generate routers
 var ControllerDirectory utils.DocValue
@@ -25,6 +25,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	beeLogger "github.com/beego/bee/v2/logger"
 	"github.com/beego/bee/v2/logger/colors"
 	"github.com/beego/bee/v2/utils"
@@ -38,6 +39,12 @@ const (
 	ORouter
 )
 
+// The architectures of the generated code
+const (
+	ArchFlat    = "flat"    // the models query the database, the controllers call them
+	ArchLayered = "layered" // repositories, services and controllers built with their dependencies
+)
+
 // DbTransformer has method to reverse engineer a database schema to restful api code
 type DbTransformer interface {
 	GetTableNames(conn *sql.DB) []string
@@ -52,6 +59,7 @@ type MysqlDB struct {
 
 // PostgresDB is the PostgreSQL version of DbTransformer
 type PostgresDB struct {
+	Schema string // the schema of the tables, all the schemas except the system ones when empty
 }
 
 // dbDriver maps a DBMS name to its version of DbTransformer
@@ -64,6 +72,8 @@ type MvcPath struct {
 	ModelPath      string
 	ControllerPath string
 	RouterPath     string
+	RepositoryPath string // layered architecture only
+	ServicePath    string // layered architecture only
 }
 
 // typeMapping maps SQL data type to corresponding Go data type
@@ -143,18 +153,23 @@ var typeMappingPostgres = map[string]string{
 // Table represent a table in a database
 type Table struct {
 	Name          string
+	Model         string            // the name of the model, see AppcodeOptions
+	Resource      string            // the name of the files and of the routes, the table name without its prefix
+	Fields        map[string]string // the custom names of the fields, by column
 	Pk            string
 	Uk            []string
 	Fk            map[string]*ForeignKey
 	Columns       []*Column
+	Relations     []*Column // reverse and many-to-many relation fields, see resolveRelations
 	ImportTimePkg bool
 }
 
 // Column reprsents a column for a table
 type Column struct {
-	Name string
-	Type string
-	Tag  *OrmTag
+	Name  string
+	Type  string
+	Tag   *OrmTag
+	plain *Column // the foreign key as a plain column, when the referenced table is not generated
 }
 
 // ForeignKey represents a foreign key column for a table
@@ -185,19 +200,35 @@ type OrmTag struct {
 	RelFk       bool
 	ReverseMany bool
 	RelM2M      bool
+	RelThrough  string // through model of a many-to-many relation
+	RelTable    string // join table of a many-to-many relation
 	Comment     string //column comment
 }
 
 // String returns the source code string for the Table struct
 func (tb *Table) String() string {
-	rv := fmt.Sprintf("type %s struct {\n", utils.CamelCase(tb.Name))
+	rv := fmt.Sprintf("type %s struct {\n", tb.Model)
 	for _, v := range tb.Columns {
 		rv += v.String() + "\n"
 	}
+	for _, v := range tb.Relations {
+		rv += v.String() + "\n"
+	}
 	rv += "}\n"
 	return rv
 }
 
+// listFields returns the fields of the model of the table in the GetAll requests:
+// the columns, the relation fields of the foreign keys are not sorted
+func (tb *Table) listFields() []listField {
+	var l []listField
+	for _, col := range tb.Columns {
+		rel := col.Tag.RelFk || col.Tag.RelOne
+		l = append(l, listField{Name: utils.SnakeString(col.Name), Field: col.Name, Filter: true, Sort: !rel})
+	}
+	return l
+}
+
 // String returns the source code string of a field in Table struct
 // It maps to a column in database table. e.g. Id int `orm:"column(id);auto"`
 func (col *Column) String() string {
@@ -246,6 +277,12 @@ func (tag *OrmTag) String() string {
 	if tag.RelM2M {
 		ormOptions = append(ormOptions, "rel(m2m)")
 	}
+	if tag.RelThrough != "" {
+		ormOptions = append(ormOptions, fmt.Sprintf("rel_through(%s)", tag.RelThrough))
+	}
+	if tag.RelTable != "" {
+		ormOptions = append(ormOptions, fmt.Sprintf("rel_table(%s)", tag.RelTable))
+	}
 	if tag.Pk {
 		ormOptions = append(ormOptions, "pk")
 	}
@@ -265,7 +302,7 @@ func (tag *OrmTag) String() string {
 	return fmt.Sprintf("`orm:\"%s\"`", strings.Join(ormOptions, ";"))
 }
 
-func GenerateAppcode(driver, connStr, level, tables, currpath string) {
+func GenerateAppcode(driver, connStr, level, arch, tables, currpath string, opts AppcodeOptions) {
 	var mode byte
 	switch level {
 	case "1":
@@ -277,6 +314,19 @@ func GenerateAppcode(driver, connStr, level, tables, currpath string) {
 	default:
 		beeLogger.Log.Fatal("Invalid level value. Must be either \"1\", \"2\", or \"3\"")
 	}
+	if arch == "" {
+		arch = ArchFlat
+	}
+	if arch != ArchFlat && arch != ArchLayered {
+		beeLogger.Log.Fatalf("Invalid arch value. Must be either \"%s\" or \"%s\"", ArchFlat, ArchLayered)
+	}
+	checkAppcodeSource(driver, opts)
+	gen(driver, connStr, mode, arch, selectTables(tables), currpath, opts)
+}
+
+// selectTables returns the set of the table names separated by a comma,
+// nil when there are none, i.e. all the tables
+func selectTables(tables string) map[string]bool {
 	var selectedTables map[string]bool
 	if tables != "" {
 		selectedTables = make(map[string]bool)
@@ -284,6 +334,11 @@ func GenerateAppcode(driver, connStr, level, tables, currpath string) {
 			selectedTables[v] = true
 		}
 	}
+	return selectedTables
+}
+
+// checkAppcodeSource checks that the tables can be read from the driver
+func checkAppcodeSource(driver string, opts AppcodeOptions) {
 	switch driver {
 	case "mysql":
 	case "postgres":
@@ -292,38 +347,54 @@ func GenerateAppcode(driver, connStr, level, tables, currpath string) {
 	default:
 		beeLogger.Log.Fatal("Unknown database driver. Must be either \"mysql\", \"postgres\" or \"sqlite\"")
 	}
-	gen(driver, connStr, mode, selectedTables, currpath)
+	if opts.Schema != "" && driver != "postgres" {
+		beeLogger.Log.Fatal("The schema option is only supported by PostgreSQL")
+	}
 }
 
 // Generate takes table, column and foreign key information from database connection
 // and generate corresponding golang source files
-func gen(dbms, connStr string, mode byte, selectedTableNames map[string]bool, apppath string) {
+func gen(dbms, connStr string, mode byte, arch string, selectedTableNames map[string]bool, apppath string, opts AppcodeOptions) {
+	tables := loadTables(dbms, connStr, selectedTableNames, opts)
+	mvcPath := new(MvcPath)
+	mvcPath.ModelPath = path.Join(apppath, "models")
+	mvcPath.ControllerPath = path.Join(apppath, "controllers")
+	mvcPath.RouterPath = path.Join(apppath, "routers")
+	if arch == ArchLayered {
+		mvcPath.RepositoryPath = path.Join(apppath, "repositories")
+		mvcPath.ServicePath = path.Join(apppath, "services")
+	}
+	createPaths(mode, mvcPath)
+	pkgPath := getPackagePath(apppath)
+	resolveRelations(tables, pkgPath)
+	writeSourceFiles(apppath, pkgPath, tables, mode, mvcPath)
+}
+
+// loadTables reads the selected tables, all of them when none is selected,
+// their columns and their constraints from the database
+func loadTables(dbms, connStr string, selectedTableNames map[string]bool, opts AppcodeOptions) []*Table {
 	db, err := sql.Open(dbms, connStr)
 	if err != nil {
 		beeLogger.Log.Fatalf("Could not connect to '%s' database using '%s': %s", dbms, connStr, err)
 	}
 	defer db.Close()
-	if trans, ok := dbDriver[dbms]; ok {
-		beeLogger.Log.Info("Analyzing database tables...")
-		var tableNames []string
-		if len(selectedTableNames) != 0 {
-			for tableName := range selectedTableNames {
-				tableNames = append(tableNames, tableName)
-			}
-		} else {
-			tableNames = trans.GetTableNames(db)
+	trans, ok := dbDriver[dbms]
+	if !ok {
+		beeLogger.Log.Fatalf("Generating app code from '%s' database is not supported yet.", dbms)
+	}
+	if dbms == "postgres" && opts.Schema != "" {
+		trans = &PostgresDB{Schema: opts.Schema}
+	}
+	beeLogger.Log.Info("Analyzing database tables...")
+	var tableNames []string
+	if len(selectedTableNames) != 0 {
+		for tableName := range selectedTableNames {
+			tableNames = append(tableNames, tableName)
 		}
-		tables := getTableObjects(tableNames, db, trans)
-		mvcPath := new(MvcPath)
-		mvcPath.ModelPath = path.Join(apppath, "models")
-		mvcPath.ControllerPath = path.Join(apppath, "controllers")
-		mvcPath.RouterPath = path.Join(apppath, "routers")
-		createPaths(mode, mvcPath)
-		pkgPath := getPackagePath(apppath)
-		writeSourceFiles(pkgPath, tables, mode, mvcPath)
 	} else {
-		beeLogger.Log.Fatalf("Generating app code from '%s' database is not supported yet.", dbms)
+		tableNames = trans.GetTableNames(db)
 	}
+	return getTableObjects(tableNames, db, trans, opts)
 }
 
 // GetTableNames returns a slice of table names in the current database
@@ -343,8 +414,8 @@ func (*MysqlDB) GetTableNames(db *sql.DB) (tables []string) {
 	return
 }
 
-// getTableObjects process each table name
-func getTableObjects(tableNames []string, db *sql.DB, dbTransformer DbTransformer) (tables []*Table) {
+// getTableObjects process each table name, naming the tables with the rules of opts
+func getTableObjects(tableNames []string, db *sql.DB, dbTransformer DbTransformer, opts AppcodeOptions) (tables []*Table) {
 	// if a table has a composite pk or doesn't have pk, we can't use it yet
 	// these tables will be put into blacklist so that other struct will not
 	// reference it.
@@ -355,6 +426,7 @@ func getTableObjects(tableNames []string, db *sql.DB, dbTransformer DbTransforme
 		tb := new(Table)
 		tb.Name = tableName
 		tb.Fk = make(map[string]*ForeignKey)
+		opts.name(tb)
 		dbTransformer.GetConstraints(db, tb, blackList)
 		tables = append(tables, tb)
 	}
@@ -439,7 +511,7 @@ func (mysqlDB *MysqlDB) GetColumns(db *sql.DB, table *Table, blackList map[strin
 
 		// create a column
 		col := new(Column)
-		col.Name = utils.CamelCase(colName)
+		col.Name = table.fieldName(colName)
 		col.Type, err = mysqlDB.GetGoDataType(dataType)
 		if err != nil {
 			beeLogger.Log.Fatalf("%s", err)
@@ -458,57 +530,58 @@ func (mysqlDB *MysqlDB) GetColumns(db *sql.DB, table *Table, blackList map[strin
 				tag.Pk = true
 			}
 		} else {
+			// if the name of the field is Id, and it's not primary key
+			if col.Name == "Id" {
+				col.Name = "Id_RENAME"
+			}
+			if isNullable == "YES" {
+				tag.Null = true
+			}
+			if isSQLSignedIntType(dataType) {
+				sign := extractIntSignness(columnType)
+				if sign == "unsigned" && extra != "auto_increment" {
+					col.Type, err = mysqlDB.GetGoDataType(dataType + " " + sign)
+					if err != nil {
+						beeLogger.Log.Fatalf("%s", err)
+					}
+				}
+			}
+			if isSQLStringType(dataType) {
+				tag.Size = extractColSize(columnType)
+			}
+			if isSQLTemporalType(dataType) {
+				tag.Type = dataType
+				//check auto_now, auto_now_add
+				if columnDefault == "CURRENT_TIMESTAMP" && extra == "on update CURRENT_TIMESTAMP" {
+					tag.AutoNow = true
+				} else if columnDefault == "CURRENT_TIMESTAMP" {
+					tag.AutoNowAdd = true
+				}
+				// need to import time package
+				table.ImportTimePkg = true
+			}
+			if isSQLDecimal(dataType) {
+				tag.Digits, tag.Decimals = extractDecimal(columnType)
+			}
+			if isSQLBinaryType(dataType) {
+				tag.Size = extractColSize(columnType)
+			}
+			if isSQLBitType(dataType) {
+				tag.Size = extractColSize(columnType)
+			}
+
+			// check if the current column is a foreign key, the plain column is
+			// kept for the tables which are not generated, see resolveRelations
 			fkCol, isFk := table.Fk[colName]
 			isBl := false
 			if isFk {
 				_, isBl = blackList[fkCol.RefTable]
 			}
-			// check if the current column is a foreign key
 			if isFk && !isBl {
-				tag.RelFk = true
-				refStructName := fkCol.RefTable
-				col.Name = utils.CamelCase(colName)
-				col.Type = "*" + utils.CamelCase(refStructName)
-			} else {
-				// if the name of column is Id, and it's not primary key
-				if colName == "id" {
-					col.Name = "Id_RENAME"
-				}
-				if isNullable == "YES" {
-					tag.Null = true
-				}
-				if isSQLSignedIntType(dataType) {
-					sign := extractIntSignness(columnType)
-					if sign == "unsigned" && extra != "auto_increment" {
-						col.Type, err = mysqlDB.GetGoDataType(dataType + " " + sign)
-						if err != nil {
-							beeLogger.Log.Fatalf("%s", err)
-						}
-					}
-				}
-				if isSQLStringType(dataType) {
-					tag.Size = extractColSize(columnType)
-				}
-				if isSQLTemporalType(dataType) {
-					tag.Type = dataType
-					//check auto_now, auto_now_add
-					if columnDefault == "CURRENT_TIMESTAMP" && extra == "on update CURRENT_TIMESTAMP" {
-						tag.AutoNow = true
-					} else if columnDefault == "CURRENT_TIMESTAMP" {
-						tag.AutoNowAdd = true
-					}
-					// need to import time package
-					table.ImportTimePkg = true
-				}
-				if isSQLDecimal(dataType) {
-					tag.Digits, tag.Decimals = extractDecimal(columnType)
-				}
-				if isSQLBinaryType(dataType) {
-					tag.Size = extractColSize(columnType)
-				}
-				if isSQLBitType(dataType) {
-					tag.Size = extractColSize(columnType)
-				}
+				col.plain = &Column{Name: col.Name, Type: col.Type, Tag: tag}
+				tag = &OrmTag{Column: colName, Comment: columnComment, RelFk: true}
+				col.Name = table.fieldName(colName)
+				col.Type = "*" + utils.CamelCase(fkCol.RefTable) // the model of the table, see resolveRelations
 			}
 		}
 		col.Tag = tag
@@ -525,12 +598,12 @@ func (*MysqlDB) GetGoDataType(sqlType string) (string, error) {
 }
 
 // GetTableNames for PostgreSQL
-func (*PostgresDB) GetTableNames(db *sql.DB) (tables []string) {
+func (postgresDB *PostgresDB) GetTableNames(db *sql.DB) (tables []string) {
 	rows, err := db.Query(`
 		SELECT table_name FROM information_schema.tables
 		WHERE table_catalog = current_database() AND
 		table_type = 'BASE TABLE' AND
-		table_schema NOT IN ('pg_catalog', 'information_schema')`)
+		`+postgresSchema("table_schema", 1), postgresDB.Schema)
 	if err != nil {
 		beeLogger.Log.Fatalf("Could not show tables: %s", err)
 	}
@@ -547,7 +620,7 @@ func (*PostgresDB) GetTableNames(db *sql.DB) (tables []string) {
 }
 
 // GetConstraints for PostgreSQL
-func (*PostgresDB) GetConstraints(db *sql.DB, table *Table, blackList map[string]bool) {
+func (postgresDB *PostgresDB) GetConstraints(db *sql.DB, table *Table, blackList map[string]bool) {
 	rows, err := db.Query(
 		`SELECT
 			c.constraint_type,
@@ -563,11 +636,11 @@ func (*PostgresDB) GetConstraints(db *sql.DB, table *Table, blackList map[string
 		INNER JOIN
 			information_schema.constraint_column_usage cu ON cu.constraint_name =  c.constraint_name
 		WHERE
-			c.table_catalog = current_database() AND c.table_schema NOT IN ('pg_catalog', 'information_schema')
+			c.table_catalog = current_database() AND `+postgresSchema("c.table_schema", 3)+`
 			 AND c.table_name = $1
-			AND u.table_catalog = current_database() AND u.table_schema NOT IN ('pg_catalog', 'information_schema')
+			AND u.table_catalog = current_database() AND `+postgresSchema("u.table_schema", 3)+`
 			 AND u.table_name = $2`,
-		table.Name, table.Name) //  u.position_in_unique_constraint,
+		table.Name, table.Name, postgresDB.Schema) //  u.position_in_unique_constraint,
 	if err != nil {
 		beeLogger.Log.Fatalf("Could not query INFORMATION_SCHEMA for PK/UK/FK information: %s", err)
 	}
@@ -621,9 +694,9 @@ func (postgresDB *PostgresDB) GetColumns(db *sql.DB, table *Table, blackList map
 		FROM
 			information_schema.columns
 		WHERE
-			table_catalog = current_database() AND table_schema NOT IN ('pg_catalog', 'information_schema')
+			table_catalog = current_database() AND `+postgresSchema("table_schema", 2)+`
 			 AND table_name = $1`,
-		table.Name)
+		table.Name, postgresDB.Schema)
 	if err != nil {
 		beeLogger.Log.Fatalf("Could not query INFORMATION_SCHEMA for column information: %s", err)
 	}
@@ -639,7 +712,7 @@ func (postgresDB *PostgresDB) GetColumns(db *sql.DB, table *Table, blackList map
 			string(colNameBytes), string(dataTypeBytes), string(columnTypeBytes), string(isNullableBytes), string(columnDefaultBytes), string(extraBytes)
 		// Create a column
 		col := new(Column)
-		col.Name = utils.CamelCase(colName)
+		col.Name = table.fieldName(colName)
 		col.Type, err = postgresDB.GetGoDataType(dataType)
 		if err != nil {
 			beeLogger.Log.Fatalf("%s", err)
@@ -657,48 +730,49 @@ func (postgresDB *PostgresDB) GetColumns(db *sql.DB, table *Table, blackList map
 				tag.Pk = true
 			}
 		} else {
+			// if the name of the field is Id, and it's not primary key
+			if col.Name == "Id" {
+				col.Name = "Id_RENAME"
+			}
+			if isNullable == "YES" {
+				tag.Null = true
+			}
+			if isSQLStringType(dataType) {
+				tag.Size = extractColSize(columnType)
+			}
+			if isSQLTemporalType(dataType) || strings.HasPrefix(dataType, "timestamp") {
+				tag.Type = dataType
+				//check auto_now, auto_now_add
+				if columnDefault == "CURRENT_TIMESTAMP" && extra == "on update CURRENT_TIMESTAMP" {
+					tag.AutoNow = true
+				} else if columnDefault == "CURRENT_TIMESTAMP" {
+					tag.AutoNowAdd = true
+				}
+				// need to import time package
+				table.ImportTimePkg = true
+			}
+			if isSQLDecimal(dataType) {
+				tag.Digits, tag.Decimals = extractDecimal(columnType)
+			}
+			if isSQLBinaryType(dataType) {
+				tag.Size = extractColSize(columnType)
+			}
+			if isSQLStrangeType(dataType) {
+				tag.Type = dataType
+			}
+
+			// check if the current column is a foreign key, the plain column is
+			// kept for the tables which are not generated, see resolveRelations
 			fkCol, isFk := table.Fk[colName]
 			isBl := false
 			if isFk {
 				_, isBl = blackList[fkCol.RefTable]
 			}
-			// check if the current column is a foreign key
 			if isFk && !isBl {
-				tag.RelFk = true
-				refStructName := fkCol.RefTable
-				col.Name = utils.CamelCase(colName)
-				col.Type = "*" + utils.CamelCase(refStructName)
-			} else {
-				// if the name of column is Id, and it's not primary key
-				if colName == "id" {
-					col.Name = "Id_RENAME"
-				}
-				if isNullable == "YES" {
-					tag.Null = true
-				}
-				if isSQLStringType(dataType) {
-					tag.Size = extractColSize(columnType)
-				}
-				if isSQLTemporalType(dataType) || strings.HasPrefix(dataType, "timestamp") {
-					tag.Type = dataType
-					//check auto_now, auto_now_add
-					if columnDefault == "CURRENT_TIMESTAMP" && extra == "on update CURRENT_TIMESTAMP" {
-						tag.AutoNow = true
-					} else if columnDefault == "CURRENT_TIMESTAMP" {
-						tag.AutoNowAdd = true
-					}
-					// need to import time package
-					table.ImportTimePkg = true
-				}
-				if isSQLDecimal(dataType) {
-					tag.Digits, tag.Decimals = extractDecimal(columnType)
-				}
-				if isSQLBinaryType(dataType) {
-					tag.Size = extractColSize(columnType)
-				}
-				if isSQLStrangeType(dataType) {
-					tag.Type = dataType
-				}
+				col.plain = &Column{Name: col.Name, Type: col.Type, Tag: tag}
+				tag = &OrmTag{Column: colName, RelFk: true}
+				col.Name = table.fieldName(colName)
+				col.Type = "*" + utils.CamelCase(fkCol.RefTable) // the model of the table, see resolveRelations
 			}
 		}
 		col.Tag = tag
@@ -714,180 +788,212 @@ func (*PostgresDB) GetGoDataType(sqlType string) (string, error) {
 	return "", fmt.Errorf("data type '%s' not found", sqlType)
 }
 
+// postgresSchema returns the condition selecting the schema of the tables from
+// the column, the schema being the query parameter param: all the schemas
+// except the system ones when the parameter is empty
+func postgresSchema(column string, param int) string {
+	return fmt.Sprintf("(($%[2]d::text = '' AND %[1]s NOT IN ('pg_catalog', 'information_schema')) OR %[1]s = $%[2]d::text)", column, param)
+}
+
 // deleteAndRecreatePaths removes several directories completely
 func createPaths(mode byte, paths *MvcPath) {
 	if (mode & OModel) == OModel {
-		os.Mkdir(paths.ModelPath, 0777)
+		generated.MkdirAll(paths.ModelPath)
+		if paths.RepositoryPath != "" {
+			generated.MkdirAll(paths.RepositoryPath)
+			generated.MkdirAll(paths.ServicePath)
+		}
 	}
 	if (mode & OController) == OController {
-		os.Mkdir(paths.ControllerPath, 0777)
+		generated.MkdirAll(paths.ControllerPath)
 	}
 	if (mode & ORouter) == ORouter {
-		os.Mkdir(paths.RouterPath, 0777)
+		generated.MkdirAll(paths.RouterPath)
 	}
 }
 
 // writeSourceFiles generates source files for model/controller/router
 // It will wipe the following directories and recreate them:./models, ./controllers, ./routers
 // Newly geneated files will be inside these folders.
-func writeSourceFiles(pkgPath string, tables []*Table, mode byte, paths *MvcPath) {
+// In the layered architecture the models come with their repositories and services.
+func writeSourceFiles(apppath, pkgPath string, tables []*Table, mode byte, paths *MvcPath) {
+	templates := appcodeTemplates{
+		Model:      "appcode/model.go.tmpl",
+		Controller: "appcode/controller.go.tmpl",
+		Router:     "appcode/router.go.tmpl",
+	}
+	layered := paths.RepositoryPath != ""
+	if layered {
+		templates = appcodeTemplates{
+			Model:      "appcode/layered/model.go.tmpl",
+			Controller: "appcode/layered/controller.go.tmpl",
+			Router:     "appcode/layered/router.go.tmpl",
+		}
+	}
 	if (OModel & mode) == OModel {
 		beeLogger.Log.Info("Creating model files...")
-		writeModelFiles(tables, paths.ModelPath)
+		writeModelFiles(apppath, tables, paths.ModelPath, templates.Model)
+		if !layered {
+			// the list queries of the GetAll functions
+			fileStr := renderTemplate(apppath, "query.go.tmpl", modelData{PackageName: "models"})
+			writeAppcodeFile(path.Join(paths.ModelPath, "query.go"), fileStr, "model")
+		}
+		if layered {
+			beeLogger.Log.Info("Creating repository and service files...")
+			writeRepositoryFiles(apppath, tables, paths.RepositoryPath, pkgPath)
+			writeServiceFiles(apppath, tables, paths.ServicePath, pkgPath)
+		}
 	}
 	if (OController & mode) == OController {
 		beeLogger.Log.Info("Creating controller files...")
-		writeControllerFiles(tables, paths.ControllerPath, pkgPath)
+		writeControllerFiles(apppath, tables, paths.ControllerPath, pkgPath, templates.Controller)
 	}
 	if (ORouter & mode) == ORouter {
 		beeLogger.Log.Info("Creating router files...")
-		writeRouterFile(tables, paths.RouterPath, pkgPath)
+		writeRouterFile(apppath, tables, paths.RouterPath, pkgPath, templates.Router)
 	}
 }
 
-// writeModelFiles generates model files
-func writeModelFiles(tables []*Table, mPath string) {
+// appcodeTemplates are the templates of an architecture
+type appcodeTemplates struct {
+	Model      string // the model of a table with a primary key
+	Controller string
+	Router     string
+}
+
+// writeAppcodeFile writes a generated file, asking before overwriting it, and
+// formats the Go files. It returns false when the file is skipped.
+func writeAppcodeFile(fpath, content, kind string) bool {
+	if generated.Exists(fpath) {
+		beeLogger.Log.Warnf("'%s' already exists. Do you want to overwrite it? [Yes|No] ", fpath)
+		if !utils.AskForConfirmation() {
+			beeLogger.Log.Warnf("Skipped create file '%s'", fpath)
+			return false
+		}
+	}
+	if err := generated.WriteFile(fpath, []byte(content)); err != nil {
+		beeLogger.Log.Fatalf("Could not write %s file to '%s': %s", kind, fpath, err)
+	}
 	w := colors.NewColorWriter(os.Stdout)
+	fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
+	if strings.HasSuffix(fpath, ".go") {
+		utils.FormatSourceCode(fpath)
+	}
+	return true
+}
+
+// appControllerData is the data of the appcode controller template
+type appControllerData struct {
+	ControllerName string
+	TableName      string
+	PkgPath        string
+}
 
+// layerData is the data of the repository and service templates of the layered architecture
+type layerData struct {
+	ModelName  string
+	PkgPath    string
+	ListFields string // repositories only
+}
+
+// routerData is the data of the appcode router template
+type routerData struct {
+	PkgPath string
+	Tables  []routerTable
+}
+
+type routerTable struct {
+	NameSpace      string
+	ControllerName string
+}
+
+// writeModelFiles generates model files
+func writeModelFiles(apppath string, tables []*Table, mPath string, pkTpl string) {
 	for _, tb := range tables {
-		filename := getFileName(tb.Name)
+		filename := getFileName(tb.Resource)
 		fpath := path.Join(mPath, filename+".go")
-		var f *os.File
-		var err error
-		if utils.IsExist(fpath) {
-			beeLogger.Log.Warnf("'%s' already exists. Do you want to overwrite it? [Yes|No] ", fpath)
-			if utils.AskForConfirmation() {
-				f, err = os.OpenFile(fpath, os.O_RDWR|os.O_TRUNC, 0666)
-				if err != nil {
-					beeLogger.Log.Warnf("%s", err)
-					continue
-				}
-			} else {
-				beeLogger.Log.Warnf("Skipped create file '%s'", fpath)
-				continue
-			}
-		} else {
-			f, err = os.OpenFile(fpath, os.O_CREATE|os.O_RDWR, 0666)
-			if err != nil {
-				beeLogger.Log.Warnf("%s", err)
-				continue
-			}
-		}
-		var template string
+		tpl := pkTpl
 		if tb.Pk == "" {
-			template = StructModelTPL
-		} else {
-			template = ModelTPL
-		}
-		fileStr := strings.Replace(template, "{{modelStruct}}", tb.String(), 1)
-		fileStr = strings.Replace(fileStr, "{{modelName}}", utils.CamelCase(tb.Name), -1)
-		fileStr = strings.Replace(fileStr, "{{tableName}}", tb.Name, -1)
-
-		// If table contains time field, import time.Time package
-		timePkg := ""
-		importTimePkg := ""
-		if tb.ImportTimePkg {
-			timePkg = "\"time\"\n"
-			importTimePkg = "import \"time\"\n"
+			tpl = "appcode/struct_model.go.tmpl"
 		}
-		fileStr = strings.Replace(fileStr, "{{timePkg}}", timePkg, -1)
-		fileStr = strings.Replace(fileStr, "{{importTimePkg}}", importTimePkg, -1)
-		if _, err := f.WriteString(fileStr); err != nil {
-			beeLogger.Log.Fatalf("Could not write model file to '%s': %s", fpath, err)
-		}
-		utils.CloseFile(f)
-		fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
-		utils.FormatSourceCode(fpath)
+		fileStr := renderTemplate(apppath, tpl, modelData{
+			PackageName: "models",
+			ModelName:   tb.Model,
+			TableName:   tb.Name,
+			ModelStruct: tb.String(),
+			// If table contains time field, import time.Time package
+			HasTime:    tb.ImportTimePkg,
+			Relations:  tb.relationsCode(),
+			ListFields: listFieldsCode(tb.listFields(), "Id"),
+		})
+		writeAppcodeFile(fpath, fileStr, "model")
 	}
 }
 
 // writeControllerFiles generates controller files
-func writeControllerFiles(tables []*Table, cPath string, pkgPath string) {
-	w := colors.NewColorWriter(os.Stdout)
-
+func writeControllerFiles(apppath string, tables []*Table, cPath string, pkgPath string, tpl string) {
 	for _, tb := range tables {
 		if tb.Pk == "" {
 			continue
 		}
-		filename := getFileName(tb.Name)
+		filename := getFileName(tb.Resource)
 		fpath := path.Join(cPath, filename+".go")
-		var f *os.File
-		var err error
-		if utils.IsExist(fpath) {
-			beeLogger.Log.Warnf("'%s' already exists. Do you want to overwrite it? [Yes|No] ", fpath)
-			if utils.AskForConfirmation() {
-				f, err = os.OpenFile(fpath, os.O_RDWR|os.O_TRUNC, 0666)
-				if err != nil {
-					beeLogger.Log.Warnf("%s", err)
-					continue
-				}
-			} else {
-				beeLogger.Log.Warnf("Skipped create file '%s'", fpath)
-				continue
-			}
-		} else {
-			f, err = os.OpenFile(fpath, os.O_CREATE|os.O_RDWR, 0666)
-			if err != nil {
-				beeLogger.Log.Warnf("%s", err)
-				continue
-			}
+		fileStr := renderTemplate(apppath, tpl, appControllerData{
+			ControllerName: tb.Model,
+			TableName:      tb.Name,
+			PkgPath:        pkgPath,
+		})
+		writeAppcodeFile(fpath, fileStr, "controller")
+	}
+}
+
+// writeRepositoryFiles generates the repository files, and the query shared by them
+func writeRepositoryFiles(apppath string, tables []*Table, rPath string, pkgPath string) {
+	writeAppcodeFile(path.Join(rPath, "common.go"), renderTemplate(apppath, "appcode/layered/common.go.tmpl", nil), "repository")
+	writeAppcodeFile(path.Join(rPath, "query.go"), renderTemplate(apppath, "query.go.tmpl", modelData{PackageName: "repositories"}), "repository")
+	for _, tb := range tables {
+		if tb.Pk == "" {
+			continue
 		}
-		fileStr := strings.Replace(CtrlTPL, "{{ctrlName}}", utils.CamelCase(tb.Name), -1)
-		fileStr = strings.Replace(fileStr, "{{pkgPath}}", pkgPath, -1)
-		if _, err := f.WriteString(fileStr); err != nil {
-			beeLogger.Log.Fatalf("Could not write controller file to '%s': %s", fpath, err)
+		fpath := path.Join(rPath, getFileName(tb.Resource)+".go")
+		fileStr := renderTemplate(apppath, "appcode/layered/repository.go.tmpl", layerData{
+			ModelName:  tb.Model,
+			PkgPath:    pkgPath,
+			ListFields: listFieldsCode(tb.listFields(), "Id"),
+		})
+		writeAppcodeFile(fpath, fileStr, "repository")
+	}
+}
+
+// writeServiceFiles generates the service files
+func writeServiceFiles(apppath string, tables []*Table, sPath string, pkgPath string) {
+	for _, tb := range tables {
+		if tb.Pk == "" {
+			continue
 		}
-		utils.CloseFile(f)
-		fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
-		utils.FormatSourceCode(fpath)
+		fpath := path.Join(sPath, getFileName(tb.Resource)+".go")
+		fileStr := renderTemplate(apppath, "appcode/layered/service.go.tmpl", layerData{
+			ModelName: tb.Model,
+			PkgPath:   pkgPath,
+		})
+		writeAppcodeFile(fpath, fileStr, "service")
 	}
 }
 
 // writeRouterFile generates router file
-func writeRouterFile(tables []*Table, rPath string, pkgPath string) {
-	w := colors.NewColorWriter(os.Stdout)
-
-	var nameSpaces []string
+func writeRouterFile(apppath string, tables []*Table, rPath string, pkgPath string, tpl string) {
+	data := routerData{PkgPath: pkgPath}
 	for _, tb := range tables {
 		if tb.Pk == "" {
 			continue
 		}
 		// Add namespaces
-		nameSpace := strings.Replace(NamespaceTPL, "{{nameSpace}}", tb.Name, -1)
-		nameSpace = strings.Replace(nameSpace, "{{ctrlName}}", utils.CamelCase(tb.Name), -1)
-		nameSpaces = append(nameSpaces, nameSpace)
+		data.Tables = append(data.Tables, routerTable{NameSpace: tb.Resource, ControllerName: tb.Model})
 	}
 	// Add export controller
 	fpath := filepath.Join(rPath, "router.go")
-	routerStr := strings.Replace(RouterTPL, "{{nameSpaces}}", strings.Join(nameSpaces, ""), 1)
-	routerStr = strings.Replace(routerStr, "{{pkgPath}}", pkgPath, 1)
-	var f *os.File
-	var err error
-	if utils.IsExist(fpath) {
-		beeLogger.Log.Warnf("'%s' already exists. Do you want to overwrite it? [Yes|No] ", fpath)
-		if utils.AskForConfirmation() {
-			f, err = os.OpenFile(fpath, os.O_RDWR|os.O_TRUNC, 0666)
-			if err != nil {
-				beeLogger.Log.Warnf("%s", err)
-				return
-			}
-		} else {
-			beeLogger.Log.Warnf("Skipped create file '%s'", fpath)
-			return
-		}
-	} else {
-		f, err = os.OpenFile(fpath, os.O_CREATE|os.O_RDWR, 0666)
-		if err != nil {
-			beeLogger.Log.Warnf("%s", err)
-			return
-		}
-	}
-	if _, err := f.WriteString(routerStr); err != nil {
-		beeLogger.Log.Fatalf("Could not write router file to '%s': %s", fpath, err)
-	}
-	utils.CloseFile(f)
-	fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
-	utils.FormatSourceCode(fpath)
+	routerStr := renderTemplate(apppath, tpl, data)
+	writeAppcodeFile(fpath, routerStr, "router")
 }
 
 func isSQLTemporalType(t string) bool {
@@ -1008,360 +1114,3 @@ func getPackagePath(curpath string) (packpath string) {
 	packpath = strings.Join(strings.Split(curpath[len(appsrcpath)+1:], string(filepath.Separator)), "/")
 	return
 }
-
-const (
-	StructModelTPL = `package models
-{{importTimePkg}}
-{{modelStruct}}
-`
-
-	ModelTPL = `package models
-
-import (
-	"errors"
-	"fmt"
-	"reflect"
-	"strings"
-	{{timePkg}}
-	"github.com/beego/beego/v2/client/orm"
-)
-
-{{modelStruct}}
-
-func (t *{{modelName}}) TableName() string {
-	return "{{tableName}}"
-}
-
-func init() {
-	orm.RegisterModel(new({{modelName}}))
-}
-
-// Add{{modelName}} insert a new {{modelName}} into database and returns
-// last inserted Id on success.
-func Add{{modelName}}(m *{{modelName}}) (id int64, err error) {
-	o := orm.NewOrm()
-	id, err = o.Insert(m)
-	return
-}
-
-// Get{{modelName}}ById retrieves {{modelName}} by Id. Returns error if
-// Id doesn't exist
-func Get{{modelName}}ById(id int) (v *{{modelName}}, err error) {
-	o := orm.NewOrm()
-	v = &{{modelName}}{Id: id}
-	if err = o.Read(v); err == nil {
-		return v, nil
-	}
-	return nil, err
-}
-
-// GetAll{{modelName}} retrieves all {{modelName}} matches certain condition. Returns empty list if
-// no records exist
-func GetAll{{modelName}}(query map[string]string, fields []string, sortby []string, order []string,
-	offset int64, limit int64) (ml []interface{}, err error) {
-	o := orm.NewOrm()
-	qs := o.QueryTable(new({{modelName}}))
-	// query k=v
-	for k, v := range query {
-		// rewrite dot-notation to Object__Attribute
-		k = strings.Replace(k, ".", "__", -1)
-		if strings.Contains(k, "isnull") {
-			qs = qs.Filter(k, (v == "true" || v == "1"))
-		} else {
-			qs = qs.Filter(k, v)
-		}
-	}
-	// order by:
-	var sortFields []string
-	if len(sortby) != 0 {
-		if len(sortby) == len(order) {
-			// 1) for each sort field, there is an associated order
-			for i, v := range sortby {
-				orderby := ""
-				if order[i] == "desc" {
-					orderby = "-" + v
-				} else if order[i] == "asc" {
-					orderby = v
-				} else {
-					return nil, errors.New("Error: Invalid order. Must be either [asc|desc]")
-				}
-				sortFields = append(sortFields, orderby)
-			}
-			qs = qs.OrderBy(sortFields...)
-		} else if len(sortby) != len(order) && len(order) == 1 {
-			// 2) there is exactly one order, all the sorted fields will be sorted by this order
-			for _, v := range sortby {
-				orderby := ""
-				if order[0] == "desc" {
-					orderby = "-" + v
-				} else if order[0] == "asc" {
-					orderby = v
-				} else {
-					return nil, errors.New("Error: Invalid order. Must be either [asc|desc]")
-				}
-				sortFields = append(sortFields, orderby)
-			}
-		} else if len(sortby) != len(order) && len(order) != 1 {
-			return nil, errors.New("Error: 'sortby', 'order' sizes mismatch or 'order' size is not 1")
-		}
-	} else {
-		if len(order) != 0 {
-			return nil, errors.New("Error: unused 'order' fields")
-		}
-	}
-
-	var l []{{modelName}}
-	qs = qs.OrderBy(sortFields...)
-	if _, err = qs.Limit(limit, offset).All(&l, fields...); err == nil {
-		if len(fields) == 0 {
-			for _, v := range l {
-				ml = append(ml, v)
-			}
-		} else {
-			// trim unused fields
-			for _, v := range l {
-				m := make(map[string]interface{})
-				val := reflect.ValueOf(v)
-				for _, fname := range fields {
-					m[fname] = val.FieldByName(fname).Interface()
-				}
-				ml = append(ml, m)
-			}
-		}
-		return ml, nil
-	}
-	return nil, err
-}
-
-// Update{{modelName}} updates {{modelName}} by Id and returns error if
-// the record to be updated doesn't exist
-func Update{{modelName}}ById(m *{{modelName}}) (err error) {
-	o := orm.NewOrm()
-	v := {{modelName}}{Id: m.Id}
-	// ascertain id exists in the database
-	if err = o.Read(&v); err == nil {
-		var num int64
-		if num, err = o.Update(m); err == nil {
-			fmt.Println("Number of records updated in database:", num)
-		}
-	}
-	return
-}
-
-// Delete{{modelName}} deletes {{modelName}} by Id and returns error if
-// the record to be deleted doesn't exist
-func Delete{{modelName}}(id int) (err error) {
-	o := orm.NewOrm()
-	v := {{modelName}}{Id: id}
-	// ascertain id exists in the database
-	if err = o.Read(&v); err == nil {
-		var num int64
-		if num, err = o.Delete(&{{modelName}}{Id: id}); err == nil {
-			fmt.Println("Number of records deleted in database:", num)
-		}
-	}
-	return
-}
-`
-	CtrlTPL = `package controllers
-
-import (
-	"{{pkgPath}}/models"
-	"encoding/json"
-	"errors"
-	"strconv"
-	"strings"
-
-	beego "github.com/beego/beego/v2/server/web"
-)
-
-// {{ctrlName}}Controller operations for {{ctrlName}}
-type {{ctrlName}}Controller struct {
-	beego.Controller
-}
-
-// URLMapping ...
-func (c *{{ctrlName}}Controller) URLMapping() {
-	c.Mapping("Post", c.Post)
-	c.Mapping("GetOne", c.GetOne)
-	c.Mapping("GetAll", c.GetAll)
-	c.Mapping("Put", c.Put)
-	c.Mapping("Delete", c.Delete)
-}
-
-// Post ...
-// @Title Post
-// @Description create {{ctrlName}}
-// @Param	body		body 	models.{{ctrlName}}	true		"body for {{ctrlName}} content"
-// @Success 201 {int} models.{{ctrlName}}
-// @Failure 403 body is empty
-// @router / [post]
-func (c *{{ctrlName}}Controller) Post() {
-	var v models.{{ctrlName}}
-	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &v); err == nil {
-		if _, err := models.Add{{ctrlName}}(&v); err == nil {
-			c.Ctx.Output.SetStatus(201)
-			c.Data["json"] = v
-		} else {
-			c.Data["json"] = err.Error()
-		}
-	} else {
-		c.Data["json"] = err.Error()
-	}
-	c.ServeJSON()
-}
-
-// GetOne ...
-// @Title Get One
-// @Description get {{ctrlName}} by id
-// @Param	id		path 	string	true		"The key for staticblock"
-// @Success 200 {object} models.{{ctrlName}}
-// @Failure 403 :id is empty
-// @router /:id [get]
-func (c *{{ctrlName}}Controller) GetOne() {
-	idStr := c.Ctx.Input.Param(":id")
-	id, _ := strconv.Atoi(idStr)
-	v, err := models.Get{{ctrlName}}ById(id)
-	if err != nil {
-		c.Data["json"] = err.Error()
-	} else {
-		c.Data["json"] = v
-	}
-	c.ServeJSON()
-}
-
-// GetAll ...
-// @Title Get All
-// @Description get {{ctrlName}}
-// @Param	query	query	string	false	"Filter. e.g. col1:v1,col2:v2 ..."
-// @Param	fields	query	string	false	"Fields returned. e.g. col1,col2 ..."
-// @Param	sortby	query	string	false	"Sorted-by fields. e.g. col1,col2 ..."
-// @Param	order	query	string	false	"Order corresponding to each sortby field, if single value, apply to all sortby fields. e.g. desc,asc ..."
-// @Param	limit	query	string	false	"Limit the size of result set. Must be an integer"
-// @Param	offset	query	string	false	"Start position of result set. Must be an integer"
-// @Success 200 {object} models.{{ctrlName}}
-// @Failure 403
-// @router / [get]
-func (c *{{ctrlName}}Controller) GetAll() {
-	var fields []string
-	var sortby []string
-	var order []string
-	var query = make(map[string]string)
-	var limit int64 = 10
-	var offset int64
-
-	// fields: col1,col2,entity.col3
-	if v := c.GetString("fields"); v != "" {
-		fields = strings.Split(v, ",")
-	}
-	// limit: 10 (default is 10)
-	if v, err := c.GetInt64("limit"); err == nil {
-		limit = v
-	}
-	// offset: 0 (default is 0)
-	if v, err := c.GetInt64("offset"); err == nil {
-		offset = v
-	}
-	// sortby: col1,col2
-	if v := c.GetString("sortby"); v != "" {
-		sortby = strings.Split(v, ",")
-	}
-	// order: desc,asc
-	if v := c.GetString("order"); v != "" {
-		order = strings.Split(v, ",")
-	}
-	// query: k:v,k:v
-	if v := c.GetString("query"); v != "" {
-		for _, cond := range strings.Split(v, ",") {
-			kv := strings.SplitN(cond, ":", 2)
-			if len(kv) != 2 {
-				c.Data["json"] = errors.New("Error: invalid query key/value pair")
-				c.ServeJSON()
-				return
-			}
-			k, v := kv[0], kv[1]
-			query[k] = v
-		}
-	}
-
-	l, err := models.GetAll{{ctrlName}}(query, fields, sortby, order, offset, limit)
-	if err != nil {
-		c.Data["json"] = err.Error()
-	} else {
-		c.Data["json"] = l
-	}
-	c.ServeJSON()
-}
-
-// Put ...
-// @Title Put
-// @Description update the {{ctrlName}}
-// @Param	id		path 	string	true		"The id you want to update"
-// @Param	body		body 	models.{{ctrlName}}	true		"body for {{ctrlName}} content"
-// @Success 200 {object} models.{{ctrlName}}
-// @Failure 403 :id is not int
-// @router /:id [put]
-func (c *{{ctrlName}}Controller) Put() {
-	idStr := c.Ctx.Input.Param(":id")
-	id, _ := strconv.Atoi(idStr)
-	v := models.{{ctrlName}}{Id: id}
-	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &v); err == nil {
-		if err := models.Update{{ctrlName}}ById(&v); err == nil {
-			c.Data["json"] = "OK"
-		} else {
-			c.Data["json"] = err.Error()
-		}
-	} else {
-		c.Data["json"] = err.Error()
-	}
-	c.ServeJSON()
-}
-
-// Delete ...
-// @Title Delete
-// @Description delete the {{ctrlName}}
-// @Param	id		path 	string	true		"The id you want to delete"
-// @Success 200 {string} delete success!
-// @Failure 403 id is empty
-// @router /:id [delete]
-func (c *{{ctrlName}}Controller) Delete() {
-	idStr := c.Ctx.Input.Param(":id")
-	id, _ := strconv.Atoi(idStr)
-	if err := models.Delete{{ctrlName}}(id); err == nil {
-		c.Data["json"] = "OK"
-	} else {
-		c.Data["json"] = err.Error()
-	}
-	c.ServeJSON()
-}
-`
-	RouterTPL = `// @APIVersion 1.0.0
-// @Title beego Test API
-// @Description beego has a very cool tools to autogenerate documents for your API
-// @Contact astaxie@gmail.com
-// @TermsOfServiceUrl http://beego.me/
-// @License Apache 2.0
-// @LicenseUrl http://www.apache.org/licenses/LICENSE-2.0.html
-package routers
-
-import (
-	"{{pkgPath}}/controllers"
-
-	beego "github.com/beego/beego/v2/server/web"
-)
-
-func init() {
-	ns := beego.NewNamespace("/v1",
-		{{nameSpaces}}
-	)
-	beego.AddNamespace(ns)
-}
-`
-	NamespaceTPL = `
-		beego.NSNamespace("/{{nameSpace}}",
-			beego.NSInclude(
-				&controllers.{{ctrlName}}Controller{},
-			),
-		),
-`
-)
@@ -0,0 +1,370 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beego/bee/v2/utils"
+)
+
+// -fields 的语法：name:type[?][:modifier]...，字段之间用逗号分隔，括号中的逗号不分隔字段。
+//   类型：string[(size)]、text、int...、uint...、float、float32、float64、bool、datetime、
+//         decimal[(digits,decimals)]、enum(a,b,...)、json、jsonb、uuid、ref[(table)]、auto、pk
+//   类型后的 ? 表示字段可以为 NULL
+//   修饰：unique、index、default(value)，默认值必须符合字段的类型
+// 例如 title:string(64):unique,price:decimal(10,2):default(0),status:enum(draft,published),author:ref(user)?
+// 模型、视图和两个数据库驱动都使用同一个解析结果，保证生成的代码一致。
+
+// field is a field of -fields
+type field struct {
+	Name    string   // the name given in -fields, e.g. published_at
+	Type    string   // e.g. string, decimal, ref
+	Args    []string // the arguments of the type, e.g. [10 2] for decimal(10,2)
+	Null    bool     // the field is nullable
+	Unique  bool
+	Index   bool
+	Default *string // the default value, nil when there is none
+}
+
+// fieldsFormatError is the error of a malformed -fields item
+func fieldsFormatError(v string) error {
+	return fmt.Errorf("the fields format is wrong. Should be key:type,key:type %s", v)
+}
+
+// splitTopLevel splits s at the separators which are not between parentheses
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// splitCall splits name(args) into name and the comma separated args
+func splitCall(s string) (name string, args []string, ok bool) {
+	i := strings.IndexByte(s, '(')
+	if i < 0 {
+		return s, nil, true
+	}
+	if !strings.HasSuffix(s, ")") {
+		return "", nil, false
+	}
+	for _, a := range strings.Split(s[i+1:len(s)-1], ",") {
+		args = append(args, strings.TrimSpace(a))
+	}
+	return s[:i], args, true
+}
+
+// parseFields parses the -fields option
+func parseFields(fields string) ([]field, error) {
+	var fds []field
+	for _, v := range splitTopLevel(fields, ',') {
+		parts := splitTopLevel(strings.TrimSpace(v), ':')
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fieldsFormatError(v)
+		}
+		f := field{Name: parts[0]}
+		typ := parts[1]
+		if strings.HasSuffix(typ, "?") {
+			f.Null = true
+			typ = strings.TrimSuffix(typ, "?")
+		}
+		var ok bool
+		if f.Type, f.Args, ok = splitCall(typ); !ok {
+			return nil, fieldsFormatError(v)
+		}
+		for _, m := range parts[2:] {
+			name, args, ok := splitCall(m)
+			switch {
+			case !ok:
+				return nil, fieldsFormatError(v)
+			case name == "unique" && args == nil:
+				f.Unique = true
+			case name == "index" && args == nil:
+				f.Index = true
+			case name == "default" && args != nil:
+				def := m[len("default(") : len(m)-1]
+				f.Default = &def
+			case f.Type == "string" && f.Args == nil && isDigits(m):
+				// the size of string:64, before the parentheses were supported
+				f.Args = []string{m}
+			default:
+				return nil, fmt.Errorf("unknown modifier '%s' in %s", m, v)
+			}
+		}
+		if err := f.check(); err != nil {
+			return nil, fmt.Errorf("%s in %s", err, v)
+		}
+		fds = append(fds, f)
+	}
+	return fds, nil
+}
+
+func isDigits(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 32)
+	return err == nil
+}
+
+// check validates the type arguments of the field
+func (f field) check() error {
+	switch f.Type {
+	case "string":
+		if len(f.Args) > 1 || len(f.Args) == 1 && !isDigits(f.Args[0]) {
+			return fmt.Errorf("string takes a size, e.g. string(64)")
+		}
+	case "decimal":
+		if len(f.Args) != 0 && (len(f.Args) != 2 || !isDigits(f.Args[0]) || !isDigits(f.Args[1])) {
+			return fmt.Errorf("decimal takes the digits and the decimals, e.g. decimal(10,2)")
+		}
+	case "enum":
+		if len(f.Args) == 0 {
+			return fmt.Errorf("enum takes its values, e.g. enum(draft,published)")
+		}
+		for _, a := range f.Args {
+			if a == "" || strings.ContainsAny(a, `'"\`) {
+				return fmt.Errorf("invalid enum value '%s'", a)
+			}
+		}
+	case "ref":
+		if len(f.Args) > 1 {
+			return fmt.Errorf("ref takes the referenced table, e.g. ref(user)")
+		}
+	default:
+		if f.Args != nil {
+			return fmt.Errorf("%s takes no argument", f.Type)
+		}
+		if f.goType() == "" {
+			return fmt.Errorf("unknown type '%s'", f.Type)
+		}
+	}
+	if f.Default != nil {
+		if err := f.checkDefault(*f.Default); err != nil {
+			return err
+		}
+	}
+	if f.Type == "ref" && (f.Unique || f.Index || f.Default != nil) {
+		return fmt.Errorf("ref takes no modifier")
+	}
+	return nil
+}
+
+// checkDefault validates the default value of the field against its type, the
+// numbers and the booleans are written in the DDL without quotes
+func (f field) checkDefault(def string) error {
+	if strings.ContainsAny(def, `'"\;`) {
+		return fmt.Errorf("invalid default value '%s'", def)
+	}
+	var err error
+	switch typ := f.goType(); typ {
+	case "int", "int8", "int16", "int32", "int64":
+		_, err = strconv.ParseInt(def, 10, intBits(strings.TrimPrefix(typ, "int")))
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		_, err = strconv.ParseUint(def, 10, intBits(strings.TrimPrefix(typ, "uint")))
+	case "float32", "float64":
+		_, err = strconv.ParseFloat(def, 64)
+	case "bool":
+		if def != "true" && def != "false" {
+			err = strconv.ErrSyntax
+		}
+	case "time.Time":
+		if _, e := time.Parse("2006-01-02 15:04:05", def); e != nil {
+			_, err = time.Parse("2006-01-02", def)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid default value '%s' for %s", def, f.Type)
+	}
+	if f.Type == "enum" {
+		for _, a := range f.Args {
+			if a == def {
+				return nil
+			}
+		}
+		return fmt.Errorf("default value '%s' is not a value of the enum", def)
+	}
+	return nil
+}
+
+// intBits returns the size of an integer type from its suffix, e.g. 16 for int16
+func intBits(suffix string) int {
+	if suffix == "" {
+		return 64
+	}
+	n, _ := strconv.Atoi(suffix)
+	return n
+}
+
+// goName returns the name of the struct field, e.g. PublishedAt
+func (f field) goName() string {
+	return utils.CamelString(f.Name)
+}
+
+// column returns the name of the column, e.g. author_id for author:ref(user)
+func (f field) column() string {
+	if f.Type == "ref" {
+		return utils.SnakeString(f.Name) + "_id"
+	}
+	return utils.SnakeString(f.Name)
+}
+
+// refTable returns the table referenced by a ref field: the argument, or the name of the field
+func (f field) refTable() string {
+	if len(f.Args) == 1 {
+		return f.Args[0]
+	}
+	return utils.SnakeString(f.Name)
+}
+
+// size returns the size of a string or of an enum field
+func (f field) size() string {
+	switch f.Type {
+	case "enum":
+		n := 0
+		for _, a := range f.Args {
+			if len(a) > n {
+				n = len(a)
+			}
+		}
+		return strconv.Itoa(n)
+	case "uuid":
+		return "36"
+	}
+	if len(f.Args) == 1 {
+		return f.Args[0]
+	}
+	return "128"
+}
+
+// digits returns the precision and the scale of a decimal field
+func (f field) digits() (string, string) {
+	if len(f.Args) == 2 {
+		return f.Args[0], f.Args[1]
+	}
+	return "10", "2"
+}
+
+// isNumeric reports whether the default value of the field is written without quotes
+func (f field) isNumeric() bool {
+	switch f.goType() {
+	case "string", "time.Time":
+		return false
+	}
+	return true
+}
+
+// goType returns the Go type of the field, or an empty string for an unknown type
+func (f field) goType() string {
+	switch f.Type {
+	case "string", "text", "enum", "json", "jsonb", "uuid":
+		return "string"
+	case "auto", "pk":
+		return "int64"
+	case "datetime":
+		return "time.Time"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"bool", "float32", "float64":
+		return f.Type
+	case "float", "decimal":
+		return "float64"
+	case "ref":
+		return "*" + utils.CamelString(f.refTable())
+	}
+	return ""
+}
+
+// ormTag returns the orm tag of the field, e.g. `orm:"size(128);unique"`
+func (f field) ormTag() string {
+	var opts []string
+	switch f.Type {
+	case "string", "enum", "uuid":
+		opts = append(opts, "size("+f.size()+")")
+	case "text":
+		opts = append(opts, "type(longtext)")
+	case "json", "jsonb":
+		opts = append(opts, "type("+f.Type+")")
+	case "decimal":
+		d, s := f.digits()
+		opts = append(opts, "digits("+d+")", "decimals("+s+")")
+	case "auto", "pk":
+		opts = append(opts, f.Type)
+	case "datetime":
+		opts = append(opts, "type(datetime)")
+	case "ref":
+		opts = append(opts, "rel(fk)")
+	}
+	if f.Null {
+		opts = append(opts, "null")
+		if f.Type == "ref" {
+			opts = append(opts, "on_delete(set_null)")
+		}
+	}
+	if f.Unique {
+		opts = append(opts, "unique")
+	}
+	if f.Index {
+		opts = append(opts, "index")
+	}
+	if f.Default != nil {
+		opts = append(opts, "default("+*f.Default+")")
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+	return "`orm:\"" + strings.Join(opts, ";") + "\"`"
+}
+
+// sqlDefault returns the DEFAULT clause of the column, or an empty string
+func (f field) sqlDefault() string {
+	if f.Default == nil {
+		return ""
+	}
+	if f.isNumeric() {
+		return " DEFAULT " + *f.Default
+	}
+	return " DEFAULT '" + *f.Default + "'"
+}
+
+// sqlNull returns the NULL or NOT NULL clause of the column
+func (f field) sqlNull() string {
+	if f.Null {
+		return " NULL"
+	}
+	return " NOT NULL"
+}
+
+// enumValues returns the quoted values of an enum field, e.g. 'draft','published'
+func (f field) enumValues() string {
+	values := make([]string, len(f.Args))
+	for i, a := range f.Args {
+		values[i] = "'" + a + "'"
+	}
+	return strings.Join(values, ",")
+}
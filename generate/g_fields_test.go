@@ -0,0 +1,167 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestParseFields(t *testing.T) {
+	testCases := []struct {
+		fields   string
+		expected []field
+		err      string
+	}{
+		{
+			fields: "title:string,body:text",
+			expected: []field{
+				{Name: "title", Type: "string"},
+				{Name: "body", Type: "text"},
+			},
+		},
+		{
+			fields: "title:string(64):unique,views:int:index",
+			expected: []field{
+				{Name: "title", Type: "string", Args: []string{"64"}, Unique: true},
+				{Name: "views", Type: "int", Index: true},
+			},
+		},
+		{
+			fields:   "title:string:64",
+			expected: []field{{Name: "title", Type: "string", Args: []string{"64"}}},
+		},
+		{
+			fields: "body:text?,author:ref(user)?",
+			expected: []field{
+				{Name: "body", Type: "text", Null: true},
+				{Name: "author", Type: "ref", Args: []string{"user"}, Null: true},
+			},
+		},
+		{
+			fields: "price:decimal(10,2):default(0.5),total:decimal",
+			expected: []field{
+				{Name: "price", Type: "decimal", Args: []string{"10", "2"}, Default: strPtr("0.5")},
+				{Name: "total", Type: "decimal"},
+			},
+		},
+		{
+			fields: "status:enum(draft, published):default(draft)",
+			expected: []field{
+				{Name: "status", Type: "enum", Args: []string{"draft", "published"}, Default: strPtr("draft")},
+			},
+		},
+		{
+			fields: "views:int:default(-1),active:bool:default(true),at:datetime:default(2024-01-02 03:04:05),name:string:default(none)",
+			expected: []field{
+				{Name: "views", Type: "int", Default: strPtr("-1")},
+				{Name: "active", Type: "bool", Default: strPtr("true")},
+				{Name: "at", Type: "datetime", Default: strPtr("2024-01-02 03:04:05")},
+				{Name: "name", Type: "string", Default: strPtr("none")},
+			},
+		},
+		{
+			fields:   "user:ref",
+			expected: []field{{Name: "user", Type: "ref"}},
+		},
+		{fields: "title", err: "the fields format is wrong. Should be key:type,key:type title"},
+		{fields: ":string", err: "the fields format is wrong. Should be key:type,key:type :string"},
+		{fields: "title:string(64", err: "the fields format is wrong. Should be key:type,key:type title:string(64"},
+		{fields: "title:string:sorted", err: "unknown modifier 'sorted' in title:string:sorted"},
+		{fields: "title:varchar", err: "unknown type 'varchar' in title:varchar"},
+		{fields: "title:string(long)", err: "string takes a size, e.g. string(64) in title:string(long)"},
+		{fields: "price:decimal(10)", err: "decimal takes the digits and the decimals, e.g. decimal(10,2) in price:decimal(10)"},
+		{fields: "status:enum", err: "enum takes its values, e.g. enum(draft,published) in status:enum"},
+		{fields: "status:enum(a,'b')", err: "invalid enum value ''b'' in status:enum(a,'b')"},
+		{fields: "status:enum(a,b):default(c)", err: "default value 'c' is not a value of the enum in status:enum(a,b):default(c)"},
+		{fields: "author:ref(user,id)", err: "ref takes the referenced table, e.g. ref(user) in author:ref(user,id)"},
+		{fields: "author:ref:index", err: "ref takes no modifier in author:ref:index"},
+		{fields: "views:int(11)", err: "int takes no argument in views:int(11)"},
+		{fields: "views:int:default(abc)", err: "invalid default value 'abc' for int in views:int:default(abc)"},
+		{fields: "views:int8:default(300)", err: "invalid default value '300' for int8 in views:int8:default(300)"},
+		{fields: "views:uint:default(-1)", err: "invalid default value '-1' for uint in views:uint:default(-1)"},
+		{fields: "price:float:default(cheap)", err: "invalid default value 'cheap' for float in price:float:default(cheap)"},
+		{fields: "active:bool:default(yes)", err: "invalid default value 'yes' for bool in active:bool:default(yes)"},
+		{fields: "at:datetime:default(now)", err: "invalid default value 'now' for datetime in at:datetime:default(now)"},
+		{fields: "name:string:default(a'b)", err: "invalid default value 'a'b' in name:string:default(a'b)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fields, func(t *testing.T) {
+			fds, err := parseFields(tc.fields)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fds, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, fds)
+			}
+		})
+	}
+}
+
+func TestFieldColumn(t *testing.T) {
+	testCases := []struct {
+		fields  string
+		goType  string
+		column  string
+		ormTag  string
+		def     string
+		sqlNull string
+	}{
+		{"title:string(64):unique", "string", "title", "`orm:\"size(64);unique\"`", "", " NOT NULL"},
+		{"title:string:64", "string", "title", "`orm:\"size(64)\"`", "", " NOT NULL"},
+		{"body:text?", "string", "body", "`orm:\"type(longtext);null\"`", "", " NULL"},
+		{"price:decimal(12,4):default(1.5)", "float64", "price", "`orm:\"digits(12);decimals(4);default(1.5)\"`", " DEFAULT 1.5", " NOT NULL"},
+		{"status:enum(draft,published):default(draft)", "string", "status", "`orm:\"size(9);default(draft)\"`", " DEFAULT 'draft'", " NOT NULL"},
+		{"author:ref(user)?", "*User", "author_id", "`orm:\"rel(fk);null;on_delete(set_null)\"`", "", " NULL"},
+		{"blog_post:ref", "*BlogPost", "blog_post_id", "`orm:\"rel(fk)\"`", "", " NOT NULL"},
+		{"views:int:default(0):index", "int", "views", "`orm:\"index;default(0)\"`", " DEFAULT 0", " NOT NULL"},
+		{"published_at:datetime?", "time.Time", "published_at", "`orm:\"type(datetime);null\"`", "", " NULL"},
+		{"active:bool", "bool", "active", "", "", " NOT NULL"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fields, func(t *testing.T) {
+			fds, err := parseFields(tc.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := fds[0]
+			if got := f.goType(); got != tc.goType {
+				t.Errorf("goType: expected %s, got %s", tc.goType, got)
+			}
+			if got := f.column(); got != tc.column {
+				t.Errorf("column: expected %s, got %s", tc.column, got)
+			}
+			if got := f.ormTag(); got != tc.ormTag {
+				t.Errorf("ormTag: expected %s, got %s", tc.ormTag, got)
+			}
+			if got := f.sqlDefault(); got != tc.def {
+				t.Errorf("sqlDefault: expected %q, got %q", tc.def, got)
+			}
+			if got := f.sqlNull(); got != tc.sqlNull {
+				t.Errorf("sqlNull: expected %q, got %q", tc.sqlNull, got)
+			}
+		})
+	}
+}
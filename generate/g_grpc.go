@@ -0,0 +1,288 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/beego/bee/v2/internal/pkg/generated"
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
+)
+
+// 根据数据表生成 gRPC 服务，与 appcode 共用表、列和命名规则：
+//   - models/：与 appcode 的 flat 架构相同的模型
+//   - pb/：每张表一个 .proto 文件，以及运行 protoc 的 go:generate 指令
+//   - rpc/：调用模型实现 gRPC 服务，Register 注册所有服务
+//   - main.go：在 Beego HTTP 服务旁启动 gRPC 服务
+// 只有单列主键的表生成服务，外键字段在消息中表示为被引用记录的 id。
+
+// grpcTypes maps the Go types of the columns to the protobuf types, and to the
+// conversions of the fields from the models to the messages and back
+var grpcTypes = map[string]struct{ Proto, ToProto, FromProto string }{
+	"int":       {"int64", "int64(%s)", "int(%s)"},
+	"int8":      {"int32", "int32(%s)", "int8(%s)"},
+	"int16":     {"int32", "int32(%s)", "int16(%s)"},
+	"int32":     {"int32", "%s", "%s"},
+	"int64":     {"int64", "%s", "%s"},
+	"uint":      {"uint64", "uint64(%s)", "uint(%s)"},
+	"uint8":     {"uint32", "uint32(%s)", "uint8(%s)"},
+	"uint16":    {"uint32", "uint32(%s)", "uint16(%s)"},
+	"uint32":    {"uint32", "%s", "%s"},
+	"uint64":    {"uint64", "%s", "%s"},
+	"float32":   {"float", "%s", "%s"},
+	"float64":   {"double", "%s", "%s"},
+	"string":    {"string", "%s", "%s"},
+	"bool":      {"bool", "%s", "%s"},
+	"time.Time": {"google.protobuf.Timestamp", "timestamppb.New(%s)", "fromTimestamp(%s)"},
+}
+
+// grpcField is a field of the message of a model
+type grpcField struct {
+	Name      string // the name in the .proto file, e.g. author_id
+	GoName    string // the name generated by protoc-gen-go, e.g. AuthorId
+	Type      string // the protobuf type
+	Number    int
+	Field     string // the field of the model, e.g. Author
+	Ref       string // the model referenced by a relation field, e.g. User
+	ToProto   string // the conversion from the field of m, or from the id of the referenced model
+	FromProto string // the conversion from the field of in
+	Zero      string // the zero value of the field of in, e.g. 0 or ""
+}
+
+// grpcData is the data of the gRPC templates of a model
+type grpcData struct {
+	PkgPath   string
+	Package   string // the protobuf package
+	ModelName string
+	Plural    string // the plural of the model, e.g. Posts
+	Fields    []grpcField
+	HasTime   bool
+	Id        grpcField // the primary key, the field Id of the model
+	IdType    string    // the Go type of the primary key, e.g. int
+	IntId     bool      // whether the primary key is an integer, returned by Add<Model>
+}
+
+// grpcAppData is the data of the gRPC templates of the application
+type grpcAppData struct {
+	PkgPath    string
+	Models     []string
+	ProtoFiles []string
+	Driver     string
+	DriverPkg  string
+	HasRouters bool
+}
+
+// GenerateGrpc generates the protobuf messages and the gRPC services of the
+// tables, their implementation with the models, and a main.go serving them
+// next to the Beego HTTP server
+func GenerateGrpc(driver, connStr, tables, currpath string, opts AppcodeOptions) {
+	checkAppcodeSource(driver, opts)
+	all := loadTables(driver, connStr, selectTables(tables), opts)
+	pkgPath := getPackagePath(currpath)
+	resolveRelations(all, pkgPath)
+	writeGrpcFiles(currpath, pkgPath, driver, all)
+	beeLogger.Log.Hint("Generate the Go code of the .proto files with protoc, protoc-gen-go and protoc-gen-go-grpc: go generate ./pb && go mod tidy")
+	beeLogger.Log.Success("gRPC services successfully generated!")
+}
+
+// writeGrpcFiles generates the models, the .proto files, the gRPC servers and main.go
+func writeGrpcFiles(currpath, pkgPath, driver string, all []*Table) {
+	modelPath := path.Join(currpath, "models")
+	pbPath := path.Join(currpath, "pb")
+	rpcPath := path.Join(currpath, "rpc")
+	generated.MkdirAll(modelPath)
+	generated.MkdirAll(pbPath)
+	generated.MkdirAll(rpcPath)
+
+	beeLogger.Log.Info("Creating model files...")
+	writeModelFiles(currpath, all, modelPath, "appcode/model.go.tmpl")
+	writeAppcodeFile(path.Join(modelPath, "query.go"), renderTemplate(currpath, "query.go.tmpl", modelData{PackageName: "models"}), "model")
+
+	beeLogger.Log.Info("Creating protobuf and gRPC server files...")
+	app := grpcAppData{PkgPath: pkgPath, Driver: driver, DriverPkg: driverPkgs[driver]}
+	app.HasRouters = generated.Exists(path.Join(currpath, "routers", "router.go"))
+	pkg := protoPackage(pkgPath)
+	for _, tb := range all {
+		if tb.Pk == "" {
+			beeLogger.Log.Warnf("Table '%s' has no single column primary key, no gRPC service is generated", tb.Name)
+			continue
+		}
+		data, ok := tb.grpcData(pkgPath, pkg, all)
+		if !ok {
+			continue
+		}
+		file := getFileName(tb.Resource) + ".proto"
+		writeAppcodeFile(path.Join(pbPath, file), renderTemplate(currpath, "grpc/service.proto.tmpl", data), "protobuf")
+		writeAppcodeFile(path.Join(rpcPath, getFileName(tb.Resource)+".go"), renderTemplate(currpath, "grpc/server.go.tmpl", data), "gRPC server")
+		app.Models = append(app.Models, tb.Model)
+		app.ProtoFiles = append(app.ProtoFiles, file)
+	}
+	app.ProtoFiles = protoFiles(pbPath, app.ProtoFiles)
+	writeAppcodeFile(path.Join(pbPath, "generate.go"), renderTemplate(currpath, "grpc/generate.go.tmpl", app), "protobuf")
+	writeAppcodeFile(path.Join(rpcPath, "rpc.go"), renderTemplate(currpath, "grpc/rpc.go.tmpl", app), "gRPC server")
+	mainFile := path.Join(currpath, "main.go")
+	if generated.Exists(mainFile) && generated.Base(currpath, mainFile) == nil {
+		// main.go of bee new or written by hand, the merge needs the generated version
+		beeLogger.Log.Warnf("'%s' was not generated by bee, it is left unchanged: serve the gRPC services with rpc.Register", mainFile)
+		return
+	}
+	writeGenerated(currpath, mainFile, renderTemplate(currpath, "grpc/main.go.tmpl", app), "main")
+}
+
+// driverPkgs are the imports of the database drivers in the generated main.go
+var driverPkgs = map[string]string{
+	"mysql":    "github.com/go-sql-driver/mysql",
+	"postgres": "github.com/lib/pq",
+}
+
+// grpcData returns the data of the gRPC templates of the model of the table. It
+// returns false when the type of the primary key has no protobuf type.
+func (tb *Table) grpcData(pkgPath, pkg string, all []*Table) (grpcData, bool) {
+	data := grpcData{PkgPath: pkgPath, Package: pkg, ModelName: tb.Model, Plural: pluralize(tb.Model)}
+	idType := tb.pkType()
+	id, ok := newGrpcField("id", idType, "m.Id", "in.Id")
+	if !ok {
+		beeLogger.Log.Warnf("The primary key of '%s' has no protobuf type, no gRPC service is generated", tb.Name)
+		return data, false
+	}
+	data.Id, data.IdType = id, idType
+	data.IntId = strings.Contains(idType, "int")
+
+	models := make(map[string]*Table, len(all))
+	for _, t := range all {
+		models[t.Model] = t
+	}
+	for _, col := range tb.Columns {
+		var f grpcField
+		if col.Tag.RelFk || col.Tag.RelOne {
+			// the relations are sent as the id of the referenced record, with the type of its primary key
+			ref, name := strings.TrimPrefix(col.Type, "*"), utils.SnakeString(col.Name)+"_id"
+			if refTable, found := models[ref]; found {
+				f, ok = newGrpcField(name, refTable.pkType(), "m."+col.Name+".Id", "in."+protoGoName(name))
+				f.Ref = ref
+			} else {
+				ok = false
+			}
+		} else {
+			f, ok = newGrpcField(utils.SnakeString(col.Name), col.Type, "m."+col.Name, "in."+protoGoName(utils.SnakeString(col.Name)))
+			data.HasTime = data.HasTime || ok && col.Type == "time.Time"
+		}
+		if !ok {
+			beeLogger.Log.Warnf("Column '%s' of '%s' has no protobuf type, it is skipped", col.Tag.Column, tb.Name)
+			continue
+		}
+		f.Number = len(data.Fields) + 1
+		f.Field = col.Name
+		data.Fields = append(data.Fields, f)
+	}
+	return data, true
+}
+
+// newGrpcField returns the field name of the message of Go type typ, converted
+// from the field of the model and from the field of the message
+func newGrpcField(name, typ, from, to string) (grpcField, bool) {
+	t, ok := grpcTypes[typ]
+	if !ok {
+		return grpcField{}, false
+	}
+	f := grpcField{
+		Name:      name,
+		GoName:    protoGoName(name),
+		Type:      t.Proto,
+		ToProto:   fmt.Sprintf(t.ToProto, from),
+		FromProto: fmt.Sprintf(t.FromProto, to),
+		Zero:      "0",
+	}
+	switch typ {
+	case "string":
+		f.Zero = `""`
+	case "bool":
+		f.Zero = "false"
+	case "time.Time":
+		f.Zero = "nil"
+	}
+	return f, true
+}
+
+// pkType returns the Go type of the primary key of the table, the field Id of its model
+func (tb *Table) pkType() string {
+	for _, col := range tb.Columns {
+		if col.Tag.Column == tb.Pk {
+			return col.Type
+		}
+	}
+	return "int"
+}
+
+// protoPackage returns the protobuf package of the application, e.g. github.com/me/my-app => my_app
+func protoPackage(pkgPath string) string {
+	name := strings.ToLower(path.Base(pkgPath))
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// protoFiles returns the .proto files generated now and those already in pbPath, sorted
+func protoFiles(pbPath string, files []string) []string {
+	set := map[string]bool{}
+	for _, f := range files {
+		set[f] = true
+	}
+	existing, _ := filepath.Glob(filepath.Join(pbPath, "*.proto"))
+	for _, f := range existing {
+		set[filepath.Base(f)] = true
+	}
+	files = files[:0]
+	for f := range set {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// protoGoName returns the name of a field in the Go code generated by protoc-gen-go,
+// e.g. author_id => AuthorId. It follows the rules of protogen.GoCamelCase.
+func protoGoName(s string) string {
+	isLower := func(c byte) bool { return 'a' <= c && c <= 'z' }
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+			// the next word starts upper case
+		case '0' <= c && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
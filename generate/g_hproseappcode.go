@@ -24,6 +24,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	beeLogger "github.com/beego/bee/v2/logger"
 	"github.com/beego/bee/v2/logger/colors"
 	"github.com/beego/bee/v2/utils"
@@ -314,12 +315,13 @@ func genHprose(dbms, connStr string, mode byte, selectedTableNames map[string]bo
 	if trans, ok := dbDriver[dbms]; ok {
 		beeLogger.Log.Info("Analyzing database tables...")
 		tableNames := trans.GetTableNames(db)
-		tables := getTableObjects(tableNames, db, trans)
+		tables := getTableObjects(tableNames, db, trans, AppcodeOptions{})
 		mvcPath := new(MvcPath)
 		mvcPath.ModelPath = path.Join(currpath, "models")
 		createPaths(mode, mvcPath)
 		pkgPath := getPackagePath(currpath)
-		writeHproseSourceFiles(pkgPath, tables, mode, mvcPath, selectedTableNames)
+		// the application is being created, its templates are those of the directory running bee hprose
+		writeHproseSourceFiles(path.Dir(currpath), pkgPath, tables, mode, mvcPath, selectedTableNames)
 	} else {
 		beeLogger.Log.Fatalf("Generating app code from '%s' database is not supported yet", dbms)
 	}
@@ -328,15 +330,15 @@ func genHprose(dbms, connStr string, mode byte, selectedTableNames map[string]bo
 // writeHproseSourceFiles generates source files for model/controller/router
 // It will wipe the following directories and recreate them:./models, ./controllers, ./routers
 // Newly geneated files will be inside these folders.
-func writeHproseSourceFiles(pkgPath string, tables []*Table, mode byte, paths *MvcPath, selectedTables map[string]bool) {
+func writeHproseSourceFiles(tplpath, pkgPath string, tables []*Table, mode byte, paths *MvcPath, selectedTables map[string]bool) {
 	if (OModel & mode) == OModel {
 		beeLogger.Log.Info("Creating model files...")
-		writeHproseModelFiles(tables, paths.ModelPath, selectedTables)
+		writeHproseModelFiles(tplpath, tables, paths.ModelPath, selectedTables)
 	}
 }
 
-// writeHproseModelFiles generates model files
-func writeHproseModelFiles(tables []*Table, mPath string, selectedTables map[string]bool) {
+// writeHproseModelFiles generates model files with the templates of tplpath
+func writeHproseModelFiles(tplpath string, tables []*Table, mPath string, selectedTables map[string]bool) {
 	w := colors.NewColorWriter(os.Stdout)
 
 	for _, tb := range tables {
@@ -348,207 +350,31 @@ func writeHproseModelFiles(tables []*Table, mPath string, selectedTables map[str
 		}
 		filename := getFileName(tb.Name)
 		fpath := path.Join(mPath, filename+".go")
-		var f *os.File
-		var err error
-		if utils.IsExist(fpath) {
+		if generated.Exists(fpath) {
 			beeLogger.Log.Warnf("'%s' already exists. Do you want to overwrite it? [Yes|No] ", fpath)
-			if utils.AskForConfirmation() {
-				f, err = os.OpenFile(fpath, os.O_RDWR|os.O_TRUNC, 0666)
-				if err != nil {
-					beeLogger.Log.Warnf("%s", err)
-					continue
-				}
-			} else {
+			if !utils.AskForConfirmation() {
 				beeLogger.Log.Warnf("Skipped create file '%s'", fpath)
 				continue
 			}
-		} else {
-			f, err = os.OpenFile(fpath, os.O_CREATE|os.O_RDWR, 0666)
-			if err != nil {
-				beeLogger.Log.Warnf("%s", err)
-				continue
-			}
 		}
-		var template string
-		if tb.Pk == "" {
-			template = HproseStructModelTPL
-		} else {
-			template = HproseModelTPL
-			HproseAddFunctions = append(HproseAddFunctions, strings.Replace(HproseAddFunction, "{{modelName}}", utils.CamelCase(tb.Name), -1))
+		data := modelData{
+			PackageName: "models",
+			ModelName:   tb.Model,
+			TableName:   tb.Name,
+			ModelStruct: tb.String(),
+			// if table contains time field, import time.Time package
+			HasTime: tb.ImportTimePkg,
 		}
-		fileStr := strings.Replace(template, "{{modelStruct}}", tb.String(), 1)
-		fileStr = strings.Replace(fileStr, "{{modelName}}", utils.CamelCase(tb.Name), -1)
-		// if table contains time field, import time.Time package
-		timePkg := ""
-		importTimePkg := ""
-		if tb.ImportTimePkg {
-			timePkg = "\"time\"\n"
-			importTimePkg = "import \"time\"\n"
+		tpl := "hprose/struct_model.go.tmpl"
+		if tb.Pk != "" {
+			tpl = "hprose/model.go.tmpl"
+			HproseAddFunctions = append(HproseAddFunctions, renderTemplate(tplpath, "hprose/functions.go.tmpl", data))
 		}
-		fileStr = strings.Replace(fileStr, "{{timePkg}}", timePkg, -1)
-		fileStr = strings.Replace(fileStr, "{{importTimePkg}}", importTimePkg, -1)
-		if _, err := f.WriteString(fileStr); err != nil {
+		fileStr := renderTemplate(tplpath, tpl, data)
+		if err := generated.WriteFile(fpath, []byte(fileStr)); err != nil {
 			beeLogger.Log.Fatalf("Could not write model file to '%s'", fpath)
 		}
-		utils.CloseFile(f)
 		fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
 		utils.FormatSourceCode(fpath)
 	}
 }
-
-const (
-	HproseAddFunction = `
-	// publish about {{modelName}} function
-	service.AddFunction("Add{{modelName}}", models.Add{{modelName}})
-	service.AddFunction("Get{{modelName}}ById", models.Get{{modelName}}ById)
-	service.AddFunction("GetAll{{modelName}}", models.GetAll{{modelName}})
-	service.AddFunction("Update{{modelName}}ById", models.Update{{modelName}}ById)
-	service.AddFunction("Delete{{modelName}}", models.Delete{{modelName}})
-
-`
-	HproseStructModelTPL = `package models
-{{importTimePkg}}
-{{modelStruct}}
-`
-
-	HproseModelTPL = `package models
-
-import (
-	"errors"
-	"fmt"
-	"reflect"
-	"strings"
-	{{timePkg}}
-	"github.com/beego/beego/v2/client/orm"
-)
-
-{{modelStruct}}
-
-func init() {
-	orm.RegisterModel(new({{modelName}}))
-}
-
-// Add{{modelName}} insert a new {{modelName}} into database and returns
-// last inserted Id on success.
-func Add{{modelName}}(m *{{modelName}}) (id int64, err error) {
-	o := orm.NewOrm()
-	id, err = o.Insert(m)
-	return
-}
-
-// Get{{modelName}}ById retrieves {{modelName}} by Id. Returns error if
-// Id doesn't exist
-func Get{{modelName}}ById(id int) (v *{{modelName}}, err error) {
-	o := orm.NewOrm()
-	v = &{{modelName}}{Id: id}
-	if err = o.Read(v); err == nil {
-		return v, nil
-	}
-	return nil, err
-}
-
-// GetAll{{modelName}} retrieves all {{modelName}} matches certain condition. Returns empty list if
-// no records exist
-func GetAll{{modelName}}(query map[string]string, fields []string, sortby []string, order []string,
-	offset int64, limit int64) (ml []interface{}, err error) {
-	o := orm.NewOrm()
-	qs := o.QueryTable(new({{modelName}}))
-	// query k=v
-	for k, v := range query {
-		// rewrite dot-notation to Object__Attribute
-		k = strings.Replace(k, ".", "__", -1)
-		qs = qs.Filter(k, v)
-	}
-	// order by:
-	var sortFields []string
-	if len(sortby) != 0 {
-		if len(sortby) == len(order) {
-			// 1) for each sort field, there is an associated order
-			for i, v := range sortby {
-				orderby := ""
-				if order[i] == "desc" {
-					orderby = "-" + v
-				} else if order[i] == "asc" {
-					orderby = v
-				} else {
-					return nil, errors.New("Error: Invalid order. Must be either [asc|desc]")
-				}
-				sortFields = append(sortFields, orderby)
-			}
-			qs = qs.OrderBy(sortFields...)
-		} else if len(sortby) != len(order) && len(order) == 1 {
-			// 2) there is exactly one order, all the sorted fields will be sorted by this order
-			for _, v := range sortby {
-				orderby := ""
-				if order[0] == "desc" {
-					orderby = "-" + v
-				} else if order[0] == "asc" {
-					orderby = v
-				} else {
-					return nil, errors.New("Error: Invalid order. Must be either [asc|desc]")
-				}
-				sortFields = append(sortFields, orderby)
-			}
-		} else if len(sortby) != len(order) && len(order) != 1 {
-			return nil, errors.New("Error: 'sortby', 'order' sizes mismatch or 'order' size is not 1")
-		}
-	} else {
-		if len(order) != 0 {
-			return nil, errors.New("Error: unused 'order' fields")
-		}
-	}
-
-	var l []{{modelName}}
-	qs = qs.OrderBy(sortFields...)
-	if _, err = qs.Limit(limit, offset).All(&l, fields...); err == nil {
-		if len(fields) == 0 {
-			for _, v := range l {
-				ml = append(ml, v)
-			}
-		} else {
-			// trim unused fields
-			for _, v := range l {
-				m := make(map[string]interface{})
-				val := reflect.ValueOf(v)
-				for _, fname := range fields {
-					m[fname] = val.FieldByName(fname).Interface()
-				}
-				ml = append(ml, m)
-			}
-		}
-		return ml, nil
-	}
-	return nil, err
-}
-
-// Update{{modelName}} updates {{modelName}} by Id and returns error if
-// the record to be updated doesn't exist
-func Update{{modelName}}ById(m *{{modelName}}) (err error) {
-	o := orm.NewOrm()
-	v := {{modelName}}{Id: m.Id}
-	// ascertain id exists in the database
-	if err = o.Read(&v); err == nil {
-		var num int64
-		if num, err = o.Update(m); err == nil {
-			fmt.Println("Number of records updated in database:", num)
-		}
-	}
-	return
-}
-
-// Delete{{modelName}} deletes {{modelName}} by Id and returns error if
-// the record to be deleted doesn't exist
-func Delete{{modelName}}(id int) (err error) {
-	o := orm.NewOrm()
-	v := {{modelName}}{Id: id}
-	// ascertain id exists in the database
-	if err = o.Read(&v); err == nil {
-		var num int64
-		if num, err = o.Delete(&{{modelName}}{Id: id}); err == nil {
-			fmt.Println("Number of records deleted in database:", num)
-		}
-	}
-	return
-}
-`
-)
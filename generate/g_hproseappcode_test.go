@@ -0,0 +1,96 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteHproseModelFiles(t *testing.T) {
+	tables := []*Table{
+		{
+			Name:  "blog_post",
+			Model: "BlogPost",
+			Pk:    "id",
+			Columns: []*Column{
+				{Name: "Id", Type: "int", Tag: &OrmTag{Column: "id", Auto: true}},
+				{Name: "CreatedAt", Type: "time.Time", Tag: &OrmTag{Column: "created_at", Type: "datetime"}},
+			},
+			ImportTimePkg: true,
+		},
+		{
+			Name:  "post_tag",
+			Model: "PostTag",
+			Columns: []*Column{
+				{Name: "Name", Type: "string", Tag: &OrmTag{Column: "name", Size: "32"}},
+			},
+		},
+	}
+
+	dir := t.TempDir()
+	mPath := filepath.Join(dir, "models")
+	if err := os.MkdirAll(mPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the struct model is overridden by the templates of the directory
+	override := filepath.Join(dir, TemplatesDir, "hprose", "struct_model.go.tmpl")
+	if err := os.MkdirAll(filepath.Dir(override), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(override, []byte("package models\n\n// {{.ModelName}} is read only\n{{.ModelStruct}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	HproseAddFunctions = nil
+	defer func() { HproseAddFunctions = nil }()
+	writeHproseModelFiles(dir, tables, mPath, nil)
+
+	post, err := os.ReadFile(filepath.Join(mPath, "blog_post.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"\t\"time\"\n", "type BlogPost struct", "func AddBlogPost(m *BlogPost)", "func DeleteBlogPost(id int)"} {
+		if !strings.Contains(string(post), s) {
+			t.Errorf("expected %q in the model:\n%s", s, post)
+		}
+	}
+
+	tag, err := os.ReadFile(filepath.Join(mPath, "post_tag.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(tag), "// PostTag is read only\ntype PostTag struct") {
+		t.Errorf("expected the overriding template, got:\n%s", tag)
+	}
+
+	if len(HproseAddFunctions) != 1 {
+		t.Fatalf("expected the functions of BlogPost only, got %q", HproseAddFunctions)
+	}
+	expected := `
+	// publish about BlogPost function
+	service.AddFunction("AddBlogPost", models.AddBlogPost)
+	service.AddFunction("GetBlogPostById", models.GetBlogPostById)
+	service.AddFunction("GetAllBlogPost", models.GetAllBlogPost)
+	service.AddFunction("UpdateBlogPostById", models.UpdateBlogPostById)
+	service.AddFunction("DeleteBlogPost", models.DeleteBlogPost)
+
+`
+	if HproseAddFunctions[0] != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, HproseAddFunctions[0])
+	}
+}
@@ -16,13 +16,12 @@ package generate
 
 import (
 	"fmt"
-	"os"
 	"path"
 	"strings"
 	"time"
 
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	"github.com/beego/bee/v2/logger"
-	"github.com/beego/bee/v2/logger/colors"
 	"github.com/beego/bee/v2/utils"
 )
 
@@ -50,66 +49,82 @@ func (m mysqlDriver) GenerateCreateDown(tableName string) string {
 }
 
 func (m mysqlDriver) generateSQLFromFields(fields string) string {
-	sql, tags := "", ""
-	fds := strings.Split(fields, ",")
-	for i, v := range fds {
-		kv := strings.SplitN(v, ":", 2)
-		if len(kv) != 2 {
-			beeLogger.Log.Error("Fields format is wrong. Should be: key:type,key:type " + v)
-			return ""
-		}
-		typ, tag := m.getSQLType(kv[1])
-		if typ == "" {
-			beeLogger.Log.Error("Fields format is wrong. Should be: key:type,key:type " + v)
-			return ""
-		}
-		if i == 0 && strings.ToLower(kv[0]) != "id" {
-			sql += "`id` int(11) NOT NULL AUTO_INCREMENT,"
-			tags = tags + "PRIMARY KEY (`id`),"
+	fds, err := parseFields(fields)
+	if err != nil {
+		beeLogger.Log.Error(err.Error())
+		return ""
+	}
+	var cols, keys []string
+	for i, f := range fds {
+		if i == 0 && strings.ToLower(f.Name) != "id" {
+			cols = append(cols, "`id` int(11) NOT NULL AUTO_INCREMENT")
+			keys = append(keys, "PRIMARY KEY (`id`)")
 		}
-		sql += "`" + utils.SnakeString(kv[0]) + "` " + typ + ","
-		if tag != "" {
-			tags = tags + fmt.Sprintf(tag, "`"+utils.SnakeString(kv[0])+"`") + ","
+		col := "`" + f.column() + "`"
+		cols = append(cols, col+" "+m.getSQLType(f))
+		switch {
+		case f.Type == "auto" || f.Type == "pk":
+			keys = append(keys, "PRIMARY KEY ("+col+")")
+		case f.Type == "ref":
+			keys = append(keys, fmt.Sprintf("FOREIGN KEY (%s) REFERENCES `%s` (`id`) ON DELETE %s", col, f.refTable(), onDelete(f)))
+		case f.Index:
+			keys = append(keys, "KEY ("+col+")")
 		}
 	}
-	sql = strings.TrimRight(sql+tags, ",")
-	return sql
+	return strings.Join(append(cols, keys...), ",")
 }
 
-func (m mysqlDriver) getSQLType(ktype string) (tp, tag string) {
-	kv := strings.SplitN(ktype, ":", 2)
-	switch kv[0] {
+func (m mysqlDriver) getSQLType(f field) string {
+	var tp string
+	switch f.Type {
 	case "string":
-		if len(kv) == 2 {
-			return "varchar(" + kv[1] + ") NOT NULL", ""
-		}
-		return "varchar(128) NOT NULL", ""
+		tp = "varchar(" + f.size() + ")"
 	case "text":
-		return "longtext  NOT NULL", ""
+		tp = "longtext"
 	case "auto":
-		return "int(11) NOT NULL AUTO_INCREMENT", ""
+		return "int(11) NOT NULL AUTO_INCREMENT"
 	case "pk":
-		return "int(11) NOT NULL", "PRIMARY KEY (%s)"
+		return "int(11) NOT NULL"
 	case "datetime":
-		return "datetime NOT NULL", ""
+		tp = "datetime"
 	case "int", "int8", "int16", "int32", "int64":
 		fallthrough
 	case "uint", "uint8", "uint16", "uint32", "uint64":
-		return "int(11) DEFAULT NULL", ""
+		tp = "int(11)"
 	case "bool":
-		return "tinyint(1) NOT NULL", ""
-	case "float32", "float64":
-		return "float NOT NULL", ""
-	case "float":
-		return "float NOT NULL", ""
+		tp = "tinyint(1)"
+	case "float32":
+		tp = "float"
+	case "float", "float64":
+		tp = "double"
+	case "decimal":
+		d, s := f.digits()
+		tp = "decimal(" + d + "," + s + ")"
+	case "enum":
+		tp = "enum(" + f.enumValues() + ")"
+	case "json", "jsonb":
+		tp = "json"
+	case "uuid":
+		tp = "char(36)"
+	case "ref":
+		tp = "int(11)"
 	}
-	return "", ""
+	tp += f.sqlNull() + f.sqlDefault()
+	if f.Unique {
+		tp += " UNIQUE"
+	}
+	return tp
 }
 
 type postgresqlDriver struct{}
 
 func (m postgresqlDriver) GenerateCreateUp(tableName string) string {
-	upsql := `m.SQL("CREATE TABLE ` + tableName + "(" + m.generateSQLFromFields(Fields.String()) + `)");`
+	columns, indexes := m.generateSQLFromFields(Fields.String())
+	upsql := `m.SQL("CREATE TABLE ` + tableName + "(" + columns + `)");`
+	for _, col := range indexes {
+		// PostgreSQL declares the indexes out of the table
+		upsql += "\n\t" + `m.SQL("CREATE INDEX ` + tableName + "_" + col + "_idx ON " + tableName + " (" + col + `)");`
+	}
 	return upsql
 }
 
@@ -118,60 +133,79 @@ func (m postgresqlDriver) GenerateCreateDown(tableName string) string {
 	return downsql
 }
 
-func (m postgresqlDriver) generateSQLFromFields(fields string) string {
-	sql, tags := "", ""
-	fds := strings.Split(fields, ",")
-	for i, v := range fds {
-		kv := strings.SplitN(v, ":", 2)
-		if len(kv) != 2 {
-			beeLogger.Log.Error("Fields format is wrong. Should be: key:type,key:type " + v)
-			return ""
-		}
-		typ, tag := m.getSQLType(kv[1])
-		if typ == "" {
-			beeLogger.Log.Error("Fields format is wrong. Should be: key:type,key:type " + v)
-			return ""
-		}
-		if i == 0 && strings.ToLower(kv[0]) != "id" {
-			sql += "id serial primary key,"
+// generateSQLFromFields returns the columns of the table, and the columns to index
+func (m postgresqlDriver) generateSQLFromFields(fields string) (string, []string) {
+	fds, err := parseFields(fields)
+	if err != nil {
+		beeLogger.Log.Error(err.Error())
+		return "", nil
+	}
+	var cols, indexes []string
+	for i, f := range fds {
+		if i == 0 && strings.ToLower(f.Name) != "id" {
+			cols = append(cols, "id serial primary key")
 		}
-		sql += utils.SnakeString(kv[0]) + " " + typ + ","
-		if tag != "" {
-			tags = tags + fmt.Sprintf(tag, utils.SnakeString(kv[0])) + ","
+		cols = append(cols, f.column()+" "+m.getSQLType(f))
+		if f.Index {
+			indexes = append(indexes, f.column())
 		}
 	}
-	if tags != "" {
-		sql = strings.TrimRight(sql+" "+tags, ",")
-	} else {
-		sql = strings.TrimRight(sql, ",")
-	}
-	return sql
+	return strings.Join(cols, ","), indexes
 }
 
-func (m postgresqlDriver) getSQLType(ktype string) (tp, tag string) {
-	kv := strings.SplitN(ktype, ":", 2)
-	switch kv[0] {
+func (m postgresqlDriver) getSQLType(f field) string {
+	var tp string
+	switch f.Type {
 	case "string":
-		if len(kv) == 2 {
-			return "char(" + kv[1] + ") NOT NULL", ""
-		}
-		return "TEXT NOT NULL", ""
+		tp = "varchar(" + f.size() + ")"
 	case "text":
-		return "TEXT NOT NULL", ""
+		tp = "TEXT"
 	case "auto", "pk":
-		return "serial primary key", ""
+		return "serial primary key"
 	case "datetime":
-		return "TIMESTAMP WITHOUT TIME ZONE NOT NULL", ""
+		tp = "TIMESTAMP WITHOUT TIME ZONE"
 	case "int", "int8", "int16", "int32", "int64":
 		fallthrough
 	case "uint", "uint8", "uint16", "uint32", "uint64":
-		return "integer DEFAULT NULL", ""
+		tp = "integer"
 	case "bool":
-		return "boolean NOT NULL", ""
-	case "float32", "float64", "float":
-		return "numeric NOT NULL", ""
+		tp = "boolean"
+	case "float32":
+		tp = "real"
+	case "float", "float64":
+		tp = "double precision"
+	case "decimal":
+		d, s := f.digits()
+		tp = "numeric(" + d + "," + s + ")"
+	case "enum":
+		tp = "varchar(" + f.size() + ")"
+	case "json", "jsonb":
+		tp = f.Type
+	case "uuid":
+		tp = "uuid"
+	case "ref":
+		tp = "integer"
 	}
-	return "", ""
+	tp += f.sqlNull() + f.sqlDefault()
+	if f.Unique {
+		tp += " UNIQUE"
+	}
+	switch f.Type {
+	case "enum":
+		tp += " CHECK (" + f.column() + " IN (" + f.enumValues() + "))"
+	case "ref":
+		// quoted, the table of the users is often named user, a reserved word
+		tp += ` REFERENCES \"` + f.refTable() + `\"(id) ON DELETE ` + onDelete(f)
+	}
+	return tp
+}
+
+// onDelete returns the ON DELETE action of a ref field, the one of its orm tag
+func onDelete(f field) string {
+	if f.Null {
+		return "SET NULL"
+	}
+	return "CASCADE"
 }
 
 func NewDBDriver() DBDriver {
@@ -186,105 +220,35 @@ func NewDBDriver() DBDriver {
 	}
 }
 
+// migrationData is the data of the migration template
+type migrationData struct {
+	StructName string
+	TableName  string
+	CurrTime   string
+	DDL        string
+	UpSQL      string
+	DownSQL    string
+}
+
 // generateMigration generates migration file template for database schema update.
 // The generated file template consists of an up() method for updating schema and
 // a down() method for reverting the update.
 func GenerateMigration(mname, upsql, downsql, curpath string) {
-	w := colors.NewColorWriter(os.Stdout)
 	migrationFilePath := path.Join(curpath, DBPath, MPath)
-	if _, err := os.Stat(migrationFilePath); os.IsNotExist(err) {
-		// create migrations directory
-		if err := os.MkdirAll(migrationFilePath, 0777); err != nil {
-			beeLogger.Log.Fatalf("Could not create migration directory: %s", err)
-		}
+	// create migrations directory
+	if err := generated.MkdirAll(migrationFilePath); err != nil {
+		beeLogger.Log.Fatalf("Could not create migration directory: %s", err)
 	}
 	// create file
 	today := time.Now().Format(MDateFormat)
 	fpath := path.Join(migrationFilePath, fmt.Sprintf("%s_%s.go", today, mname))
-	if f, err := os.OpenFile(fpath, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0666); err == nil {
-		defer utils.CloseFile(f)
-		ddlSpec := ""
-		spec := ""
-		up := ""
-		down := ""
-		if DDL != "" {
-			ddlSpec = "m.ddlSpec()"
-			switch strings.Title(DDL.String()) {
-			case "Create":
-				spec = strings.Replace(DDLSpecCreate, "{{StructName}}", utils.CamelCase(mname)+"_"+today, -1)
-			case "Alter":
-				spec = strings.Replace(DDLSpecAlter, "{{StructName}}", utils.CamelCase(mname)+"_"+today, -1)
-			}
-			spec = strings.Replace(spec, "{{tableName}}", mname, -1)
-		} else {
-			up = strings.Replace(MigrationUp, "{{UpSQL}}", upsql, -1)
-			up = strings.Replace(up, "{{StructName}}", utils.CamelCase(mname)+"_"+today, -1)
-			down = strings.Replace(MigrationDown, "{{DownSQL}}", downsql, -1)
-			down = strings.Replace(down, "{{StructName}}", utils.CamelCase(mname)+"_"+today, -1)
-		}
-
-		header := strings.Replace(MigrationHeader, "{{StructName}}", utils.CamelCase(mname)+"_"+today, -1)
-		header = strings.Replace(header, "{{ddlSpec}}", ddlSpec, -1)
-		header = strings.Replace(header, "{{CurrTime}}", today, -1)
-		f.WriteString(header + spec + up + down)
-		// Run 'gofmt' on the generated source code
-		utils.FormatSourceCode(fpath)
-		fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", fpath, "\x1b[0m")
-	} else {
-		beeLogger.Log.Fatalf("Could not create migration file: %s", err)
-	}
+	content := renderTemplate(curpath, "migration.go.tmpl", migrationData{
+		StructName: utils.CamelCase(mname) + "_" + today,
+		TableName:  mname,
+		CurrTime:   today,
+		DDL:        strings.Title(DDL.String()),
+		UpSQL:      upsql,
+		DownSQL:    downsql,
+	})
+	writeGenerated(curpath, fpath, content, "migration")
 }
-
-const (
-	MigrationHeader = `package main
-						import (
-							"github.com/beego/beego/v2/client/orm/migration"
-						)
-
-						// DO NOT MODIFY
-						type {{StructName}} struct {
-							migration.Migration
-						}
-
-						// DO NOT MODIFY
-						func init() {
-							m := &{{StructName}}{}
-							m.Created = "{{CurrTime}}"
-							{{ddlSpec}}
-							migration.Register("{{StructName}}", m)
-						}
-					   `
-
-	DDLSpecCreate = `
-				/*
-				refer beego/migration/doc.go
-				*/
-				func(m *{{StructName}}) ddlSpec(){
-				m.CreateTable("{{tableName}}", "InnoDB", "utf8")
-				m.PriCol("id").SetAuto(true).SetNullable(false).SetDataType("INT(10)").SetUnsigned(true)
-
-				}
-				`
-	DDLSpecAlter = `
-				/*
-				refer beego/migration/doc.go
-				*/
-				func(m *{{StructName}}) ddlSpec(){
-				m.AlterTable("{{tableName}}")
-
-				}
-				`
-	MigrationUp = `
-				// Run the migrations
-				func (m *{{StructName}}) Up() {
-					// use m.SQL("CREATE TABLE ...") to make schema update
-					{{UpSQL}}
-				}`
-	MigrationDown = `
-				// Reverse the migrations
-				func (m *{{StructName}}) Down() {
-					// use m.SQL("DROP TABLE ...") to reverse schema update
-					{{DownSQL}}
-				}
-				`
-)
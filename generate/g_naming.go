@@ -0,0 +1,150 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"strings"
+
+	"github.com/beego/bee/v2/config"
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
+)
+
+// 根据命名规则（bee.json 的 appcode 配置）确定由数据表生成的名称：
+//   - 资源名：去掉前缀后的表名，用作文件名和路由的 namespace，例如 wp_posts => posts
+//   - 模型名：资源名的驼峰形式，开启 singularize 时取单数，例如 posts => Post；
+//     models 中的自定义名称优先
+//   - 字段名：列名的驼峰形式，fields 中的自定义名称优先；主键字段始终为 Id
+// 模型的 TableName() 仍然返回原始表名。
+
+// AppcodeOptions selects the tables of GenerateAppcode and names the code generated from them
+type AppcodeOptions struct {
+	Schema      string                       // the PostgreSQL schema of the tables, all the schemas when empty
+	StripPrefix []string                     // the prefixes removed from the table names, e.g. wp_
+	Singularize bool                         // whether the models are named after the singular of the tables
+	Models      map[string]string            // the custom model names, by table
+	Fields      map[string]map[string]string // the custom field names, by table and column
+}
+
+// AppcodeOptionsFromConfig returns the naming rules of the appcode from bee.json,
+// and schema, or the schema of bee.json when it is empty
+func AppcodeOptionsFromConfig(schema string) AppcodeOptions {
+	if err := config.Resolved("appcode"); err != nil {
+		beeLogger.Log.Fatalf("Invalid configuration: %s", err)
+	}
+	opts := AppcodeOptions{
+		Schema:      config.Conf.Appcode.Schema,
+		StripPrefix: config.Conf.Appcode.StripPrefix,
+		Singularize: config.Conf.Appcode.Singularize,
+		Models:      config.Conf.Appcode.Models,
+		Fields:      config.Conf.Appcode.Fields,
+	}
+	if schema != "" {
+		opts.Schema = schema
+	}
+	return opts
+}
+
+// resource returns the name of the table without its prefix, the first
+// matching prefix is removed
+func (opts AppcodeOptions) resource(table string) string {
+	for _, prefix := range opts.StripPrefix {
+		if prefix != "" && strings.HasPrefix(table, prefix) && len(table) > len(prefix) {
+			return table[len(prefix):]
+		}
+	}
+	return table
+}
+
+// model returns the name of the model of the table
+func (opts AppcodeOptions) model(table string) string {
+	if name, ok := opts.Models[table]; ok {
+		return name
+	}
+	name := utils.CamelCase(opts.resource(table))
+	if opts.Singularize {
+		name = singularize(name)
+	}
+	return name
+}
+
+// name fills in the names of the table and the custom names of its fields
+func (opts AppcodeOptions) name(tb *Table) {
+	tb.Model = opts.model(tb.Name)
+	tb.Resource = opts.resource(tb.Name)
+	tb.Fields = opts.Fields[tb.Name]
+}
+
+// fieldName returns the name of the field of the column, e.g. created_at => CreatedAt
+func (tb *Table) fieldName(column string) string {
+	if name, ok := tb.Fields[column]; ok {
+		return name
+	}
+	return utils.CamelCase(column)
+}
+
+// irregularSingulars are the plurals which the rules of singularize get wrong,
+// and the nouns without plural form
+var irregularSingulars = map[string]string{
+	"people":   "person",
+	"children": "child",
+	"men":      "man",
+	"women":    "woman",
+	"mice":     "mouse",
+	"feet":     "foot",
+	"teeth":    "tooth",
+	"movies":   "movie",
+	"cookies":  "cookie",
+	"zombies":  "zombie",
+	"calories": "calorie",
+	"caches":   "cache",
+	"news":     "news",
+	"series":   "series",
+	"species":  "species",
+}
+
+// singularize returns the singular form of an English noun, e.g. Categories => Category.
+// It reverses pluralize, words ending with ss, us or is are returned unchanged. Only the
+// last word of a camel case name changes, e.g. BlogStatuses => BlogStatus. The rules
+// cover the common table names, the others are named by the models option.
+func singularize(s string) string {
+	word := s
+	for i := len(s) - 1; i > 0; i-- {
+		if s[i] >= 'A' && s[i] <= 'Z' {
+			word = s[i:]
+			break
+		}
+	}
+	prefix := s[:len(s)-len(word)]
+	lower := strings.ToLower(word)
+	if singular, ok := irregularSingulars[lower]; ok {
+		return prefix + word[:1] + singular[1:]
+	}
+	switch {
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return s
+	case strings.HasSuffix(lower, "ies") && len(lower) > 4:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "uses") && len(lower) > 4 && !strings.ContainsAny(lower[len(lower)-5:len(lower)-4], "aeiou"):
+		// statuses => status and buses => bus, but houses => house and causes => cause
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "s") && len(lower) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import "testing"
+
+func TestSingularize(t *testing.T) {
+	testCases := map[string]string{
+		"Posts":        "Post",
+		"Categories":   "Category",
+		"Boxes":        "Box",
+		"Matches":      "Match",
+		"Wishes":       "Wish",
+		"Addresses":    "Address",
+		"Statuses":     "Status",
+		"Buses":        "Bus",
+		"Viruses":      "Virus",
+		"Houses":       "House",
+		"Causes":       "Cause",
+		"Uses":         "Use",
+		"Movies":       "Movie",
+		"Caches":       "Cache",
+		"News":         "News",
+		"Series":       "Series",
+		"People":       "Person",
+		"Children":     "Child",
+		"Status":       "Status",
+		"Address":      "Address",
+		"Analysis":     "Analysis",
+		"Keys":         "Key",
+		"Post":         "Post",
+		"S":            "S",
+		"":             "",
+		"BlogStatuses": "BlogStatus",
+		"UserMovies":   "UserMovie",
+		"SiteNews":     "SiteNews",
+		"SalesPeople":  "SalesPerson",
+		"OrderItems":   "OrderItem",
+	}
+	for plural, expected := range testCases {
+		if got := singularize(plural); got != expected {
+			t.Errorf("singularize(%q): expected %q, got %q", plural, expected, got)
+		}
+	}
+}
+
+func TestAppcodeOptionsModel(t *testing.T) {
+	opts := AppcodeOptions{
+		StripPrefix: []string{"wp_"},
+		Singularize: true,
+		Models:      map[string]string{"wp_wolves": "Wolf"},
+	}
+	testCases := map[string]string{
+		"wp_posts":       "Post",
+		"wp_post_status": "PostStatus",
+		"wp_wolves":      "Wolf",
+		"user_movies":    "UserMovie",
+		"news":           "News",
+	}
+	for table, expected := range testCases {
+		if got := opts.model(table); got != expected {
+			t.Errorf("model(%q): expected %q, got %q", table, expected, got)
+		}
+	}
+}
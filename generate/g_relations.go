@@ -0,0 +1,231 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
+)
+
+// 根据外键信息生成模型之间的关联字段：
+//   - 外键列生成 rel(fk) 字段，带唯一约束的外键列生成 rel(one) 字段
+//   - 被引用的表生成对应的 reverse(many) / reverse(one) 字段
+//   - 仅包含两个外键的中间表被识别为多对多关系，生成 rel(m2m) 和 reverse(many) 字段
+
+// resolveRelations turns the foreign keys of the tables into Beego ORM
+// relation fields on both sides of each relation. Tables only made of two
+// foreign keys are mapped as many-to-many relations through themselves.
+func resolveRelations(tables []*Table, pkgPath string) {
+	byName := make(map[string]*Table, len(tables))
+	sorted := make([]*Table, len(tables))
+	copy(sorted, tables)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, tb := range sorted {
+		byName[tb.Name] = tb
+	}
+
+	// Forward relations
+	for _, tb := range sorted {
+		for _, col := range tb.Columns {
+			if !col.Tag.RelFk {
+				continue
+			}
+			fk := tb.Fk[col.Tag.Column]
+			ref, ok := byName[fk.RefTable]
+			if !ok || ref.Pk == "" {
+				// 被引用的表不会生成（或没有单列主键），保留为普通列
+				beeLogger.Log.Warnf("Table '%s' references '%s' which is not generated, '%s' is kept as a plain column",
+					tb.Name, fk.RefTable, col.Tag.Column)
+				*col = *col.plain
+				continue
+			}
+			col.Name = relationFieldName(tb, col)
+			col.Type = "*" + ref.Model
+			if tb.isUnique(col.Tag.Column) {
+				col.Tag.RelFk = false
+				col.Tag.RelOne = true
+			}
+		}
+	}
+
+	// Many-to-many relations through join tables
+	joins := map[string]bool{}
+	for _, tb := range sorted {
+		left, right, ok := tb.joinColumns()
+		if !ok {
+			continue
+		}
+		a, b := byName[tb.Fk[left.Tag.Column].RefTable], byName[tb.Fk[right.Tag.Column].RefTable]
+		tag := &OrmTag{RelM2M: true}
+		if tb.Pk != "" {
+			tag.RelThrough = fmt.Sprintf("%s/models.%s", pkgPath, tb.Model)
+		} else {
+			// 没有单列主键的中间表不会注册为模型，由 ORM 直接使用该表
+			tag.RelTable = tb.Name
+			beeLogger.Log.Hintf("Join table '%s' has no single column primary key, its columns must be named '%s_id' and '%s_id'",
+				tb.Name, strings.ToLower(a.Model), strings.ToLower(b.Model))
+		}
+		a.addRelation(pluralize(b.Model), "[]*"+b.Model, tag)
+		b.addRelation(pluralize(a.Model), "[]*"+a.Model, &OrmTag{ReverseMany: true})
+		joins[tb.Name] = true
+	}
+
+	// Reverse relations
+	for _, tb := range sorted {
+		if tb.Pk == "" || joins[tb.Name] {
+			continue
+		}
+		refs := map[string]int{}
+		for _, col := range tb.Columns {
+			if col.Tag.RelFk || col.Tag.RelOne {
+				refs[tb.Fk[col.Tag.Column].RefTable]++
+			}
+		}
+		for _, col := range tb.Columns {
+			if !col.Tag.RelFk && !col.Tag.RelOne {
+				continue
+			}
+			ref := byName[tb.Fk[col.Tag.Column].RefTable]
+			if refs[ref.Name] > 1 {
+				beeLogger.Log.Warnf("Table '%s' references '%s' more than once, no reverse relation is generated on '%s'",
+					tb.Name, ref.Name, ref.Model)
+				continue
+			}
+			structName := tb.Model
+			if col.Tag.RelOne {
+				ref.addRelation(structName, "*"+structName, &OrmTag{ReverseOne: true})
+			} else {
+				ref.addRelation(pluralize(structName), "[]*"+structName, &OrmTag{ReverseMany: true})
+			}
+		}
+	}
+}
+
+// relationFieldName names the field of a foreign key column after the column
+// without its _id suffix, e.g. author_id => Author, unless it has a custom name
+func relationFieldName(tb *Table, col *Column) string {
+	column := col.Tag.Column
+	if _, ok := tb.Fields[column]; ok || !strings.HasSuffix(strings.ToLower(column), "_id") {
+		return tb.fieldName(column)
+	}
+	name := utils.CamelCase(column[:len(column)-3])
+	if tb.hasField(name, col) {
+		return tb.fieldName(column)
+	}
+	return name
+}
+
+// isUnique reports whether the column has a unique constraint
+func (tb *Table) isUnique(column string) bool {
+	for _, uk := range tb.Uk {
+		if uk == column {
+			return true
+		}
+	}
+	return false
+}
+
+// hasField reports whether a field other than except is named name
+func (tb *Table) hasField(name string, except *Column) bool {
+	for _, col := range tb.Columns {
+		if col != except && col.Name == name {
+			return true
+		}
+	}
+	for _, col := range tb.Relations {
+		if col != except && col.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addRelation adds a relation field, renaming it when it collides with another field
+func (tb *Table) addRelation(name, typ string, tag *OrmTag) {
+	if tb.hasField(name, nil) {
+		name += "Set"
+	}
+	tb.Relations = append(tb.Relations, &Column{Name: name, Type: typ, Tag: tag})
+}
+
+// joinColumns returns the two foreign key columns of a join table. A join
+// table references two tables, and has no other columns than its primary
+// key and temporal columns such as created_at.
+func (tb *Table) joinColumns() (left, right *Column, ok bool) {
+	var fks []*Column
+	for _, col := range tb.Columns {
+		switch {
+		case col.Tag.RelFk || col.Tag.RelOne:
+			fks = append(fks, col)
+		case tb.Pk != "" && col.Tag.Column == tb.Pk:
+		case col.Tag.AutoNow || col.Tag.AutoNowAdd:
+		default:
+			return nil, nil, false
+		}
+	}
+	if len(fks) != 2 || tb.Fk[fks[0].Tag.Column].RefTable == tb.Fk[fks[1].Tag.Column].RefTable {
+		return nil, nil, false
+	}
+	return fks[0], fks[1], true
+}
+
+// relationFields returns the relation fields of the table, keyed by the
+// name accepted by the ?expand= parameter of the generated controllers.
+func (tb *Table) relationFields() map[string]string {
+	fields := map[string]string{}
+	for _, col := range tb.Columns {
+		if col.Tag.RelFk || col.Tag.RelOne {
+			fields[utils.SnakeString(col.Name)] = col.Name
+		}
+	}
+	for _, col := range tb.Relations {
+		fields[utils.SnakeString(col.Name)] = col.Name
+	}
+	return fields
+}
+
+// relationsCode returns the entries of the relations map of the generated model
+func (tb *Table) relationsCode() string {
+	fields := tb.relationFields()
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\t%q: %q,\n", key, fields[key])
+	}
+	return b.String()
+}
+
+// pluralize returns the plural form of an English noun, e.g. Category => Categories.
+// Words already ending with an s are returned unchanged.
+func pluralize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case s == "" || strings.HasSuffix(lower, "s"):
+		return s
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsAny(lower[len(lower)-2:len(lower)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"), strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
@@ -0,0 +1,158 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"testing"
+
+	"github.com/beego/bee/v2/utils"
+)
+
+// fkColumn returns the column of a foreign key as read by GetColumns
+func fkColumn(tb *Table, column, refTable string, plain *Column) *Column {
+	tb.Fk[column] = &ForeignKey{Name: column, RefTable: refTable, RefColumn: "id"}
+	plain.Tag.Column = column
+	return &Column{
+		Name:  tb.fieldName(column),
+		Type:  "*" + utils.CamelCase(refTable),
+		Tag:   &OrmTag{Column: column, RelFk: true},
+		plain: plain,
+	}
+}
+
+func idColumn() *Column {
+	return &Column{Name: "Id", Type: "int", Tag: &OrmTag{Column: "id", Auto: true}}
+}
+
+func TestResolveRelations(t *testing.T) {
+	user := &Table{Name: "user", Model: "User", Pk: "id", Fk: map[string]*ForeignKey{}}
+	user.Columns = []*Column{idColumn()}
+
+	profile := &Table{Name: "profile", Model: "Profile", Pk: "id", Uk: []string{"user_id"}, Fk: map[string]*ForeignKey{}}
+	profile.Columns = []*Column{
+		idColumn(),
+		fkColumn(profile, "user_id", "user", &Column{Name: "UserId", Type: "int", Tag: &OrmTag{}}),
+	}
+
+	post := &Table{Name: "post", Model: "Post", Pk: "id", Fk: map[string]*ForeignKey{}}
+	post.Columns = []*Column{
+		idColumn(),
+		fkColumn(post, "author_id", "user", &Column{Name: "AuthorId", Type: "int", Tag: &OrmTag{}}),
+		// the categories are not generated
+		fkColumn(post, "category_id", "category", &Column{Name: "CategoryId", Type: "uint64", Tag: &OrmTag{Null: true}}),
+		fkColumn(post, "country_code", "country", &Column{Name: "CountryCode", Type: "string", Tag: &OrmTag{Size: "2"}}),
+	}
+
+	tag := &Table{Name: "tag", Model: "Tag", Pk: "id", Fk: map[string]*ForeignKey{}}
+	tag.Columns = []*Column{idColumn()}
+
+	// a join table without primary key
+	postTag := &Table{Name: "post_tag", Model: "PostTag", Fk: map[string]*ForeignKey{}}
+	postTag.Columns = []*Column{
+		fkColumn(postTag, "post_id", "post", &Column{Name: "PostId", Type: "int", Tag: &OrmTag{}}),
+		fkColumn(postTag, "tag_id", "tag", &Column{Name: "TagId", Type: "int", Tag: &OrmTag{}}),
+	}
+
+	// a table referencing the same table twice
+	message := &Table{Name: "message", Model: "Message", Pk: "id", Fk: map[string]*ForeignKey{}}
+	message.Columns = []*Column{
+		idColumn(),
+		fkColumn(message, "from_id", "user", &Column{Name: "FromId", Type: "int", Tag: &OrmTag{}}),
+		fkColumn(message, "to_id", "user", &Column{Name: "ToId", Type: "int", Tag: &OrmTag{}}),
+	}
+
+	resolveRelations([]*Table{user, profile, post, tag, postTag, message}, "example.com/blog")
+
+	testCases := []struct {
+		table     *Table
+		expected  string
+		relations string
+	}{
+		{
+			table: user,
+			expected: "type User struct {\n" +
+				"Id int `orm:\"column(id);auto\"`\n" +
+				"Posts []*Post `orm:\"reverse(many)\"`\n" +
+				"Profile *Profile `orm:\"reverse(one)\"`\n" +
+				"}\n",
+			relations: "\t\"posts\": \"Posts\",\n\t\"profile\": \"Profile\",\n",
+		},
+		{
+			table: profile,
+			expected: "type Profile struct {\n" +
+				"Id int `orm:\"column(id);auto\"`\n" +
+				"User *User `orm:\"column(user_id);rel(one)\"`\n" +
+				"}\n",
+			relations: "\t\"user\": \"User\",\n",
+		},
+		{
+			table: post,
+			expected: "type Post struct {\n" +
+				"Id int `orm:\"column(id);auto\"`\n" +
+				"Author *User `orm:\"column(author_id);rel(fk)\"`\n" +
+				"CategoryId uint64 `orm:\"column(category_id);null\"`\n" +
+				"CountryCode string `orm:\"column(country_code);size(2)\"`\n" +
+				"Tags []*Tag `orm:\"rel(m2m);rel_table(post_tag)\"`\n" +
+				"}\n",
+			relations: "\t\"author\": \"Author\",\n\t\"tags\": \"Tags\",\n",
+		},
+		{
+			table: tag,
+			expected: "type Tag struct {\n" +
+				"Id int `orm:\"column(id);auto\"`\n" +
+				"Posts []*Post `orm:\"reverse(many)\"`\n" +
+				"}\n",
+			relations: "\t\"posts\": \"Posts\",\n",
+		},
+		{
+			table: message,
+			expected: "type Message struct {\n" +
+				"Id int `orm:\"column(id);auto\"`\n" +
+				"From *User `orm:\"column(from_id);rel(fk)\"`\n" +
+				"To *User `orm:\"column(to_id);rel(fk)\"`\n" +
+				"}\n",
+			relations: "\t\"from\": \"From\",\n\t\"to\": \"To\",\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.table.Name, func(t *testing.T) {
+			if got := tc.table.String(); got != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+			if got := tc.table.relationsCode(); got != tc.relations {
+				t.Errorf("expected the relations:\n%s\ngot:\n%s", tc.relations, got)
+			}
+		})
+	}
+}
+
+func TestRelationFieldName(t *testing.T) {
+	tb := &Table{Name: "post", Model: "Post", Fields: map[string]string{"editor_id": "EditorId"}}
+	tb.Columns = []*Column{
+		{Name: "Author", Type: "string", Tag: &OrmTag{Column: "author"}},
+		{Name: "AuthorId", Type: "*User", Tag: &OrmTag{Column: "author_id", RelFk: true}},
+		{Name: "EditorId", Type: "*User", Tag: &OrmTag{Column: "editor_id", RelFk: true}},
+		{Name: "OwnerId", Type: "*User", Tag: &OrmTag{Column: "owner_id", RelFk: true}},
+		{Name: "Parent", Type: "*Post", Tag: &OrmTag{Column: "parent", RelFk: true}},
+	}
+	// author_id collides with author, editor_id has a custom name and parent has no _id suffix
+	expected := []string{"AuthorId", "EditorId", "Owner", "Parent"}
+	for i, col := range tb.Columns[1:] {
+		if got := relationFieldName(tb, col); got != expected[i] {
+			t.Errorf("%s: expected %s, got %s", col.Tag.Column, expected[i], got)
+		}
+	}
+}
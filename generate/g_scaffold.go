@@ -1,36 +1,89 @@
 package generate
 
 import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/beego/bee/v2/cmd/commands/migrate"
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/logger/colors"
 	"github.com/beego/bee/v2/utils"
+	"golang.org/x/tools/go/ast/astutil"
 )
 
-func GenerateScaffold(sname, fields, currpath, driver, conn string) {
-	beeLogger.Log.Infof("Do you want to create a '%s' model? [Yes|No] ", sname)
+// The steps of the scaffold, which can be skipped with -skip
+const (
+	ScaffoldModel      = "model"
+	ScaffoldController = "controller"
+	ScaffoldViews      = "views"
+	ScaffoldMigration  = "migration"
+	ScaffoldMigrate    = "migrate"
+	ScaffoldRoutes     = "routes"
+)
+
+// ScaffoldSteps are the steps of the scaffold, in order
+var ScaffoldSteps = []string{ScaffoldModel, ScaffoldViews, ScaffoldController, ScaffoldMigration, ScaffoldMigrate, ScaffoldRoutes}
+
+// ScaffoldOptions controls the steps of GenerateScaffold
+type ScaffoldOptions struct {
+	Yes         bool            // run the steps without asking
+	Skip        map[string]bool // the steps not to run
+	RoutersFile string          // the file in which the routes are registered, relative to the application
+	Namespace   string          // the namespace in which the routes are registered, the first one when empty
+}
+
+// DefaultRoutersFile is the routers file of the applications created by bee new and bee api
+var DefaultRoutersFile = filepath.Join("routers", "router.go")
+
+// beegoWebPath is the import path of the beego web package
+const beegoWebPath = "github.com/beego/beego/v2/server/web"
+
+func GenerateScaffold(sname, fields, currpath, driver, conn string, opts ScaffoldOptions) {
+	// confirm asks whether to run the step, unless it is skipped or -yes is set
+	confirm := func(step, question string) bool {
+		if opts.Skip[step] {
+			beeLogger.Log.Infof("Skipping the %s", step)
+			return false
+		}
+		if step == ScaffoldMigrate && generated.DryRun {
+			// migrating changes the database
+			beeLogger.Log.Infof("Skipping the %s in dry-run mode", step)
+			return false
+		}
+		if opts.Yes {
+			return true
+		}
+		beeLogger.Log.Infof("%s [Yes|No] ", question)
+		return utils.AskForConfirmation()
+	}
 
 	// Generate the model
-	if utils.AskForConfirmation() {
+	if confirm(ScaffoldModel, fmt.Sprintf("Do you want to create a '%s' model?", sname)) {
 		GenerateModel(sname, fields, currpath)
 	}
 
-	// Generate the controller
-	beeLogger.Log.Infof("Do you want to create a '%s' controller? [Yes|No] ", sname)
-	if utils.AskForConfirmation() {
-		GenerateController(sname, currpath)
+	// Generate the views
+	if confirm(ScaffoldViews, fmt.Sprintf("Do you want to create views for this '%s' resource?", sname)) {
+		GenerateView(sname, fields, currpath)
 	}
 
-	// Generate the views
-	beeLogger.Log.Infof("Do you want to create views for this '%s' resource? [Yes|No] ", sname)
-	if utils.AskForConfirmation() {
-		GenerateView(sname, currpath)
+	// Generate the controller, which renders the views
+	if confirm(ScaffoldController, fmt.Sprintf("Do you want to create a '%s' controller?", sname)) {
+		GenerateController(sname, currpath)
 	}
 
 	// Generate a migration
-	beeLogger.Log.Infof("Do you want to create a '%s' migration and schema for this resource? [Yes|No] ", sname)
-	if utils.AskForConfirmation() {
+	if confirm(ScaffoldMigration, fmt.Sprintf("Do you want to create a '%s' migration and schema for this resource?", sname)) {
 		upsql := ""
 		downsql := ""
 		if fields != "" {
@@ -42,9 +95,192 @@ func GenerateScaffold(sname, fields, currpath, driver, conn string) {
 	}
 
 	// Run the migration
-	beeLogger.Log.Infof("Do you want to migrate the database? [Yes|No] ")
-	if utils.AskForConfirmation() {
+	if confirm(ScaffoldMigrate, "Do you want to migrate the database?") {
 		migrate.MigrateUpdate(currpath, driver, conn, "")
 	}
-	beeLogger.Log.Successf("All done! Don't forget to add  beego.AutoPrefix(\"/%s\" ,&controllers.%sController{}) to routers/route.go\n", sname, strings.Title(sname))
+
+	// Register the routes
+	routersFile := opts.RoutersFile
+	if routersFile == "" {
+		routersFile = DefaultRoutersFile
+	}
+	if confirm(ScaffoldRoutes, fmt.Sprintf("Do you want to register the '%s' controller in %s?", sname, routersFile)) {
+		if err := registerScaffoldRoutes(sname, currpath, filepath.Join(currpath, routersFile), opts.Namespace); err != nil {
+			beeLogger.Log.Errorf("Could not register the routes: %s", err)
+		} else {
+			beeLogger.Log.Success("All done!")
+			return
+		}
+	}
+	beeLogger.Log.Successf("All done! Don't forget to register the '%s' controller in %s", sname, routersFile)
+}
+
+// registerScaffoldRoutes registers the controller of the scaffold in the
+// routers file: with NSNamespace in the namespace of the init function, or
+// in a namespace of its own when the init function declares no namespace.
+// The routes of the controller are declared by its @router annotations.
+func registerScaffoldRoutes(sname, currpath, routersFile, namespace string) error {
+	src, err := generated.ReadFile(routersFile)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, routersFile, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	// the controller package, as created by GenerateController
+	p, f := path.Split(sname)
+	ctrlPkgPath := path.Join(appPackagePath(currpath), "controllers", p)
+	ctrlType := strings.Title(f) + "Controller"
+
+	beegoName := importName(file, beegoWebPath)
+	if beegoName == "" {
+		beegoName = "beego"
+		astutil.AddNamedImport(fset, file, beegoName, beegoWebPath)
+	}
+	ctrlName := importName(file, ctrlPkgPath)
+	if ctrlName == "" {
+		ctrlName = path.Base(ctrlPkgPath)
+		astutil.AddImport(fset, file, ctrlPkgPath)
+	}
+
+	registered := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == ctrlType {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == ctrlName {
+				registered = true
+			}
+		}
+		return !registered
+	})
+	if registered {
+		beeLogger.Log.Infof("%s.%s is already registered in %s", ctrlName, ctrlType, routersFile)
+		return nil
+	}
+
+	var initFunc *ast.FuncDecl
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "init" && fn.Recv == nil && fn.Body != nil {
+			initFunc = fn
+			break
+		}
+	}
+	if initFunc == nil {
+		return fmt.Errorf("no init function in %s", routersFile)
+	}
+
+	// the new nodes have no position, except where a line break is needed
+	prefix := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote("/" + sname)}
+	ctrl := &ast.UnaryExpr{Op: token.AND, X: &ast.CompositeLit{Type: selector(ctrlName, ctrlType)}}
+	include := &ast.CallExpr{Fun: selector(beegoName, "NSInclude"), Args: []ast.Expr{ctrl}}
+	if ns := findNamespace(initFunc, beegoName, namespace); ns != nil {
+		arg := &ast.CallExpr{Fun: selector(beegoName, "NSNamespace"), Args: []ast.Expr{prefix, include}}
+		tf := fset.File(ns.Rparen)
+		if line := tf.Line(ns.Rparen); line != tf.Line(ns.Lparen) && line < tf.LineCount() {
+			// the arguments are on their own lines: the new one takes the
+			// line of the closing parenthesis, which moves to the next line
+			arg.Fun.(*ast.SelectorExpr).X.(*ast.Ident).NamePos = ns.Rparen
+			ns.Rparen = tf.LineStart(line + 1)
+		}
+		ns.Args = append(ns.Args, arg)
+		beeLogger.Log.Infof("Registering %s.%s in the namespace %s", ctrlName, ctrlType, namespaceName(ns))
+	} else if namespace != "" {
+		return fmt.Errorf("no namespace '%s' in %s", namespace, routersFile)
+	} else {
+		ns := &ast.CallExpr{Fun: selector(beegoName, "NewNamespace"), Args: []ast.Expr{prefix, include}}
+		call := &ast.CallExpr{Fun: selector(beegoName, "AddNamespace"), Args: []ast.Expr{ns}}
+		initFunc.Body.List = append(initFunc.Body.List, &ast.ExprStmt{X: call})
+		beeLogger.Log.Infof("Registering %s.%s in the namespace /%s", ctrlName, ctrlType, sname)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return err
+	}
+	if err := generated.WriteFile(routersFile, buf.Bytes()); err != nil {
+		return err
+	}
+	if !generated.DryRun {
+		// the dry run lists the diff of the routers file instead
+		w := colors.NewColorWriter(os.Stdout)
+		fmt.Fprintf(w, "\t%s%supdate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", routersFile, "\x1b[0m")
+	}
+	return nil
+}
+
+// appPackagePath returns the import path of the application in currpath: the
+// path of its module when it has a go.mod, its path in GOPATH otherwise
+func appPackagePath(currpath string) string {
+	root, modPath, err := findModule(currpath)
+	if err != nil || modPath == "" {
+		return getPackagePath(currpath)
+	}
+	abs, err := filepath.Abs(currpath)
+	if err != nil {
+		return getPackagePath(currpath)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return getPackagePath(currpath)
+	}
+	return path.Join(modPath, filepath.ToSlash(rel))
+}
+
+func selector(x, sel string) *ast.SelectorExpr {
+	return &ast.SelectorExpr{X: ast.NewIdent(x), Sel: ast.NewIdent(sel)}
+}
+
+// importName returns the name under which file imports importPath, or an empty string
+func importName(file *ast.File, importPath string) string {
+	for _, imp := range file.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err != nil || p != importPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return path.Base(importPath)
+	}
+	return ""
+}
+
+// findNamespace returns the NewNamespace call of fn with the given prefix, or the first one when prefix is empty
+func findNamespace(fn *ast.FuncDecl, beegoName, prefix string) *ast.CallExpr {
+	var found *ast.CallExpr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "NewNamespace" {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); !ok || x.Name != beegoName {
+			return true
+		}
+		if prefix == "" || namespaceName(call) == prefix {
+			found = call
+		}
+		return true
+	})
+	return found
+}
+
+// namespaceName returns the prefix of a NewNamespace call
+func namespaceName(call *ast.CallExpr) string {
+	if len(call.Args) == 0 {
+		return ""
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	name, _ := strconv.Unquote(lit.Value)
+	return name
 }
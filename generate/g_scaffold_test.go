@@ -0,0 +1,266 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisterScaffoldRoutes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		sname     string
+		namespace string
+		routers   string
+		expected  string // empty when the routers file is unchanged
+		wantErr   bool
+	}{
+		{
+			name:  "new namespace",
+			sname: "tag",
+			routers: `package routers
+
+import (
+	"myapi/controllers"
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	beego.Router("/", &controllers.MainController{})
+}
+`,
+			expected: `package routers
+
+import (
+	beego "github.com/beego/beego/v2/server/web"
+	"myapi/controllers"
+)
+
+func init() {
+	beego.Router("/", &controllers.MainController{})
+	beego.AddNamespace(beego.NewNamespace("/tag", beego.NSInclude(&controllers.TagController{})))
+}
+`,
+		},
+		{
+			name:  "existing namespace",
+			sname: "tag",
+			routers: `package routers
+
+import (
+	"myapi/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	ns := beego.NewNamespace("/v1",
+		beego.NSNamespace("/object",
+			beego.NSInclude(
+				&controllers.ObjectController{},
+			),
+		),
+	)
+	beego.AddNamespace(ns)
+}
+`,
+			expected: `package routers
+
+import (
+	"myapi/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	ns := beego.NewNamespace("/v1",
+		beego.NSNamespace("/object",
+			beego.NSInclude(
+				&controllers.ObjectController{},
+			),
+		),
+		beego.NSNamespace("/tag", beego.NSInclude(&controllers.TagController{})),
+	)
+	beego.AddNamespace(ns)
+}
+`,
+		},
+		{
+			name:      "selected namespace",
+			sname:     "tag",
+			namespace: "/v2",
+			routers: `package routers
+
+import (
+	"myapi/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	v1 := beego.NewNamespace("/v1", beego.NSInclude(&controllers.ObjectController{}))
+	v2 := beego.NewNamespace("/v2", beego.NSInclude(&controllers.ObjectController{}))
+	beego.AddNamespace(v1, v2)
+}
+`,
+			expected: `package routers
+
+import (
+	"myapi/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	v1 := beego.NewNamespace("/v1", beego.NSInclude(&controllers.ObjectController{}))
+	v2 := beego.NewNamespace("/v2", beego.NSInclude(&controllers.ObjectController{}), beego.NSNamespace("/tag", beego.NSInclude(&controllers.TagController{})))
+	beego.AddNamespace(v1, v2)
+}
+`,
+		},
+		{
+			name:      "unknown namespace",
+			sname:     "tag",
+			namespace: "/v3",
+			routers: `package routers
+
+import (
+	"myapi/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	beego.AddNamespace(beego.NewNamespace("/v1", beego.NSInclude(&controllers.ObjectController{})))
+}
+`,
+			wantErr: true,
+		},
+		{
+			name:  "named imports",
+			sname: "tag",
+			routers: `package routers
+
+import (
+	web "github.com/beego/beego/v2/server/web"
+	ctrl "myapi/controllers"
+)
+
+func init() {
+	web.AddNamespace(web.NewNamespace("/v1", web.NSInclude(&ctrl.ObjectController{})))
+}
+`,
+			expected: `package routers
+
+import (
+	web "github.com/beego/beego/v2/server/web"
+	ctrl "myapi/controllers"
+)
+
+func init() {
+	web.AddNamespace(web.NewNamespace("/v1", web.NSInclude(&ctrl.ObjectController{}), web.NSNamespace("/tag", web.NSInclude(&ctrl.TagController{}))))
+}
+`,
+		},
+		{
+			name:  "sub package",
+			sname: "admin/tag",
+			routers: `package routers
+
+import (
+	"myapi/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	beego.AddNamespace(beego.NewNamespace("/v1", beego.NSInclude(&controllers.ObjectController{})))
+}
+`,
+			expected: `package routers
+
+import (
+	"myapi/controllers"
+	"myapi/controllers/admin"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	beego.AddNamespace(beego.NewNamespace("/v1", beego.NSInclude(&controllers.ObjectController{}), beego.NSNamespace("/admin/tag", beego.NSInclude(&admin.TagController{}))))
+}
+`,
+		},
+		{
+			name:  "already registered",
+			sname: "tag",
+			routers: `package routers
+
+import (
+	"myapi/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	beego.Include(&controllers.TagController{})
+}
+`,
+		},
+		{
+			name:  "no init function",
+			sname: "tag",
+			routers: `package routers
+`,
+			wantErr: true,
+		},
+	}
+
+	// the application is outside of GOPATH, its import path comes from go.mod
+	t.Setenv("GOPATH", t.TempDir())
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			routersFile := filepath.Join(dir, "routers", "router.go")
+			if err := os.MkdirAll(filepath.Dir(routersFile), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module myapi\n\ngo 1.18\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(routersFile, []byte(tc.routers), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := registerScaffoldRoutes(tc.sname, dir, routersFile, tc.namespace)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+			content, err := os.ReadFile(routersFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := tc.expected
+			if expected == "" {
+				expected = tc.routers
+			}
+			if string(content) != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,481 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/beego/bee/v2/internal/pkg/generated"
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
+)
+
+// bee generate test 为控制器和模型生成测试：控制器的每个 @router 路由生成一行表驱动的 httptest 测试，
+// 请求的路径、查询参数和请求体来自 @Param 注释，期望的状态码来自 @Success 注释；
+// 模型生成增删改查的测试。两个包的 main_test.go 把 ORM 注册到内存中的 SQLite 数据库。
+
+// testRoute is a row of the table of a controller test
+type testRoute struct {
+	Name        string // the controller method
+	Method      string // the HTTP method
+	Path        string // the request path, with the query
+	Header      map[string]string
+	ContentType string
+	Body        string
+	Status      int
+	Skip        string // the reason to skip the row, e.g. the method is not implemented
+	rank        int    // the order of the request in the test
+}
+
+// testRouter is a route of the controller registered by the test
+type testRouter struct {
+	Path    string
+	Mapping string // e.g. get:GetOne
+}
+
+// controllerTestData is the data of test/controller_test.go.tmpl
+type controllerTestData struct {
+	PackageName    string
+	ControllerName string // the controller type, e.g. PostController
+	Routers        []testRouter
+	Routes         []testRoute
+}
+
+// testField is a required field of a model, set to a sample value by the tests
+type testField struct {
+	Name  string
+	Value string // the Go expression of the value, e.g. "test" or time.Now()
+	JSON  string // the JSON value, e.g. "test" or "2006-01-02T15:04:05Z"
+}
+
+// testRef is a foreign key of the model, whose record is inserted before the model
+type testRef struct {
+	Field  string
+	Type   string
+	Fields []testField // the required fields of the referenced model
+}
+
+// modelTestData is the data of test/model_test.go.tmpl
+type modelTestData struct {
+	PackageName string
+	ModelName   string
+	ID          string // the id returned by Add<Model> converted to the id type, e.g. id or int(id)
+	Fields      []testField
+	Refs        []testRef
+	HasTime     bool // whether a sample value is a time.Time
+	HasGetAll   bool
+	HasUpdate   bool
+	HasDelete   bool
+}
+
+// mainTestData is the data of test/main_test.go.tmpl
+type mainTestData struct {
+	PackageName string
+	AppRoot     string // the application directory, relative to the package
+}
+
+// successCode matches the status code of a @Success annotation
+var successCode = regexp.MustCompile(`^@Success\s+(\d{3})`)
+
+// pathParam matches a parameter of a route, e.g. :id or :id:int
+var pathParam = regexp.MustCompile(`:(\w+)(:\w+|\([^)]*\))?`)
+
+// GenerateTest generates the tests of the controller and of the model name
+func GenerateTest(name, currpath string) {
+	p, f := path.Split(name)
+	ctrlFile := path.Join(currpath, "controllers", p, strings.ToLower(f)+".go")
+	modelFile := path.Join(currpath, "models", p, strings.ToLower(f)+".go")
+	if !utils.IsExist(ctrlFile) && !utils.IsExist(modelFile) {
+		beeLogger.Log.Fatalf("Could not find the controller '%s' or the model '%s'", ctrlFile, modelFile)
+	}
+
+	if utils.IsExist(ctrlFile) {
+		data, err := controllerTest(ctrlFile, strings.Title(f)+"Controller")
+		if err != nil {
+			beeLogger.Log.Fatalf("Could not read the controller: %s", err)
+		}
+		beeLogger.Log.Infof("Found %d route(s) in '%s'", len(data.Routes), ctrlFile)
+		writeTestFile(currpath, ctrlFile, "test/controller_test.go.tmpl", data)
+	}
+	if utils.IsExist(modelFile) {
+		data, err := modelTest(modelFile, strings.Title(f))
+		if err != nil {
+			beeLogger.Log.Fatalf("Could not read the model: %s", err)
+		}
+		writeTestFile(currpath, modelFile, "test/model_test.go.tmpl", data)
+	}
+	beeLogger.Log.Hint("The tests use SQLite: run 'go get github.com/mattn/go-sqlite3', which needs cgo")
+}
+
+// writeTestFile writes the test of file, and the main_test.go of its package when the package has no TestMain
+func writeTestFile(currpath, file, tpl string, data interface{}) {
+	dir := filepath.Dir(file)
+	tpath := strings.TrimSuffix(file, ".go") + "_test.go"
+	files := map[string]string{tpath: renderTemplate(currpath, tpl, data)}
+	mainPath := filepath.Join(dir, "main_test.go")
+	if !hasTestMain(dir) || generated.Base(currpath, mainPath) != nil {
+		root, err := filepath.Rel(dir, currpath)
+		if err != nil {
+			beeLogger.Log.Fatalf("Could not create test file: %s", err)
+		}
+		mainData := mainTestData{PackageName: packageName(file), AppRoot: filepath.ToSlash(root)}
+		files[mainPath] = renderTemplate(currpath, "test/main_test.go.tmpl", mainData)
+	}
+	for fpath, content := range files {
+		writeGenerated(currpath, fpath, content, "test")
+	}
+}
+
+// hasTestMain reports whether a test file of dir declares TestMain
+func hasTestMain(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	for _, file := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			continue
+		}
+		if obj := f.Scope.Lookup("TestMain"); obj != nil && obj.Kind == ast.Fun {
+			return true
+		}
+	}
+	return false
+}
+
+// packageName returns the package name of a Go file
+func packageName(file string) string {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return path.Base(path.Dir(file))
+	}
+	return f.Name.Name
+}
+
+// controllerTest returns the test data of the routes of the methods of ctrlType
+func controllerTest(file, ctrlType string) (controllerTestData, error) {
+	data := controllerTestData{PackageName: packageName(file), ControllerName: ctrlType}
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+	if err != nil {
+		return data, err
+	}
+	// the routes are registered under a prefix of their own, the tests of
+	// the controllers of a package share the router of the application
+	prefix := "/" + strings.ToLower(strings.TrimSuffix(ctrlType, "Controller"))
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Doc == nil || types.ExprString(fn.Recv.List[0].Type) != "*"+ctrlType {
+			continue
+		}
+		pcs, err := parseComment(fn.Doc.List)
+		if err != nil {
+			return data, err
+		}
+		status := 200
+		for _, c := range fn.Doc.List {
+			if m := successCode.FindStringSubmatch(strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))); m != nil {
+				status, _ = strconv.Atoi(m[1])
+				break
+			}
+		}
+		for _, pc := range pcs {
+			routerPath := path.Join(prefix, pc.routerPath)
+			data.Routers = append(data.Routers, testRouter{Path: routerPath, Mapping: strings.Join(pc.methods, ",") + ":" + fn.Name.Name})
+			for _, method := range pc.methods {
+				route := newTestRoute(file, fn.Name.Name, strings.ToUpper(method), routerPath, pc)
+				route.Status = status
+				route.rank = routeRank(route.Method, fn.Name.Name, strings.Contains(pc.routerPath, ":"))
+				if len(fn.Body.List) == 0 {
+					route.Skip = fn.Name.Name + " is not implemented"
+				}
+				data.Routes = append(data.Routes, route)
+			}
+		}
+	}
+	// create the record before reading, updating and deleting it
+	sort.SliceStable(data.Routes, func(i, j int) bool {
+		return data.Routes[i].rank < data.Routes[j].rank
+	})
+	return data, nil
+}
+
+// routeRank orders the requests of the test: the creation, the reads, the updates and the deletions
+func routeRank(method, name string, hasParams bool) int {
+	switch {
+	case method == "DELETE" || strings.HasPrefix(name, "Delete"):
+		return 3
+	case method == "POST" && !hasParams:
+		return 0
+	case method == "GET" || method == "HEAD" || method == "OPTIONS":
+		return 1
+	}
+	return 2
+}
+
+// newTestRoute returns the request of a route of the controller file, with the parameters of its annotations
+func newTestRoute(file, name, method, routerPath string, pc *parsedComment) testRoute {
+	if method == "*" {
+		method = "GET"
+	}
+	r := testRoute{Name: name, Method: method}
+	datatypes := map[string]string{}
+	query, form := url.Values{}, url.Values{}
+	var names []string
+	for n := range pc.params {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		p := pc.params[n]
+		datatypes[p.name] = p.datatype
+		if !p.required && p.location != "body" {
+			continue
+		}
+		value := sampleValue(p.name, p.datatype, p.defValue)
+		switch p.location {
+		case "query":
+			query.Set(p.name, value)
+		case "formData":
+			form.Set(p.name, value)
+		case "header":
+			if r.Header == nil {
+				r.Header = map[string]string{}
+			}
+			r.Header[p.name] = value
+		case "body":
+			r.ContentType, r.Body = "application/json", sampleBody(file, p.datatype)
+		}
+	}
+	r.Path = pathParam.ReplaceAllStringFunc(routerPath, func(s string) string {
+		n := pathParam.FindStringSubmatch(s)[1]
+		return sampleValue(n, datatypes[n], "")
+	})
+	if len(query) > 0 {
+		r.Path += "?" + query.Encode()
+	}
+	if r.Body == "" && (len(form) > 0 || method == "POST" || method == "PUT" || method == "PATCH") {
+		r.ContentType, r.Body = "application/x-www-form-urlencoded", form.Encode()
+	}
+	return r
+}
+
+// sampleValue returns the value of a parameter in the requests of the tests
+func sampleValue(name, datatype, defValue string) string {
+	if defValue != "" {
+		return defValue
+	}
+	switch strings.ToLower(datatype) {
+	case "int", "integer", "int32", "int64", "uint", "uint64", "number", "float", "float64":
+		return "1"
+	case "bool", "boolean":
+		return "true"
+	}
+	if strings.HasSuffix(strings.ToLower(name), "id") {
+		return "1"
+	}
+	return "test"
+}
+
+// modelTest returns the test data of the model modelName, declared with the
+// functions generated by bee generate model in file
+func modelTest(file, modelName string) (modelTestData, error) {
+	data := modelTestData{PackageName: packageName(file), ModelName: modelName, ID: "id"}
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+	if err != nil {
+		return data, err
+	}
+	funcs := map[string]*ast.FuncDecl{}
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+			funcs[fn.Name.Name] = fn
+		}
+	}
+	get := funcs["Get"+modelName+"ById"]
+	if funcs["Add"+modelName] == nil || get == nil {
+		return data, fmt.Errorf("no Add%s or Get%sById function in %s", modelName, modelName, file)
+	}
+	if params := get.Type.Params.List; len(params) == 1 {
+		if t := types.ExprString(params[0].Type); t != "int64" {
+			data.ID = t + "(id)"
+		}
+	}
+	if getAll := funcs["GetAll"+modelName]; getAll != nil {
+		// not the GetAll of the models generated before the list queries
+		params := getAll.Type.Params.List
+		data.HasGetAll = len(params) > 0 && types.ExprString(params[0].Type) == "ListQuery"
+	}
+	data.HasUpdate = funcs["Update"+modelName+"ById"] != nil
+	data.HasDelete = funcs["Delete"+modelName] != nil
+
+	// the required fields, and the records referenced by the required foreign keys
+	structs := modelStructs(filepath.Dir(file))
+	st := structs[modelName]
+	if st == nil {
+		return data, fmt.Errorf("no struct %s in %s", modelName, file)
+	}
+	data.Fields, data.Refs = requiredFields(st)
+	for i, ref := range data.Refs {
+		if rst := structs[ref.Type]; rst != nil {
+			data.Refs[i].Fields, _ = requiredFields(rst)
+		}
+	}
+	data.HasTime = hasTime(data.Fields)
+	for _, ref := range data.Refs {
+		data.HasTime = data.HasTime || hasTime(ref.Fields)
+	}
+	return data, nil
+}
+
+// hasTime reports whether a field is set to the current time
+func hasTime(fields []testField) bool {
+	for _, f := range fields {
+		if f.Value == "time.Now()" {
+			return true
+		}
+	}
+	return false
+}
+
+// modelStructs returns the structs declared by the package of dir, by name
+func modelStructs(dir string) map[string]*ast.StructType {
+	structs := map[string]*ast.StructType{}
+	pkgs, _ := parser.ParseDir(token.NewFileSet(), dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, obj := range f.Scope.Objects {
+				if ts, ok := obj.Decl.(*ast.TypeSpec); ok {
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						structs[ts.Name.Name] = st
+					}
+				}
+			}
+		}
+	}
+	return structs
+}
+
+// requiredFields returns the fields of a model the database needs a value of:
+// the fields not null, without a default value and not generated by the
+// database, and the foreign keys not null
+func requiredFields(st *ast.StructType) (fields []testField, refs []testRef) {
+	for _, field := range st.Fields.List {
+		if len(field.Names) != 1 || !field.Names[0].IsExported() {
+			continue
+		}
+		var orm string
+		if field.Tag != nil {
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			orm = reflect.StructTag(tag).Get("orm")
+		}
+		if orm == "-" || strings.Contains(orm, "null") || strings.Contains(orm, "auto") || strings.Contains(orm, "default(") {
+			continue
+		}
+		name := field.Names[0].Name
+		if star, ok := field.Type.(*ast.StarExpr); ok {
+			if strings.Contains(orm, "rel(fk)") || strings.Contains(orm, "rel(one)") {
+				refs = append(refs, testRef{Field: name, Type: types.ExprString(star.X)})
+			}
+			continue
+		}
+		if strings.Contains(orm, "pk") {
+			continue
+		}
+		var value, js string
+		switch t := types.ExprString(field.Type); t {
+		case "string":
+			value = strconv.Quote(sampleValue(name, t, ""))
+			js = value
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			value, js = "1", "1"
+		case "float32", "float64":
+			value, js = "1.5", "1.5"
+		case "bool":
+			value, js = "true", "true"
+		case "time.Time":
+			value, js = "time.Now()", `"2006-01-02T15:04:05Z"`
+		default:
+			continue
+		}
+		fields = append(fields, testField{Name: name, Value: value, JSON: js})
+	}
+	return fields, refs
+}
+
+// sampleBody returns the JSON body of the model datatype, e.g. models.Post, imported by the controller file:
+// the required fields of the model, and the id of the records referenced by its required foreign keys
+func sampleBody(file, datatype string) string {
+	dir := modelDir(file, datatype)
+	if dir == "" {
+		return "{}"
+	}
+	structs := modelStructs(dir)
+	st := structs[datatype[strings.LastIndex(datatype, ".")+1:]]
+	if st == nil {
+		return "{}"
+	}
+	fields, refs := requiredFields(st)
+	var members []string
+	for _, f := range fields {
+		members = append(members, strconv.Quote(f.Name)+":"+f.JSON)
+	}
+	for _, ref := range refs {
+		members = append(members, strconv.Quote(ref.Field)+`:{"Id":1}`)
+	}
+	return "{" + strings.Join(members, ",") + "}"
+}
+
+// modelDir returns the directory of the package of datatype imported by file, empty if it is not in the module of file
+func modelDir(file, datatype string) string {
+	i := strings.LastIndex(datatype, ".")
+	if i < 0 {
+		return ""
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+	if err != nil {
+		return ""
+	}
+	root, modPath, err := findModule(filepath.Dir(file))
+	if err != nil {
+		return ""
+	}
+	for _, imp := range f.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		name := path.Base(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name != datatype[:i] {
+			continue
+		}
+		// the GOPATH workspaces have no module path
+		if modPath == "" || importPath == modPath || strings.HasPrefix(importPath, modPath+"/") {
+			return filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(importPath, modPath)))
+		}
+	}
+	return ""
+}
@@ -16,60 +16,191 @@ package generate
 
 import (
 	"fmt"
-	"os"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	beeLogger "github.com/beego/bee/v2/logger"
-	"github.com/beego/bee/v2/logger/colors"
 	"github.com/beego/bee/v2/utils"
 )
 
-// recipe
-// admin/recipe
-func GenerateView(viewpath, currpath string) {
-	w := colors.NewColorWriter(os.Stdout)
+// 视图和渲染视图的控制器都需要模型的字段：来自 -fields，或者解析已有的模型文件。
+// 每个字段根据类型选择表单中的输入控件。
 
-	beeLogger.Log.Info("Generating view...")
+// viewField is a field of the model shown by the views
+type viewField struct {
+	Name    string // the name of the struct field, also the name of the form input
+	Label   string
+	GoType  string
+	Input   string   // text, textarea, number, checkbox, datetime-local or select
+	Step    string   // the step of the number inputs: 1, any, or 0.01 for decimal(10,2)
+	Options []string // the options of the select inputs, the values of an enum
+}
 
-	absViewPath := path.Join(currpath, "views", viewpath)
-	err := os.MkdirAll(absViewPath, os.ModePerm)
+// viewData is the data of the view templates
+type viewData struct {
+	ViewPath       string
+	File           string
+	ModelName      string
+	ControllerName string
+	Fields         []viewField
+}
+
+// decimalsTag matches the decimals of an orm tag
+var decimalsTag = regexp.MustCompile(`decimals\((\d+)\)`)
+
+// newViewField returns the field of the views for a struct field of type
+// goType, ok is false when the type has no form input
+func newViewField(name, goType, ormTag string) (viewField, bool) {
+	f := viewField{Name: name, Label: fieldLabel(name), GoType: goType}
+	switch goType {
+	case "string":
+		f.Input = "text"
+		for _, t := range []string{"type(longtext)", "type(text)", "type(json)", "type(jsonb)"} {
+			if strings.Contains(ormTag, t) {
+				f.Input = "textarea"
+			}
+		}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		f.Input, f.Step = "number", "1"
+	case "float32", "float64":
+		f.Input, f.Step = "number", "any"
+		if m := decimalsTag.FindStringSubmatch(ormTag); m != nil {
+			// the step of decimals(2) is 0.01
+			if n, _ := strconv.Atoi(m[1]); n > 0 {
+				f.Step = "0." + strings.Repeat("0", n-1) + "1"
+			} else {
+				f.Step = "1"
+			}
+		}
+	case "bool":
+		f.Input = "checkbox"
+	case "time.Time":
+		f.Input = "datetime-local"
+	default:
+		return f, false
+	}
+	return f, true
+}
+
+// fieldLabel returns the label of a field, e.g. Created At for CreatedAt
+func fieldLabel(name string) string {
+	words := strings.Split(utils.SnakeString(name), "_")
+	for i, w := range words {
+		words[i] = strings.Title(w)
+	}
+	return strings.Join(words, " ")
+}
+
+// fieldsFromSpec returns the fields of the views described by -fields
+func fieldsFromSpec(fields string) ([]viewField, error) {
+	fds, err := parseFields(fields)
 	if err != nil {
-		beeLogger.Log.Fatalf("Could not create '%s' view: %s", viewpath, err)
+		return nil, err
+	}
+	var vfs []viewField
+	for _, f := range fds {
+		if f.goName() == "Id" || f.Type == "auto" || f.Type == "pk" {
+			continue
+		}
+		if vf, ok := newViewField(f.goName(), f.goType(), f.ormTag()); ok {
+			if f.Type == "enum" {
+				vf.Input, vf.Options = "select", f.Args
+			}
+			vfs = append(vfs, vf)
+		}
+	}
+	return vfs, nil
+}
+
+// fieldsFromModel returns the fields of the views of the struct modelName
+// declared in the model file, without the primary key and the relations
+func fieldsFromModel(file, modelName string) ([]viewField, error) {
+	src, err := generated.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), file, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	var st *ast.StructType
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == modelName {
+			st, _ = ts.Type.(*ast.StructType)
+		}
+		return st == nil
+	})
+	if st == nil {
+		return nil, fmt.Errorf("no struct %s in %s", modelName, file)
 	}
+	var vfs []viewField
+	for _, field := range st.Fields.List {
+		ormTag := ""
+		if field.Tag != nil {
+			if tag, err := strconv.Unquote(field.Tag.Value); err == nil {
+				ormTag = reflect.StructTag(tag).Get("orm")
+			}
+		}
+		if ormTag == "-" || strings.Contains(ormTag, "auto") || strings.Contains(ormTag, "pk") {
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Name == "Id" || !name.IsExported() {
+				continue
+			}
+			if vf, ok := newViewField(name.Name, types.ExprString(field.Type), `orm:"`+ormTag+`"`); ok {
+				vfs = append(vfs, vf)
+			}
+		}
+	}
+	return vfs, nil
+}
 
-	cfile := path.Join(absViewPath, "index.tpl")
-	if f, err := os.OpenFile(cfile, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0666); err == nil {
-		defer utils.CloseFile(f)
-		f.WriteString(cfile)
-		fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", cfile, "\x1b[0m")
-	} else {
-		beeLogger.Log.Fatalf("Could not create view file: %s", err)
+// modelFields returns the fields of the model name, from -fields when it
+// is set, from the model file generated by bee generate model otherwise
+func modelFields(name, fields, currpath string) ([]viewField, error) {
+	if fields != "" {
+		return fieldsFromSpec(fields)
 	}
+	p, f := path.Split(name)
+	file := path.Join(currpath, "models", p, strings.ToLower(f)+".go")
+	if !generated.Exists(file) {
+		return nil, fmt.Errorf("no -fields and no model file '%s'", file)
+	}
+	return fieldsFromModel(file, strings.Title(f))
+}
 
-	cfile = path.Join(absViewPath, "show.tpl")
-	if f, err := os.OpenFile(cfile, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0666); err == nil {
-		defer utils.CloseFile(f)
-		f.WriteString(cfile)
-		fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", cfile, "\x1b[0m")
-	} else {
-		beeLogger.Log.Fatalf("Could not create view file: %s", err)
+// recipe
+// admin/recipe
+func GenerateView(viewpath, fields, currpath string) {
+
+	beeLogger.Log.Info("Generating view...")
+
+	_, f := path.Split(viewpath)
+	data := viewData{ViewPath: viewpath, ModelName: strings.Title(f), ControllerName: strings.Title(f)}
+	vfs, err := modelFields(viewpath, fields, currpath)
+	if err != nil {
+		beeLogger.Log.Warnf("Generating the views without the model fields: %s", err)
 	}
+	data.Fields = vfs
 
-	cfile = path.Join(absViewPath, "create.tpl")
-	if f, err := os.OpenFile(cfile, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0666); err == nil {
-		defer utils.CloseFile(f)
-		f.WriteString(cfile)
-		fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", cfile, "\x1b[0m")
-	} else {
-		beeLogger.Log.Fatalf("Could not create view file: %s", err)
+	absViewPath := path.Join(currpath, "views", viewpath)
+	err = generated.MkdirAll(absViewPath)
+	if err != nil {
+		beeLogger.Log.Fatalf("Could not create '%s' view: %s", viewpath, err)
 	}
 
-	cfile = path.Join(absViewPath, "edit.tpl")
-	if f, err := os.OpenFile(cfile, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0666); err == nil {
-		defer utils.CloseFile(f)
-		f.WriteString(cfile)
-		fmt.Fprintf(w, "\t%s%screate%s\t %s%s\n", "\x1b[32m", "\x1b[1m", "\x1b[21m", cfile, "\x1b[0m")
-	} else {
-		beeLogger.Log.Fatalf("Could not create view file: %s", err)
+	for _, name := range []string{"index", "show", "create", "edit"} {
+		cfile := path.Join(absViewPath, name+".tpl")
+		data.File = cfile
+		writeGenerated(currpath, cfile, renderTemplate(currpath, "view/"+name+".tpl.tmpl", data), "view")
 	}
 }
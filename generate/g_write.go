@@ -0,0 +1,59 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+
+	"github.com/beego/bee/v2/internal/pkg/generated"
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/logger/colors"
+)
+
+// actionColors are the colors of the actions of generated.Write
+var actionColors = map[string]string{
+	generated.Create:    "\x1b[32m",
+	generated.Identical: "\x1b[34m",
+	generated.Update:    "\x1b[33m",
+	generated.Merged:    "\x1b[33m",
+	generated.Conflict:  "\x1b[31m",
+}
+
+// writeGenerated writes the generated content of fpath, merging it with the
+// changes made by hand when the file was generated before. The Go sources are
+// formatted first, so that the kept generated version matches the file.
+func writeGenerated(currpath, fpath, content, kind string) {
+	w := colors.NewColorWriter(os.Stdout)
+	src := []byte(content)
+	if filepath.Ext(fpath) == ".go" {
+		if formatted, err := format.Source(src); err == nil {
+			src = formatted
+		}
+	}
+	result, err := generated.Write(currpath, fpath, src)
+	if err == generated.ErrNoBase {
+		beeLogger.Log.Fatalf("Could not create %s file: '%s' already exists and was not generated by bee", kind, fpath)
+	} else if err != nil {
+		beeLogger.Log.Fatalf("Could not create %s file: %s", kind, err)
+	}
+	fmt.Fprintf(w, "\t%s%s%s%s\t %s%s\n", actionColors[result.Action], "\x1b[1m", result.Action, "\x1b[21m", fpath, "\x1b[0m")
+	if result.Conflicts > 0 {
+		beeLogger.Log.Warnf("'%s' has %d conflict(s) between your changes and the generated code, resolve them between the '%s' and '%s' markers",
+			fpath, result.Conflicts, generated.MarkerMine, generated.MarkerTheirs)
+	}
+}
@@ -18,8 +18,9 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -27,12 +28,10 @@ import (
 	"unicode"
 
 	"github.com/beego/beego/v2/server/web"
-	"golang.org/x/tools/go/packages"
 
 	"github.com/beego/beego/v2/core/logs"
 
-	"github.com/beego/beego/v2/server/web/context/param"
-
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	beeLogger "github.com/beego/bee/v2/logger"
 )
 
@@ -91,34 +90,110 @@ func genRouters(ctrlPath string, routersPath string) {
 }
 
 func parserPkg(ctrlPath string, routersPath string) error {
-	genInfoList = make(map[string][]web.ControllerComments)
-	pkgs, err := packages.Load(&packages.Config{
-		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedSyntax,
-		Dir:  ctrlPath,
-	}, "./...")
+	if err := parseControllers(ctrlPath, true); err != nil {
+		return err
+	}
+	return genRouterCode(routersPath)
+}
 
+// ControllerFilter is a filter declared with @Filter on a controller method
+type ControllerFilter struct {
+	Pattern string
+	Pos     string // e.g. beego.BeforeExec
+	Filter  string
+}
+
+// ControllerFilters returns the filters declared in the controllers of
+// ctrlPath, by controller ("package path:type name") and method. It reads the
+// cache of the routers but does not write it.
+func ControllerFilters(ctrlPath string) (map[string]map[string][]ControllerFilter, error) {
+	if err := parseControllers(ctrlPath, false); err != nil {
+		return nil, err
+	}
+	filters := map[string]map[string][]ControllerFilter{}
+	for key, cList := range genInfoList {
+		for _, c := range cList {
+			for _, f := range c.FilterComments {
+				if filters[key] == nil {
+					filters[key] = map[string][]ControllerFilter{}
+				}
+				filters[key][c.Method] = append(filters[key][c.Method], ControllerFilter{
+					Pattern: f.Pattern,
+					Pos:     routerHooksMapping[f.Pos],
+					Filter:  f.Filter,
+				})
+			}
+		}
+	}
+	return filters, nil
+}
+
+// parseControllers parses the annotations of the controllers of ctrlPath into
+// genInfoList, and updates the cache of the routers when writeCache is true
+func parseControllers(ctrlPath string, writeCache bool) error {
+	genInfoList = make(map[string][]web.ControllerComments)
+	files, root, err := controllerFiles(ctrlPath)
 	if err != nil {
 		return err
 	}
-	for _, pkg := range pkgs {
-		for _, fl := range pkg.Syntax {
-			for _, d := range fl.Decls {
-				switch specDecl := d.(type) {
-				case *ast.FuncDecl:
-					if specDecl.Recv != nil {
-						exp, ok := specDecl.Recv.List[0].Type.(*ast.StarExpr) // Check that the type is correct first beforing throwing to parser
-						if ok {
-							err = parserComments(specDecl, fmt.Sprint(exp.X), pkg.PkgPath)
-							if err != nil {
-								return err
-							}
-						}
+
+	// 只重新解析内容发生变化的文件，其余文件的注释从缓存中读取
+	cache := loadRoutersCache(root, ctrlPath)
+	next := newRoutersCache(ctrlPath)
+	parsed := 0
+	for _, cf := range files {
+		data, err := os.ReadFile(cf.path)
+		if err != nil {
+			return err
+		}
+		hash := contentHash(data)
+		entry, ok := cache.Files[cf.path]
+		if !ok || entry.Hash != hash || entry.PkgPath != cf.pkgPath {
+			entry, err = parseControllerFile(cf, data)
+			if err != nil {
+				return err
+			}
+			entry.Hash = hash
+			parsed++
+		}
+		next.Files[cf.path] = entry
+		for _, r := range entry.Routes {
+			genInfoList[r.Controller] = append(genInfoList[r.Controller], r.controllerComments())
+		}
+	}
+	beeLogger.Log.Infof("Parsed %d of %d controller files", parsed, len(files))
+	if !writeCache {
+		return nil
+	}
+	if err := next.save(root); err != nil {
+		beeLogger.Log.Warnf("Could not write the routers cache: %s", err)
+	}
+	return nil
+}
+
+// parseControllerFile parses the routes of the controller methods of a file
+func parseControllerFile(cf controllerFile, data []byte) (*cachedFile, error) {
+	fl, err := parser.ParseFile(token.NewFileSet(), cf.path, data, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	entry := &cachedFile{PkgPath: cf.pkgPath}
+	for _, d := range fl.Decls {
+		switch specDecl := d.(type) {
+		case *ast.FuncDecl:
+			if specDecl.Recv != nil {
+				exp, ok := specDecl.Recv.List[0].Type.(*ast.StarExpr) // Check that the type is correct first beforing throwing to parser
+				if ok {
+					routes, err := parserComments(specDecl, fmt.Sprint(exp.X), cf.pkgPath)
+					if err != nil {
+						return nil, err
 					}
+					entry.Routes = append(entry.Routes, routes...)
 				}
 			}
 		}
 	}
-	return genRouterCode(routersPath)
+	return entry, nil
 }
 
 type parsedComment struct {
@@ -149,27 +224,28 @@ type parsedParam struct {
 	required bool
 }
 
-func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
+func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) ([]*cachedRoute, error) {
+	var routes []*cachedRoute
 	if f.Doc != nil {
 		parsedComments, err := parseComment(f.Doc.List)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		for _, parsedComment := range parsedComments {
 			if parsedComment.routerPath != "" {
-				key := pkgpath + ":" + controllerName
-				cc := web.ControllerComments{}
-				cc.Method = f.Name.String()
-				cc.Router = parsedComment.routerPath
-				cc.AllowHTTPMethods = parsedComment.methods
-				cc.MethodParams = buildMethodParams(f.Type.Params.List, parsedComment)
-				cc.FilterComments = buildFilters(parsedComment.filters)
-				cc.ImportComments = buildImports(parsedComment.imports)
-				genInfoList[key] = append(genInfoList[key], cc)
+				routes = append(routes, &cachedRoute{
+					Controller:       pkgpath + ":" + controllerName,
+					Method:           f.Name.String(),
+					Router:           parsedComment.routerPath,
+					AllowHTTPMethods: parsedComment.methods,
+					MethodParams:     buildMethodParams(f.Type.Params.List, parsedComment),
+					Filters:          buildFilters(parsedComment.filters),
+					Imports:          buildImports(parsedComment.imports),
+				})
 			}
 		}
 	}
-	return nil
+	return routes, nil
 }
 
 func buildImports(pis []parsedImport) []*web.ControllerImportComments {
@@ -214,42 +290,32 @@ func buildFilters(pfs []parsedFilter) []*web.ControllerFilterComments {
 	return filterComments
 }
 
-func buildMethodParams(funcParams []*ast.Field, pc *parsedComment) []*param.MethodParam {
-	result := make([]*param.MethodParam, 0, len(funcParams))
+func buildMethodParams(funcParams []*ast.Field, pc *parsedComment) []cachedParam {
+	result := make([]cachedParam, 0, len(funcParams))
 	for _, fparam := range funcParams {
 		for _, pName := range fparam.Names {
-			methodParam := buildMethodParam(fparam, pName.Name, pc)
+			methodParam := buildMethodParam(pName.Name, pc)
 			result = append(result, methodParam)
 		}
 	}
 	return result
 }
 
-func buildMethodParam(fparam *ast.Field, name string, pc *parsedComment) *param.MethodParam {
-	options := []param.MethodParamOption{}
+func buildMethodParam(name string, pc *parsedComment) cachedParam {
 	if cparam, ok := pc.params[name]; ok {
 		// Build param from comment info
-		name = cparam.name
-		if cparam.required {
-			options = append(options, param.IsRequired)
-		}
-		switch cparam.location {
-		case "body":
-			options = append(options, param.InBody)
-		case "header":
-			options = append(options, param.InHeader)
-		case "path":
-			options = append(options, param.InPath)
-		}
-		if cparam.defValue != "" {
-			options = append(options, param.Default(cparam.defValue))
-		}
-	} else {
-		if paramInPath(name, pc.routerPath) {
-			options = append(options, param.InPath)
+		return cachedParam{
+			Name:     cparam.name,
+			Location: cparam.location,
+			Default:  cparam.defValue,
+			Required: cparam.required,
 		}
 	}
-	return param.New(name, options...)
+	p := cachedParam{Name: name}
+	if paramInPath(name, pc.routerPath) {
+		p.Location = "path"
+	}
+	return p
 }
 
 func paramInPath(name, route string) bool {
@@ -519,17 +585,6 @@ func genRouterCode(routersPath string) error {
 
 	if globalinfo != "" {
 
-		routersPathDir := filepath.Dir(routersPath)
-		err := os.MkdirAll(routersPathDir, os.ModePerm)
-		if err != nil {
-			return err
-		}
-		f, err := os.Create(routersPath)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-
 		routersDir := RouterPkg.String()
 		if len(routersDir) == 0{
 			routersDir = "routers"
@@ -540,10 +595,13 @@ func genRouterCode(routersPath string) error {
 		content := strings.Replace(globalRouterTemplate, "{{.globalinfo}}", globalinfo, -1)
 		content = strings.Replace(content, "{{.routersDir}}", routersDir, -1)
 		content = strings.Replace(content, "{{.globalimport}}", globalimport, -1)
-		_, err = f.WriteString(content)
-		if err != nil {
-			return err
+
+		// 内容没有变化时不写入，避免触发 bee run 的重新编译
+		if old, err := generated.ReadFile(routersPath); err == nil && string(old) == content {
+			beeLogger.Log.Infof("%s is up to date", routersPath)
+			return nil
 		}
+		return generated.WriteFile(routersPath, []byte(content))
 	}
 	return nil
 }
\ No newline at end of file
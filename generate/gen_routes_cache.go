@@ -0,0 +1,174 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/beego/bee/v2/internal/pkg/generated"
+	"github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context/param"
+	"golang.org/x/tools/go/packages"
+)
+
+// 控制器注释的解析结果按文件缓存在应用根目录（控制器所在模块的根目录）的 .bee/cache/routers.json 中，以文件内容的哈希判断是否需要重新解析。
+// 缓存只是加速手段，读取失败或者格式版本不同时直接忽略。
+
+// routersCacheFile is the cache of the parsed controller comments, relative to the root of the application
+var routersCacheFile = filepath.Join(".bee", "cache", "routers.json")
+
+// routersCacheVersion changes when the parsing of the comments changes
+const routersCacheVersion = 1
+
+type routersCache struct {
+	Version       int
+	ControllerDir string
+	Files         map[string]*cachedFile // file path: parsed routes
+}
+
+type cachedFile struct {
+	Hash    string
+	PkgPath string
+	Routes  []*cachedRoute
+}
+
+type cachedRoute struct {
+	Controller       string // package path:controller name
+	Method           string
+	Router           string
+	AllowHTTPMethods []string
+	MethodParams     []cachedParam
+	Filters          []*web.ControllerFilterComments
+	Imports          []*web.ControllerImportComments
+}
+
+type cachedParam struct {
+	Name     string
+	Location string // body, header, path or empty
+	Default  string
+	Required bool
+}
+
+// controllerFile is a Go file of the controller directory
+type controllerFile struct {
+	path    string
+	pkgPath string
+}
+
+func newRoutersCache(ctrlPath string) *routersCache {
+	return &routersCache{Version: routersCacheVersion, ControllerDir: ctrlPath, Files: map[string]*cachedFile{}}
+}
+
+// loadRoutersCache reads the cache of the application, it returns an empty cache when there is none
+func loadRoutersCache(root, ctrlPath string) *routersCache {
+	data, err := os.ReadFile(filepath.Join(root, routersCacheFile))
+	if err != nil {
+		return newRoutersCache(ctrlPath)
+	}
+	var cache routersCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Version != routersCacheVersion ||
+		cache.ControllerDir != ctrlPath || cache.Files == nil {
+		return newRoutersCache(ctrlPath)
+	}
+	return &cache
+}
+
+// save writes the cache under the root directory of the application
+func (c *routersCache) save(root string) error {
+	if generated.DryRun {
+		// the cache is not part of the generated code
+		return nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(root, routersCacheFile)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// controllerComments converts the cached route to the comments of the router file
+func (r *cachedRoute) controllerComments() web.ControllerComments {
+	cc := web.ControllerComments{
+		Method:           r.Method,
+		Router:           r.Router,
+		AllowHTTPMethods: r.AllowHTTPMethods,
+		FilterComments:   r.Filters,
+		ImportComments:   r.Imports,
+	}
+	cc.MethodParams = make([]*param.MethodParam, 0, len(r.MethodParams))
+	for _, p := range r.MethodParams {
+		options := []param.MethodParamOption{}
+		if p.Required {
+			options = append(options, param.IsRequired)
+		}
+		switch p.Location {
+		case "body":
+			options = append(options, param.InBody)
+		case "header":
+			options = append(options, param.InHeader)
+		case "path":
+			options = append(options, param.InPath)
+		}
+		if p.Default != "" {
+			options = append(options, param.Default(p.Default))
+		}
+		cc.MethodParams = append(cc.MethodParams, param.New(p.Name, options...))
+	}
+	return cc
+}
+
+// controllerFiles returns the Go files of the packages under ctrlPath, as
+// listed by the go command with the build tags and the GOFLAGS of the
+// environment, and the root directory of the application.
+func controllerFiles(ctrlPath string) ([]controllerFile, string, error) {
+	abs, err := filepath.Abs(ctrlPath)
+	if err != nil {
+		return nil, "", err
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedModule,
+		Dir:  abs,
+	}, "./...")
+	if err != nil {
+		return nil, "", err
+	}
+	// the application is the module of the controllers, or their parent directory in GOPATH mode
+	root := filepath.Dir(abs)
+	var files []controllerFile
+	for _, pkg := range pkgs {
+		if pkg.Module != nil && pkg.Module.Dir != "" {
+			root = pkg.Module.Dir
+		}
+		for _, f := range pkg.GoFiles {
+			files = append(files, controllerFile{path: f, pkgPath: pkg.PkgPath})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, root, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseControllersCache(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.18\n",
+		"controllers/user.go": `package controllers
+
+type UserController struct{}
+
+// @router /:id [get]
+func (u *UserController) Get() {}
+`,
+		"controllers/admin/admin.go": `package admin
+
+type AdminController struct{}
+
+// @router / [get]
+func (a *AdminController) Get() {}
+`,
+		"controllers/debug.go": `//go:build debug
+
+package controllers
+
+// @router /debug [get]
+func (u *UserController) Debug() {}
+`,
+		"controllers/user_test.go": `package controllers
+
+// @router /test [get]
+func (u *UserController) Test() {}
+`,
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the working directory is not the application
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	cwd := t.TempDir()
+	if err := os.Chdir(cwd); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOFLAGS", "-mod=mod")
+	if err := parseControllers(filepath.Join(dir, "controllers"), true); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"example.com/app/controllers:UserController":        {"/:id"},
+		"example.com/app/controllers/admin:AdminController": {"/"},
+	}
+	if len(genInfoList) != len(expected) {
+		t.Fatalf("expected the controllers %v, got %v", expected, genInfoList)
+	}
+	for key, routers := range expected {
+		cList := genInfoList[key]
+		if len(cList) != len(routers) {
+			t.Fatalf("expected the routes %v of %s, got %+v", routers, key, cList)
+		}
+		for i, c := range cList {
+			if c.Router != routers[i] {
+				t.Errorf("expected the route %s of %s, got %s", routers[i], key, c.Router)
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, routersCacheFile)); err != nil {
+		t.Errorf("expected the cache under the application: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, ".bee")); !os.IsNotExist(err) {
+		t.Errorf("expected no cache in the working directory, got %v", err)
+	}
+
+	// the build tags of GOFLAGS select the files
+	t.Setenv("GOFLAGS", "-mod=mod -tags=debug")
+	if err := parseControllers(filepath.Join(dir, "controllers"), true); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(genInfoList["example.com/app/controllers:UserController"]); n != 2 {
+		t.Errorf("expected the routes of debug.go with the debug tag, got %d routes", n)
+	}
+
+	// bee routes reads the cache without writing it
+	cache := filepath.Join(dir, routersCacheFile)
+	if err := os.Remove(cache); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ControllerFilters(filepath.Join(dir, "controllers")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cache); !os.IsNotExist(err) {
+		t.Errorf("expected no cache written by ControllerFilters, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// 生成器共用的模块查找：从目录向上查找 go.mod，没有 go.mod 时回退到 GOPATH 工作区。
+
+// findModule returns the root directory and the path of the module of dir,
+// or of the GOPATH workspace when there is no go.mod
+func findModule(dir string) (string, string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if data, err := os.ReadFile(filepath.Join(d, "go.mod")); err == nil {
+			modPath := modfile.ModulePath(data)
+			if modPath == "" {
+				return "", "", fmt.Errorf("no module path in %s", filepath.Join(d, "go.mod"))
+			}
+			return d, modPath, nil
+		}
+		if parent := filepath.Dir(d); parent == d {
+			break
+		}
+	}
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		src := filepath.Join(gopath, "src")
+		if rel, err := filepath.Rel(src, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			return src, "", nil
+		}
+	}
+	return "", "", fmt.Errorf("could not find the module of %s", dir)
+}
@@ -0,0 +1,197 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 检查模式（bee generate docs -check）下，控制器注释中的问题不会中断生成，
+// 而是被收集起来，最后带着 文件:行号 一起报告。
+
+// docsCheck collects the annotation problems in check mode, it is nil when generating the docs
+var docsCheck *annotationChecker
+
+// routeParamRegexp matches the parameters of a route such as /:id or /:id:int or /:id([0-9]+)
+var routeParamRegexp = regexp.MustCompile(`:(\w+)`)
+
+// swaggerParamRegexp matches the parameters of a swagger path such as /{id}
+var swaggerParamRegexp = regexp.MustCompile(`\{[^}]*\}`)
+
+// unknownModels holds the model types of the annotations which could not be found
+var unknownModels map[string]bool
+
+type annotationChecker struct {
+	problems    []annotationIssue
+	annotations map[string][]token.Position // controller, method and path: positions of the @router
+	routes      map[string]token.Position   // method and full path: position of the route
+}
+
+type annotationIssue struct {
+	pos     token.Position
+	message string
+}
+
+// annotationProblem records a problem of an annotation in check mode. It
+// returns false when generating the docs, the caller then handles the problem
+// as it always did.
+func annotationProblem(pos token.Position, format string, args ...interface{}) bool {
+	if docsCheck == nil {
+		return false
+	}
+	docsCheck.problems = append(docsCheck.problems, annotationIssue{pos, fmt.Sprintf(format, args...)})
+	return true
+}
+
+// annotateRoute records the position of a @router annotation of a controller
+func annotateRoute(pos token.Position, controller, method, route string) {
+	if docsCheck == nil {
+		return
+	}
+	key := controller + " " + method + " " + route
+	docsCheck.annotations[key] = append(docsCheck.annotations[key], pos)
+}
+
+// routePositions returns the positions of the @router annotations of a route
+// of a controller, or at when the route is not annotated
+func routePositions(controller, method, route string, at token.Position) []token.Position {
+	if docsCheck == nil {
+		return nil
+	}
+	if positions, ok := docsCheck.annotations[controller+" "+method+" "+route]; ok {
+		return positions
+	}
+	return []token.Position{at}
+}
+
+// checkRoute reports the routes registered twice with the same method and full
+// path, in a controller or by several controllers and namespaces. The names of
+// the parameters are ignored, /user/:id and /user/:name are the same route.
+func checkRoute(method, fullPath string, positions []token.Position) {
+	if docsCheck == nil {
+		return
+	}
+	key := method + " " + swaggerParamRegexp.ReplaceAllString(urlReplace(fullPath), "{}")
+	for _, pos := range positions {
+		if prev, ok := docsCheck.routes[key]; ok {
+			annotationProblem(pos, "duplicate route %s %s, already declared at %s", method, fullPath, relPosition(prev))
+			continue
+		}
+		docsCheck.routes[key] = pos
+	}
+}
+
+// checkPathParams reports the parameters of a route without @Param ... path, and
+// the path parameters which are not in the route
+func checkPathParams(route string, routePos token.Position, pathParams map[string]token.Position, funcParams map[string]string, controller, method string) {
+	if docsCheck == nil {
+		return
+	}
+	inRoute := map[string]bool{}
+	for _, m := range routeParamRegexp.FindAllStringSubmatch(route, -1) {
+		name := m[1]
+		inRoute[name] = true
+		if _, ok := pathParams[name]; ok {
+			continue
+		}
+		// the remaining parameters of the method are documented in the path
+		if _, ok := funcParams[name]; ok && paramInPath(name, route) {
+			continue
+		}
+		annotationProblem(routePos, "[%s.%s] route parameter :%s of %s has no @Param %s path", controller, method, name, route, name)
+	}
+	for name, pos := range pathParams {
+		if !inRoute[name] {
+			annotationProblem(pos, "[%s.%s] path parameter %s is not in the route %s", controller, method, name, route)
+		}
+	}
+}
+
+// CheckDocs validates the annotations of the controllers of the application
+// in curpath without writing the docs. It returns the problems found, as
+// "file:line: message".
+func CheckDocs(curpath string) []string {
+	docsCheck = &annotationChecker{
+		annotations: map[string][]token.Position{},
+		routes:      map[string]token.Position{},
+	}
+	defer func() { docsCheck = nil }()
+
+	analyseDocs(curpath)
+	issues := docsCheck.problems
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].pos.Filename != issues[j].pos.Filename {
+			return issues[i].pos.Filename < issues[j].pos.Filename
+		}
+		return issues[i].pos.Line < issues[j].pos.Line
+	})
+	problems := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		problems = append(problems, fmt.Sprintf("%s: %s", relPosition(issue.pos), issue.message))
+	}
+	return problems
+}
+
+// commentPosition returns the position of a line of a comment group, such as
+// a line of the package comment of the routers
+func commentPosition(fset *token.FileSet, group *ast.CommentGroup, line string) token.Position {
+	line = strings.TrimSpace(line)
+	for _, c := range group.List {
+		for i, l := range strings.Split(c.Text, "\n") {
+			l = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(l, "//"), "/*"), "*/")
+			if strings.TrimSpace(l) == line {
+				pos := fset.Position(c.Pos())
+				pos.Line += i
+				return pos
+			}
+		}
+	}
+	return fset.Position(group.Pos())
+}
+
+// relPosition returns the position relative to the working directory when possible
+func relPosition(pos token.Position) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, pos.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+			pos.Filename = rel
+		}
+	}
+	return fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+}
+
+func isHTTPMethod(method string) bool {
+	switch method {
+	case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+// isResponseCode reports whether code is an HTTP status code or default
+func isResponseCode(code string) bool {
+	if code == "default" {
+		return true
+	}
+	n, err := strconv.Atoi(code)
+	return err == nil && n >= 100 && n < 600
+}
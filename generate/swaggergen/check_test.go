@@ -0,0 +1,278 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeApp writes the files of an application of the module example.com/app
+// into a temporary directory and changes the working directory to it.
+func writeApp(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/app\n\ngo 1.18\n"
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(strings.TrimLeft(content, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+const checkRouters = `
+// @APIVersion 1.0.0
+// @Title app
+package routers
+
+import (
+	"example.com/app/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	ns := beego.NewNamespace("/v1",
+		beego.NSNamespace("/user", beego.NSInclude(&controllers.UserController{})),
+	)
+	beego.AddNamespace(ns)
+}
+`
+
+func TestCheckDocs(t *testing.T) {
+	testCases := []struct {
+		name        string
+		routers     string
+		controllers string
+		expected    []string
+	}{
+		{
+			name: "valid",
+			controllers: `
+package controllers
+
+type UserController struct{}
+
+// @Title Get
+// @Param id path int true "the id"
+// @Success 200 {string} the user
+// @Failure 404 not found
+// @Security token read
+// @router /:id [get]
+func (c *UserController) Get() {}
+`,
+		},
+		{
+			name: "malformed annotations",
+			controllers: `
+package controllers
+
+type UserController struct{}
+
+// @Param id path
+// @Success ok {string} the user
+// @Failure 40 not found
+// @Success 200 {object} models.Missing
+// @Security
+// @router /:id [fetch]
+func (c *UserController) Get() {}
+`,
+			expected: []string{
+				"controllers/controllers.go:5: [UserController.Get] @Param should have at least 4 fields: name, location, type and description, got 2",
+				`controllers/controllers.go:6: [UserController.Get] @Success should start with a status code, got "ok"`,
+				`controllers/controllers.go:7: [UserController.Get] @Failure should start with a status code, got "40"`,
+				"controllers/controllers.go:8: [UserController.Get] unknown model type models.Missing",
+				"controllers/controllers.go:9: [UserController.Get] @Security: no params for security specified",
+				"controllers/controllers.go:10: [UserController.Get] unknown HTTP method FETCH in @router",
+				"controllers/controllers.go:10: [UserController.Get] route parameter :id of /:id has no @Param id path",
+			},
+		},
+		{
+			name: "security definitions",
+			routers: `
+// @APIVersion 1.0.0
+// @SecurityDefinition token apiKey Authorization cookie
+// @SecurityDefinition oauth oauth2 https://example.com/auth device
+// @SecurityDefinition other digest
+// @SecurityDefinition basic basic "the users"
+// @Security
+package routers
+
+import (
+	"example.com/app/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	beego.Include(&controllers.UserController{})
+}
+`,
+			controllers: `
+package controllers
+
+type UserController struct{}
+
+// @router /user [get]
+func (c *UserController) Get() {}
+`,
+			expected: []string{
+				"routers/router.go:2: @SecurityDefinition: unknown in type: cookie. Possible values are `query` or `header`",
+				"routers/router.go:3: @SecurityDefinition: not enough params for oauth2: 4",
+				"routers/router.go:4: @SecurityDefinition: unknown security type: digest. Possible values are `oauth2`, `apiKey` or `basic`",
+				"routers/router.go:6: @Security: no params for security specified",
+			},
+		},
+		{
+			name: "path parameters",
+			controllers: `
+package controllers
+
+type UserController struct{}
+
+// @Param name path string true "the name"
+// @router /:id [put]
+func (c *UserController) Put() {}
+
+// @router /:id/posts [get]
+func (c *UserController) Posts(id int) {}
+`,
+			expected: []string{
+				"controllers/controllers.go:5: [UserController.Put] path parameter name is not in the route /:id",
+				"controllers/controllers.go:6: [UserController.Put] route parameter :id of /:id has no @Param id path",
+			},
+		},
+		{
+			name: "duplicate routes in a controller",
+			controllers: `
+package controllers
+
+type UserController struct{}
+
+// @Param id path int true "the id"
+// @router /:id [get]
+func (c *UserController) Get() {}
+
+// @Param name path string true "the name"
+// @router /:name [get]
+func (c *UserController) GetByName() {}
+`,
+			expected: []string{
+				"controllers/controllers.go:10: duplicate route GET /v1/user/:name, already declared at controllers/controllers.go:6",
+			},
+		},
+		{
+			name: "duplicate routes of several controllers and namespaces",
+			routers: `
+package routers
+
+import (
+	"example.com/app/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	ns := beego.NewNamespace("/v1",
+		beego.NSNamespace("/user", beego.NSInclude(&controllers.UserController{})),
+		beego.NSNamespace("/admin", beego.NSInclude(&controllers.UserController{})),
+		beego.NSNamespace("/user", beego.NSInclude(&controllers.ProfileController{})),
+	)
+	beego.AddNamespace(ns)
+	beego.Router("/v1/admin/list", &controllers.ProfileController{}, "get:List")
+}
+`,
+			controllers: `
+package controllers
+
+type UserController struct{}
+
+// @router /list [get]
+func (c *UserController) List() {}
+
+type ProfileController struct{}
+
+// @router /list [get,post]
+func (c *ProfileController) List() {}
+`,
+			expected: []string{
+				"controllers/controllers.go:10: duplicate route GET /v1/user/list, already declared at controllers/controllers.go:5",
+				"routers/router.go:16: duplicate route GET /v1/admin/list, already declared at controllers/controllers.go:5",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			routers := tc.routers
+			if routers == "" {
+				routers = checkRouters
+			}
+			dir := writeApp(t, map[string]string{
+				"routers/router.go":          routers,
+				"controllers/controllers.go": tc.controllers,
+			})
+			problems := CheckDocs(dir)
+			if len(problems) == 0 && len(tc.expected) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(problems, tc.expected) {
+				t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(tc.expected, "\n"), strings.Join(problems, "\n"))
+			}
+		})
+	}
+}
+
+func TestCheckDocsResetsUnknownModels(t *testing.T) {
+	unknownModels = map[string]bool{"models.User": true}
+	dir := writeApp(t, map[string]string{
+		"routers/router.go": checkRouters,
+		"controllers/controllers.go": `
+package controllers
+
+type UserController struct{}
+
+// @Param body body models.User true "the user"
+// @router / [post]
+func (c *UserController) Post() {}
+`,
+		"models/user.go": `
+package models
+
+type User struct {
+	Name string
+}
+`,
+	})
+	if problems := CheckDocs(dir); len(problems) != 0 {
+		t.Errorf("expected no problem, got %v", problems)
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/beego/bee/v2/internal/pkg/generated"
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/beego/v2/server/web/swagger"
+)
+
+// 根据控制器注释生成类型化的 API 客户端：先像生成文档一样解析路由和控制器，
+// 再把文档转换为 OpenAPI 3 的结构（统一了模型、请求体和响应），最后生成 Go 或 TypeScript 代码。
+// 每个命名空间（文档中的 tag）生成一个 service。
+
+const (
+	// ClientGo generates a Go client package
+	ClientGo = "go"
+	// ClientTypeScript generates a TypeScript client module
+	ClientTypeScript = "ts"
+)
+
+// clientAPI is the API as seen by a client
+type clientAPI struct {
+	Services []*clientService
+	Schemas  map[string]*Schema // definition name: schema
+	Names    map[string]string  // definition name: type name
+}
+
+type clientService struct {
+	Name       string // exported identifier, e.g. Object
+	Operations []*clientOperation
+}
+
+type clientOperation struct {
+	Name        string // exported identifier, e.g. Get
+	Method      string
+	Path        string // full path, e.g. /v1/object/{objectId}
+	Summary     string
+	Description string
+	Deprecated  bool
+	Params      []*clientParam // path, query and header parameters
+	Body        *Schema
+	BodyNeeded  bool
+	Form        []*clientParam
+	Multipart   bool
+	Response    *Schema
+}
+
+type clientParam struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	Schema      *Schema
+}
+
+// GenerateClient generates a typed client of the application in curpath
+// into the output directory, lang is either ClientGo or ClientTypeScript.
+func GenerateClient(curpath, lang, output string) {
+	if lang == "" {
+		lang = ClientGo
+	}
+	if lang != ClientGo && lang != ClientTypeScript {
+		beeLogger.Log.Fatalf("Unknown language '%s'. Must be either %s or %s", lang, ClientGo, ClientTypeScript)
+	}
+	if output == "" {
+		output = "client"
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(curpath, output)
+	}
+
+	analyseDocs(curpath)
+	api := buildClientAPI()
+	if len(api.Services) == 0 {
+		beeLogger.Log.Warnf("No annotated route found, the client has no service")
+	}
+
+	if err := generated.MkdirAll(output); err != nil {
+		beeLogger.Log.Fatalf("Could not create the directory '%s': %s", output, err)
+	}
+	switch lang {
+	case ClientGo:
+		writeGoClient(api, output)
+	case ClientTypeScript:
+		writeTSClient(api, output)
+	}
+}
+
+// buildClientAPI groups the operations of the parsed documents by namespace
+func buildClientAPI() *clientAPI {
+	var docs []*swagger.Swagger
+	if rootapiSingle {
+		docs = append(docs, &rootapiDefault)
+	} else {
+		var versions []string
+		for version := range rootapiMap {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+		for _, version := range versions {
+			docs = append(docs, rootapiMap[version])
+		}
+	}
+
+	api := &clientAPI{Schemas: map[string]*Schema{}, Names: map[string]string{}}
+	services := map[string]*clientService{}
+	for _, doc := range docs {
+		doc.Definitions = rootapiDefault.Definitions
+		oa := toOpenAPI3(doc)
+		if oa.Components != nil {
+			for name, s := range oa.Components.Schemas {
+				api.Schemas[name] = s
+			}
+		}
+		var paths []string
+		for p := range oa.Paths {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			item := oa.Paths[p]
+			for _, m := range []struct {
+				method string
+				op     *Operation
+			}{
+				{"GET", item.Get}, {"POST", item.Post}, {"PUT", item.Put}, {"PATCH", item.Patch},
+				{"DELETE", item.Delete}, {"HEAD", item.Head}, {"OPTIONS", item.Options},
+			} {
+				if m.op == nil {
+					continue
+				}
+				tag := "default"
+				if len(m.op.Tags) > 0 {
+					tag = m.op.Tags[0]
+				}
+				name := serviceName(tag)
+				svc, ok := services[name]
+				if !ok {
+					svc = &clientService{Name: name}
+					services[name] = svc
+					api.Services = append(api.Services, svc)
+				}
+				svc.Operations = append(svc.Operations, clientOp(svc, m.method, strings.TrimSuffix(doc.BasePath, "/")+p, m.op))
+			}
+		}
+	}
+	sort.Slice(api.Services, func(i, j int) bool { return api.Services[i].Name < api.Services[j].Name })
+
+	// 模型的类型名去掉包名，冲突时使用完整的名称
+	var defs []string
+	for name := range api.Schemas {
+		defs = append(defs, name)
+	}
+	sort.Strings(defs)
+	used := map[string]bool{}
+	for _, name := range defs {
+		short := name
+		if i := strings.Index(name, "."); i >= 0 && i < len(name)-1 {
+			short = name[i+1:]
+		}
+		ident := exportedIdent(short)
+		if used[ident] {
+			ident = exportedIdent(name)
+		}
+		used[ident] = true
+		api.Names[name] = ident
+	}
+	return api
+}
+
+func clientOp(svc *clientService, method, path string, op *Operation) *clientOperation {
+	name := op.OperationID
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" {
+		name = strings.ToLower(method) + " " + path
+	}
+	name = exportedIdent(name)
+	if svc.hasOperation(name) {
+		name += strings.Title(strings.ToLower(method))
+	}
+	for i, base := 2, name; svc.hasOperation(name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	out := &clientOperation{
+		Name:        name,
+		Method:      method,
+		Path:        path,
+		Summary:     op.Summary,
+		Description: strings.TrimSpace(strings.ReplaceAll(op.Description, "<br>", "")),
+		Deprecated:  op.Deprecated,
+	}
+	for _, p := range op.Parameters {
+		out.Params = append(out.Params, &clientParam{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      p.Schema,
+		})
+	}
+	// the route parameters without @Param are strings
+	for _, m := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		found := false
+		for _, p := range out.Params {
+			found = found || (p.In == "path" && p.Name == m[1])
+		}
+		if !found {
+			out.Params = append(out.Params, &clientParam{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	if body := op.RequestBody; body != nil {
+		if mt, ok := body.Content[aform]; ok {
+			out.Form, out.Multipart = formParams(mt.Schema), true
+		} else if mt, ok := body.Content[aurlencoded]; ok {
+			out.Form = formParams(mt.Schema)
+		} else {
+			for _, mt := range body.Content {
+				out.Body = mt.Schema
+				break
+			}
+			out.BodyNeeded = body.Required
+		}
+	}
+
+	var codes []string
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		for _, mt := range op.Responses[code].Content {
+			out.Response = mt.Schema
+			break
+		}
+		if out.Response != nil {
+			break
+		}
+	}
+	return out
+}
+
+func (svc *clientService) hasOperation(name string) bool {
+	for _, op := range svc.Operations {
+		if op.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func formParams(s *Schema) []*clientParam {
+	if s == nil {
+		return nil
+	}
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	var params []*clientParam
+	for _, name := range sortedProperties(s) {
+		p := s.Properties[name]
+		params = append(params, &clientParam{
+			Name:        name,
+			In:          "formData",
+			Description: p.Description,
+			Required:    required[name],
+			Schema:      p,
+		})
+	}
+	return params
+}
+
+// serviceName returns the service of a tag, such as Object for the namespace
+// /object or for the controller github.com/app/controllersObjectController
+func serviceName(tag string) string {
+	if strings.Contains(tag, "/") && strings.HasSuffix(tag, "Controller") {
+		tag = strings.TrimSuffix(controllerShortName(tag), "Controller")
+	}
+	return exportedIdent(tag)
+}
+
+// exportedIdent converts s to an exported identifier, e.g. UserList for user_list
+func exportedIdent(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteRune('X')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	return b.String()
+}
+
+// schemaType returns the type of a schema and whether null is allowed
+func schemaType(s *Schema) (string, bool) {
+	switch t := s.Type.(type) {
+	case string:
+		return t, false
+	case []string:
+		typ, nullable := "", false
+		for _, v := range t {
+			if v == "null" {
+				nullable = true
+			} else {
+				typ = v
+			}
+		}
+		return typ, nullable
+	}
+	return "", false
+}
+
+// nullableRef returns the referenced schema of a nullable reference, oneOf: [$ref, null]
+func nullableRef(s *Schema) (string, bool) {
+	if len(s.OneOf) != 2 {
+		return "", false
+	}
+	if t, _ := schemaType(s.OneOf[1]); t == "null" && s.OneOf[0].Ref != "" {
+		return s.OneOf[0].Ref, true
+	}
+	return "", false
+}
+
+func sortedProperties(s *Schema) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// refName returns the type name of a schema reference
+func (api *clientAPI) refName(ref string) string {
+	return api.Names[strings.TrimPrefix(ref, schemasRef)]
+}
+
+var pathParamRegexp = regexp.MustCompile(`\{([^}]+)\}`)
+
+// commentLines returns the non empty lines of a description
+func commentLines(s string) []string {
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// reserve renames the models named as the generated types of the client or
+// the globals of the language, e.g. a model named Error
+func (api *clientAPI) reserve(names ...string) {
+	reserved := map[string]bool{}
+	for _, name := range names {
+		reserved[name] = true
+	}
+	for _, svc := range api.Services {
+		reserved[svc.Name+"Service"] = true
+		for _, op := range svc.Operations {
+			reserved[svc.Name+op.Name+"Request"] = true
+		}
+	}
+	used := map[string]bool{}
+	for _, ident := range api.Names {
+		used[ident] = true
+	}
+	var defs []string
+	for name := range api.Names {
+		defs = append(defs, name)
+	}
+	sort.Strings(defs)
+	for _, name := range defs {
+		ident := api.Names[name]
+		if !reserved[ident] {
+			continue
+		}
+		for reserved[ident] || used[ident] {
+			ident += "Model"
+		}
+		used[ident] = true
+		api.Names[name] = ident
+	}
+}
@@ -0,0 +1,507 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
+)
+
+// goFile is a generated Go file, its imports are collected while writing the body
+type goFile struct {
+	imports map[string]bool
+	body    strings.Builder
+}
+
+func newGoFile() *goFile {
+	return &goFile{imports: map[string]bool{}}
+}
+
+func (f *goFile) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&f.body, format, args...)
+}
+
+func (f *goFile) comment(indent string, lines ...string) {
+	for _, l := range lines {
+		for _, cl := range commentLines(l) {
+			f.printf("%s// %s\n", indent, cl)
+		}
+	}
+}
+
+func (f *goFile) write(filename, pkg string) {
+	var imports []string
+	for im := range f.imports {
+		imports = append(imports, strconv.Quote(im))
+	}
+	sort.Strings(imports)
+	content := "// Code generated by bee generate client. DO NOT EDIT.\n\npackage " + pkg + "\n\n"
+	if len(imports) > 0 {
+		content += "import (\n\t" + strings.Join(imports, "\n\t") + "\n)\n\n"
+	}
+	content += f.body.String()
+	utils.WriteToFile(filename, content)
+	utils.FormatSourceCode(filename)
+	beeLogger.Log.Infof("Client file generated: %s", filename)
+}
+
+// writeGoClient writes the client package: client.go, models.go and services.go
+func writeGoClient(api *clientAPI, output string) {
+	pkg := exportedIdent(filepath.Base(output))
+	pkg = strings.ToLower(pkg[:1]) + pkg[1:]
+	api.reserve("Client", "NewClient", "Error")
+
+	var fields, inits strings.Builder
+	for _, svc := range api.Services {
+		fmt.Fprintf(&fields, "\t%s *%sService\n", svc.Name, svc.Name)
+		fmt.Fprintf(&inits, "\tc.%s = &%sService{client: c}\n", svc.Name, svc.Name)
+	}
+	runtime := newGoFile()
+	for _, im := range []string{"bytes", "context", "encoding/json", "fmt", "io", "mime/multipart", "net/http", "net/url", "strings", "time"} {
+		runtime.imports[im] = true
+	}
+	runtime.printf("%s", strings.NewReplacer("{{services}}", fields.String(), "{{serviceInits}}", inits.String()).Replace(goClientTPL))
+	runtime.write(filepath.Join(output, "client.go"), pkg)
+
+	models := newGoFile()
+	var defs []string
+	for name := range api.Schemas {
+		defs = append(defs, name)
+	}
+	sort.Slice(defs, func(i, j int) bool { return api.Names[defs[i]] < api.Names[defs[j]] })
+	for _, name := range defs {
+		s := api.Schemas[name]
+		typ := api.Names[name]
+		models.printf("// %s is the model %s\n", typ, name)
+		models.comment("", s.Description)
+		if t, _ := schemaType(s); t == astTypeObject && s.AdditionalProperties == nil {
+			models.printf("type %s struct {\n%s}\n\n", typ, api.goFields(s, models))
+		} else {
+			models.printf("type %s %s\n\n", typ, api.goType(s, models))
+		}
+	}
+	models.write(filepath.Join(output, "models.go"), pkg)
+
+	services := newGoFile()
+	for _, svc := range api.Services {
+		services.printf("// %sService calls the %s operations\n", svc.Name, svc.Name)
+		services.printf("type %sService struct {\n\tclient *Client\n}\n\n", svc.Name)
+		for _, op := range svc.Operations {
+			api.goOperation(svc, op, services)
+		}
+	}
+	services.write(filepath.Join(output, "services.go"), pkg)
+}
+
+// goOperation writes the request struct and the method of an operation
+func (api *clientAPI) goOperation(svc *clientService, op *clientOperation, f *goFile) {
+	f.imports["context"] = true
+	reqType := svc.Name + op.Name + "Request"
+	hasReq := len(op.Params) > 0 || op.Body != nil || len(op.Form) > 0
+	fieldNames := map[string]string{} // parameter: field
+	if hasReq {
+		f.printf("// %s is the request of %sService.%s\n", reqType, svc.Name, op.Name)
+		f.printf("type %s struct {\n", reqType)
+		used := map[string]bool{}
+		for _, p := range append(append([]*clientParam{}, op.Params...), op.Form...) {
+			field := exportedIdent(p.Name)
+			for i := 2; used[field] || field == "Body"; i++ {
+				field = exportedIdent(p.Name) + strconv.Itoa(i)
+			}
+			used[field] = true
+			fieldNames[p.In+":"+p.Name] = field
+			f.comment("\t", p.Description)
+			f.printf("\t%s %s\n", field, api.goParamType(p, f))
+		}
+		if op.Body != nil {
+			typ := api.goType(op.Body, f)
+			if op.Body.Ref != "" {
+				typ = "*" + typ
+			}
+			f.printf("\tBody %s\n", typ)
+		}
+		f.printf("}\n\n")
+	}
+
+	// the response
+	var respType, zero string
+	if op.Response != nil {
+		respType = api.goType(op.Response, f)
+		zero = "out"
+		if op.Response.Ref != "" {
+			respType, zero = "*"+respType, "&out"
+		}
+	}
+
+	summary := op.Summary
+	if summary == "" {
+		summary = op.Method + " " + op.Path
+	}
+	f.printf("// %s %s\n", op.Name, summary)
+	f.comment("", op.Description)
+	if op.Deprecated {
+		f.printf("//\n// Deprecated: the operation is deprecated.\n")
+	}
+	params := "ctx context.Context"
+	if hasReq {
+		params += ", req *" + reqType
+	}
+	results := "error"
+	if respType != "" {
+		results = "(" + respType + ", error)"
+	}
+	f.printf("func (s *%sService) %s(%s) %s {\n", svc.Name, op.Name, params, results)
+
+	// the path, with the path parameters
+	path := strconv.Quote(op.Path)
+	for _, m := range pathParamRegexp.FindAllStringSubmatch(op.Path, -1) {
+		if field, ok := fieldNames["path:"+m[1]]; ok {
+			f.imports["net/url"] = true
+			path = strings.Replace(path, m[0], `" + url.PathEscape(formatValue(req.`+field+`)) + "`, 1)
+		}
+	}
+	path = strings.TrimSuffix(path, ` + ""`)
+	f.printf("\tr := &request{method: %q, path: %s}\n", op.Method, path)
+
+	for _, p := range append(append([]*clientParam{}, op.Params...), op.Form...) {
+		field := "req." + fieldNames[p.In+":"+p.Name]
+		var add string
+		switch p.In {
+		case "query":
+			add = "r.addQuery"
+		case "header":
+			add = "r.addHeader"
+		case "formData":
+			add = "r.addForm"
+			if t, _ := schemaType(p.Schema); t == "string" && p.Schema.Format == "binary" {
+				f.printf("\tif %s != nil {\n\t\tr.addFile(%q, %s)\n\t}\n", field, p.Name, field)
+				continue
+			}
+		default:
+			continue
+		}
+		typ := api.goParamType(p, f)
+		switch {
+		case strings.HasPrefix(typ, "[]") && typ != "[]byte":
+			f.printf("\tfor _, v := range %s {\n\t\t%s(%q, v)\n\t}\n", field, add, p.Name)
+		case strings.HasPrefix(typ, "*"):
+			f.printf("\tif %s != nil {\n\t\t%s(%q, *%s)\n\t}\n", field, add, p.Name, field)
+		default:
+			f.printf("\t%s(%q, %s)\n", add, p.Name, field)
+		}
+	}
+	if op.Body != nil {
+		f.printf("\tr.body = req.Body\n")
+	}
+	if op.Multipart {
+		f.printf("\tr.multipart = true\n")
+	}
+
+	if respType == "" {
+		f.printf("\treturn s.client.do(ctx, r, nil)\n}\n\n")
+		return
+	}
+	f.printf("\tvar out %s\n", strings.TrimPrefix(respType, "*"))
+	f.printf("\tif err := s.client.do(ctx, r, &out); err != nil {\n\t\treturn %s, err\n\t}\n", goZero(respType))
+	f.printf("\treturn %s, nil\n}\n\n", zero)
+}
+
+// goParamType returns the type of a parameter, optional parameters are pointers
+func (api *clientAPI) goParamType(p *clientParam, f *goFile) string {
+	typ := api.goType(p.Schema, f)
+	if p.Required || strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "*") ||
+		strings.HasPrefix(typ, "map[") || typ == "interface{}" || typ == "io.Reader" {
+		return typ
+	}
+	return "*" + typ
+}
+
+// goType returns the Go type of a schema
+func (api *clientAPI) goType(s *Schema, f *goFile) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		return api.refName(s.Ref)
+	}
+	if ref, ok := nullableRef(s); ok {
+		return "*" + api.refName(ref)
+	}
+	t, nullable := schemaType(s)
+	var typ string
+	switch t {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			f.imports["time"] = true
+			typ = "time.Time"
+		case "byte":
+			return "[]byte"
+		case "binary":
+			f.imports["io"] = true
+			return "io.Reader"
+		default:
+			typ = "string"
+		}
+	case "integer":
+		typ = "int64"
+		if s.Format == "int32" {
+			typ = "int32"
+		}
+	case "number":
+		typ = "float64"
+		if s.Format == "float" {
+			typ = "float32"
+		}
+	case "boolean":
+		typ = "bool"
+	case astTypeArray:
+		return "[]" + api.goType(s.Items, f)
+	case astTypeObject:
+		if s.AdditionalProperties != nil {
+			return "map[string]" + api.goType(s.AdditionalProperties, f)
+		}
+		if len(s.Properties) > 0 {
+			typ = "struct {\n" + api.goFields(s, f) + "}"
+			break
+		}
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+	if nullable {
+		return "*" + typ
+	}
+	return typ
+}
+
+// goFields returns the fields of an object schema, with their json tags
+func (api *clientAPI) goFields(s *Schema, f *goFile) string {
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	var b strings.Builder
+	used := map[string]bool{}
+	for _, name := range sortedProperties(s) {
+		p := s.Properties[name]
+		field := exportedIdent(name)
+		for i := 2; used[field]; i++ {
+			field = exportedIdent(name) + strconv.Itoa(i)
+		}
+		used[field] = true
+		for _, l := range commentLines(p.Description) {
+			fmt.Fprintf(&b, "\t// %s\n", l)
+		}
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:%q`\n", field, api.goType(p, f), tag)
+	}
+	return b.String()
+}
+
+// goZero returns the zero value of a result type
+func goZero(typ string) string {
+	switch {
+	case strings.HasPrefix(typ, "*"), strings.HasPrefix(typ, "[]"), strings.HasPrefix(typ, "map["), typ == "interface{}":
+		return "nil"
+	case typ == "string":
+		return `""`
+	case typ == "bool":
+		return "false"
+	case strings.HasPrefix(typ, "int"), strings.HasPrefix(typ, "float"):
+		return "0"
+	}
+	return typ + "{}"
+}
+
+const goClientTPL = `// Client calls the API
+type Client struct {
+	// BaseURL is the URL of the server, such as https://api.example.com
+	BaseURL string
+	// HTTPClient sends the requests, http.DefaultClient by default
+	HTTPClient *http.Client
+	// Timeout applies to the requests whose context has no deadline, no timeout by default
+	Timeout time.Duration
+	// Header is added to every request, such as an Authorization header
+	Header http.Header
+
+{{services}}}
+
+// NewClient returns a client of the server at baseURL
+func NewClient(baseURL string) *Client {
+	c := &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Header:     http.Header{},
+	}
+{{serviceInits}}	return c
+}
+
+// Error is returned for the responses whose status code is not 2xx
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, strings.TrimSpace(string(e.Body)))
+}
+
+type request struct {
+	method    string
+	path      string
+	query     url.Values
+	header    http.Header
+	body      interface{}
+	form      url.Values
+	files     map[string]io.Reader
+	multipart bool
+}
+
+func (r *request) addQuery(name string, v interface{}) {
+	if r.query == nil {
+		r.query = url.Values{}
+	}
+	r.query.Add(name, formatValue(v))
+}
+
+func (r *request) addHeader(name string, v interface{}) {
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Add(name, formatValue(v))
+}
+
+func (r *request) addForm(name string, v interface{}) {
+	if r.form == nil {
+		r.form = url.Values{}
+	}
+	r.form.Add(name, formatValue(v))
+}
+
+func (r *request) addFile(name string, file io.Reader) {
+	if r.files == nil {
+		r.files = map[string]io.Reader{}
+	}
+	r.files[name] = file
+}
+
+// encode returns the body of the request and its content type
+func (r *request) encode() (io.Reader, string, error) {
+	switch {
+	case r.body != nil:
+		data, err := json.Marshal(r.body)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(data), "application/json", nil
+	case r.multipart:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for name, values := range r.form {
+			for _, v := range values {
+				if err := w.WriteField(name, v); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		for name, file := range r.files {
+			part, err := w.CreateFormFile(name, name)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := io.Copy(part, file); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, w.FormDataContentType(), nil
+	case r.form != nil:
+		return strings.NewReader(r.form.Encode()), "application/x-www-form-urlencoded", nil
+	}
+	return nil, "", nil
+}
+
+// do sends the request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, r *request, out interface{}) error {
+	if _, ok := ctx.Deadline(); !ok && c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	body, contentType, err := r.encode()
+	if err != nil {
+		return err
+	}
+	u := c.BaseURL + r.path
+	if len(r.query) > 0 {
+		u += "?" + r.query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, u, body)
+	if err != nil {
+		return err
+	}
+	for name, values := range c.Header {
+		req.Header[name] = values
+	}
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &Error{StatusCode: resp.StatusCode, Body: data}
+	}
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// formatValue formats a parameter value
+func formatValue(v interface{}) string {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+`
@@ -0,0 +1,334 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web/swagger"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata")
+
+// clientTestAPI sets a small parsed API: a user namespace with path, query,
+// header, body and multipart parameters, and an object namespace whose
+// model is named as a type of the client.
+func clientTestAPI(t *testing.T) {
+	t.Helper()
+	single, def, apis, nullable := rootapiSingle, rootapiDefault, rootapiMap, nullableProperties
+	t.Cleanup(func() {
+		rootapiSingle, rootapiDefault, rootapiMap, nullableProperties = single, def, apis, nullable
+	})
+
+	user := &swagger.Schema{Ref: "#/definitions/models.User"}
+	rootapiSingle = true
+	rootapiMap = map[string]*swagger.Swagger{}
+	nullableProperties = map[string]map[string]bool{"models.User": {"manager": true}}
+	rootapiDefault = swagger.Swagger{
+		BasePath: "/v1",
+		Paths: map[string]*swagger.Item{
+			"/user/": {
+				Get: &swagger.Operation{
+					Tags:        []string{"user"},
+					OperationID: "UserController.GetAll",
+					Summary:     "list the users",
+					Parameters: []swagger.Parameter{
+						{In: "query", Name: "tags", Type: "array", Items: &swagger.ParameterItems{Type: "string"}},
+						{In: "query", Name: "limit", Type: "integer", Format: "int32", Description: "the maximum number of users"},
+						{In: "query", Name: "since", Type: "string", Format: "date-time"},
+					},
+					Responses: map[string]swagger.Response{
+						"200": {Description: "the users", Schema: &swagger.Schema{Type: "array", Items: user}},
+					},
+				},
+			},
+			"/user/{uid}": {
+				Get: &swagger.Operation{
+					Tags:        []string{"user"},
+					OperationID: "UserController.Get",
+					Summary:     "get the user",
+					Description: "returns the user<br>\nof the id",
+					Parameters: []swagger.Parameter{
+						{In: "path", Name: "uid", Type: "integer", Format: "int64", Required: true},
+						{In: "header", Name: "X-Token", Type: "string"},
+					},
+					Responses: map[string]swagger.Response{
+						"200": {Description: "the user", Schema: user},
+						"404": {Description: "not found"},
+					},
+				},
+				Put: &swagger.Operation{
+					Tags:        []string{"user"},
+					OperationID: "UserController.Put",
+					Deprecated:  true,
+					Parameters: []swagger.Parameter{
+						{In: "path", Name: "uid", Type: "integer", Format: "int64", Required: true},
+						{In: "body", Name: "body", Required: true, Schema: user},
+					},
+					Responses: map[string]swagger.Response{
+						"200": {Description: "the user", Schema: user},
+					},
+				},
+				Delete: &swagger.Operation{
+					Tags:        []string{"user"},
+					OperationID: "UserController.Delete",
+					Parameters: []swagger.Parameter{
+						{In: "path", Name: "uid", Type: "integer", Format: "int64", Required: true},
+					},
+				},
+			},
+			"/user/avatar": {
+				Post: &swagger.Operation{
+					Tags:        []string{"user"},
+					OperationID: "UserController.Avatar",
+					Consumes:    []string{"multipart/form-data"},
+					Parameters: []swagger.Parameter{
+						{In: "formData", Name: "name", Type: "string", Required: true},
+						{In: "formData", Name: "file", Type: "file", Description: "the avatar"},
+					},
+				},
+			},
+			"/object/{objectId}": {
+				Get: &swagger.Operation{
+					Tags:        []string{"object"},
+					OperationID: "ObjectController.Get",
+					Responses: map[string]swagger.Response{
+						"200": {Schema: &swagger.Schema{Type: "string"}},
+						"403": {Schema: &swagger.Schema{Ref: "#/definitions/models.Error"}},
+					},
+				},
+			},
+			"/object/": {
+				Post: &swagger.Operation{
+					Tags:        []string{"object"},
+					OperationID: "ObjectController.Post",
+					Parameters: []swagger.Parameter{
+						{In: "formData", Name: "score", Type: "number", Format: "float", Required: true},
+					},
+					Responses: map[string]swagger.Response{
+						"200": {Schema: &swagger.Schema{Ref: "#/definitions/models.Error"}},
+					},
+				},
+			},
+		},
+		Definitions: map[string]swagger.Schema{
+			"models.User": {
+				Type:        "object",
+				Description: "User is a member",
+				Required:    []string{"id", "name"},
+				Properties: map[string]swagger.Propertie{
+					"id":      {Type: "integer", Format: "int64"},
+					"name":    {Type: "string", Description: "the display name"},
+					"created": {Type: "string", Format: "date-time"},
+					"manager": {Ref: "#/definitions/models.User"},
+					"tags":    {Type: "array", Items: &swagger.Propertie{Type: "string"}},
+					"scores":  {Type: "object", AdditionalProperties: &swagger.Propertie{Type: "number", Format: "double"}},
+				},
+			},
+			"models.Error": {
+				Type: "object",
+				Properties: map[string]swagger.Propertie{
+					"code":    {Type: "integer", Format: "int32"},
+					"message": {Type: "string"},
+				},
+			},
+		},
+	}
+}
+
+// checkGolden compares the generated file with testdata/client/<name>.golden,
+// or writes it with -update
+func checkGolden(t *testing.T, dir, name string) {
+	t.Helper()
+	actual, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "client", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != string(expected) {
+		t.Errorf("%s differs from %s, run go test -update to review the change:\n%s", name, golden, actual)
+	}
+}
+
+func TestGoClient(t *testing.T) {
+	clientTestAPI(t)
+	app := t.TempDir()
+	output := filepath.Join(app, "apiclient")
+	if err := os.MkdirAll(output, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeGoClient(buildClientAPI(), output)
+	for _, name := range []string{"client.go", "models.go", "services.go"} {
+		checkGolden(t, output, name)
+	}
+
+	// the client compiles, and is used as documented
+	if testing.Short() {
+		t.Skip("go vet of the generated client is skipped in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go vet of the generated client needs the go command")
+	}
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.18\n",
+		"main.go": `package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"example.com/app/apiclient"
+)
+
+func main() {
+	ctx := context.Background()
+	c := apiclient.NewClient("http://localhost:8080")
+	limit := int32(10)
+	users, err := c.User.GetAll(ctx, &apiclient.UserGetAllRequest{Tags: []string{"admin"}, Limit: &limit})
+	fmt.Println(len(users), err)
+	user, err := c.User.Get(ctx, &apiclient.UserGetRequest{Uid: 1})
+	if err == nil {
+		user.Manager = nil
+		_, err = c.User.Put(ctx, &apiclient.UserPutRequest{Uid: user.Id, Body: user})
+	}
+	fmt.Println(err, c.User.Delete(ctx, &apiclient.UserDeleteRequest{Uid: 1}))
+	fmt.Println(c.User.Avatar(ctx, &apiclient.UserAvatarRequest{Name: "me", File: strings.NewReader("png")}))
+	var e *apiclient.ErrorModel
+	e, err = c.Object.Post(ctx, &apiclient.ObjectPostRequest{Score: 1.5})
+	fmt.Println(e, err)
+	var object string
+	object, err = c.Object.Get(ctx, &apiclient.ObjectGetRequest{ObjectId: "id"})
+	fmt.Println(object, err)
+	var _ error = (*apiclient.Error)(nil)
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(app, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command("go", "vet", "./...")
+	cmd.Dir = app
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go vet of the generated client failed: %s\n%s", err, out)
+	}
+}
+
+func TestTSClient(t *testing.T) {
+	clientTestAPI(t)
+	output := t.TempDir()
+	writeTSClient(buildClientAPI(), output)
+	checkGolden(t, output, "client.ts")
+
+	src, err := os.ReadFile(filepath.Join(output, "client.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkBrackets(string(src)); err != nil {
+		t.Errorf("client.ts is malformed: %s", err)
+	}
+
+	// the TypeScript compiler is rarely installed, it is used when available
+	tsc, err := exec.LookPath("tsc")
+	if err != nil || testing.Short() {
+		return
+	}
+	cmd := exec.Command(tsc, "--noEmit", "--strict", "--target", "es2020", "--lib", "es2020,dom", "client.ts")
+	cmd.Dir = output
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("tsc failed: %s\n%s", err, out)
+	}
+}
+
+// checkBrackets checks that the brackets of a TypeScript source are balanced,
+// outside of the comments, the strings and the template literals
+func checkBrackets(src string) error {
+	closing := map[byte]byte{')': '(', ']': '[', '}': '{'}
+	var stack []byte
+	line := 1
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			line++
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := indexFrom(src, "*/", i+2)
+			if end < 0 {
+				return fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:end], "\n")
+			i = end + 1
+		case c == '"' || c == '\'' || c == '`':
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' {
+					j++
+				} else if src[j] == '\n' && c != '`' {
+					break
+				}
+			}
+			if j >= len(src) || src[j] != c {
+				return fmt.Errorf("line %d: unterminated string", line)
+			}
+			line += strings.Count(src[i:j], "\n")
+			i = j
+		case c == '(' || c == '[' || c == '{':
+			stack = append(stack, c)
+		case closing[c] != 0:
+			if len(stack) == 0 || stack[len(stack)-1] != closing[c] {
+				return fmt.Errorf("line %d: unexpected %c", line, c)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("%d unclosed brackets", len(stack))
+	}
+	return nil
+}
+
+func indexFrom(s, substr string, from int) int {
+	if i := strings.Index(s[from:], substr); i >= 0 {
+		return from + i
+	}
+	return -1
+}
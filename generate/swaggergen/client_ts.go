@@ -0,0 +1,381 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
+)
+
+// tsGlobals are the names which the models must not shadow in the module
+var tsGlobals = []string{
+	"Array", "Blob", "Boolean", "Date", "Error", "FormData", "Function", "Headers", "JSON", "Map",
+	"Number", "Object", "Promise", "Record", "Response", "Request", "RequestInit", "Set", "String",
+	"Symbol", "URL", "URLSearchParams", "AbortController", "AbortSignal",
+	"Client", "ClientOptions", "RequestOptions", "APIError", "APIRequest",
+}
+
+var tsIdentRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// writeTSClient writes the client module client.ts
+func writeTSClient(api *clientAPI, output string) {
+	api.reserve(tsGlobals...)
+	var b strings.Builder
+	b.WriteString("// Code generated by bee generate client. DO NOT EDIT.\n\n")
+
+	var defs []string
+	for name := range api.Schemas {
+		defs = append(defs, name)
+	}
+	sort.Slice(defs, func(i, j int) bool { return api.Names[defs[i]] < api.Names[defs[j]] })
+	for _, name := range defs {
+		s := api.Schemas[name]
+		typ := api.Names[name]
+		tsComment(&b, "", append([]string{typ + " is the model " + name}, commentLines(s.Description)...)...)
+		if t, _ := schemaType(s); t == astTypeObject && s.AdditionalProperties == nil {
+			fmt.Fprintf(&b, "export interface %s %s\n\n", typ, api.tsObject(s, ""))
+		} else {
+			fmt.Fprintf(&b, "export type %s = %s;\n\n", typ, api.tsType(s, ""))
+		}
+	}
+
+	for _, svc := range api.Services {
+		for _, op := range svc.Operations {
+			api.tsRequest(&b, svc, op)
+		}
+	}
+
+	var fields, inits strings.Builder
+	for _, svc := range api.Services {
+		fmt.Fprintf(&fields, "  readonly %s: %sService;\n", lowerFirst(svc.Name), svc.Name)
+		fmt.Fprintf(&inits, "    this.%s = new %sService(this);\n", lowerFirst(svc.Name), svc.Name)
+	}
+	b.WriteString(strings.NewReplacer("{{services}}", fields.String(), "{{serviceInits}}", inits.String()).Replace(tsClientTPL))
+
+	for _, svc := range api.Services {
+		fmt.Fprintf(&b, "\n/** %sService calls the %s operations */\n", svc.Name, svc.Name)
+		fmt.Fprintf(&b, "export class %sService {\n  constructor(private readonly client: Client) {}\n", svc.Name)
+		for _, op := range svc.Operations {
+			api.tsMethod(&b, svc, op)
+		}
+		b.WriteString("}\n")
+	}
+
+	filename := filepath.Join(output, "client.ts")
+	utils.WriteToFile(filename, b.String())
+	beeLogger.Log.Infof("Client file generated: %s", filename)
+}
+
+// tsRequest writes the request interface of an operation
+func (api *clientAPI) tsRequest(b *strings.Builder, svc *clientService, op *clientOperation) {
+	if len(op.Params) == 0 && op.Body == nil && len(op.Form) == 0 {
+		return
+	}
+	tsComment(b, "", svc.Name+op.Name+"Request is the request of "+svc.Name+"Service."+lowerFirst(op.Name))
+	fmt.Fprintf(b, "export interface %s%sRequest {\n", svc.Name, op.Name)
+	for _, p := range append(append([]*clientParam{}, op.Params...), op.Form...) {
+		tsComment(b, "  ", commentLines(p.Description)...)
+		opt := "?"
+		if p.Required {
+			opt = ""
+		}
+		fmt.Fprintf(b, "  %s%s: %s;\n", tsProp(p.Name), opt, api.tsType(p.Schema, "  "))
+	}
+	if op.Body != nil {
+		opt := "?"
+		if op.BodyNeeded {
+			opt = ""
+		}
+		fmt.Fprintf(b, "  body%s: %s;\n", opt, api.tsType(op.Body, "  "))
+	}
+	b.WriteString("}\n\n")
+}
+
+// tsMethod writes the method of an operation
+func (api *clientAPI) tsMethod(b *strings.Builder, svc *clientService, op *clientOperation) {
+	hasReq := len(op.Params) > 0 || op.Body != nil || len(op.Form) > 0
+	resp := "void"
+	if op.Response != nil {
+		resp = api.tsType(op.Response, "  ")
+	}
+	summary := op.Summary
+	if summary == "" {
+		summary = op.Method + " " + op.Path
+	}
+	lines := append([]string{summary}, commentLines(op.Description)...)
+	if op.Deprecated {
+		lines = append(lines, "@deprecated")
+	}
+	b.WriteString("\n")
+	tsComment(b, "  ", lines...)
+	params := "options?: RequestOptions"
+	if hasReq {
+		params = "req: " + svc.Name + op.Name + "Request, " + params
+	}
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", lowerFirst(op.Name), params, resp)
+
+	path := "`" + op.Path + "`"
+	for _, m := range pathParamRegexp.FindAllStringSubmatch(op.Path, -1) {
+		path = strings.Replace(path, m[0], "${encodeURIComponent(String(req"+tsAccess(m[1])+"))}", 1)
+	}
+	fmt.Fprintf(b, "    return this.client.request<%s>({\n      method: %q,\n      path: %s,\n", resp, op.Method, path)
+	for _, in := range []string{"query", "header"} {
+		var values []string
+		for _, p := range op.Params {
+			if p.In == in {
+				values = append(values, fmt.Sprintf("%s: req%s", tsProp(p.Name), tsAccess(p.Name)))
+			}
+		}
+		if len(values) > 0 {
+			key := in
+			if in == "header" {
+				key = "headers"
+			}
+			fmt.Fprintf(b, "      %s: { %s },\n", key, strings.Join(values, ", "))
+		}
+	}
+	if op.Body != nil {
+		b.WriteString("      body: req.body,\n")
+	}
+	if len(op.Form) > 0 {
+		var values []string
+		for _, p := range op.Form {
+			values = append(values, fmt.Sprintf("%s: req%s", tsProp(p.Name), tsAccess(p.Name)))
+		}
+		fmt.Fprintf(b, "      form: { %s },\n", strings.Join(values, ", "))
+		if op.Multipart {
+			b.WriteString("      multipart: true,\n")
+		}
+	}
+	b.WriteString("    }, options);\n  }\n")
+}
+
+// tsType returns the TypeScript type of a schema
+func (api *clientAPI) tsType(s *Schema, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+	if s.Ref != "" {
+		return api.refName(s.Ref)
+	}
+	if ref, ok := nullableRef(s); ok {
+		return api.refName(ref) + " | null"
+	}
+	t, nullable := schemaType(s)
+	var typ string
+	switch t {
+	case "string":
+		typ = "string"
+		if s.Format == "binary" {
+			typ = "Blob"
+		}
+	case "integer", "number":
+		typ = "number"
+	case "boolean":
+		typ = "boolean"
+	case astTypeArray:
+		typ = "Array<" + api.tsType(s.Items, indent) + ">"
+	case astTypeObject:
+		switch {
+		case s.AdditionalProperties != nil:
+			typ = "Record<string, " + api.tsType(s.AdditionalProperties, indent) + ">"
+		case len(s.Properties) > 0:
+			typ = api.tsObject(s, indent)
+		default:
+			typ = "Record<string, unknown>"
+		}
+	default:
+		return "unknown"
+	}
+	if nullable {
+		return typ + " | null"
+	}
+	return typ
+}
+
+// tsObject returns the properties of an object schema
+func (api *clientAPI) tsObject(s *Schema, indent string) string {
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range sortedProperties(s) {
+		p := s.Properties[name]
+		tsComment(&b, indent+"  ", commentLines(p.Description)...)
+		opt := "?"
+		if required[name] {
+			opt = ""
+		}
+		fmt.Fprintf(&b, "%s  %s%s: %s;\n", indent, tsProp(name), opt, api.tsType(p, indent+"  "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+func tsComment(b *strings.Builder, indent string, lines ...string) {
+	switch len(lines) {
+	case 0:
+	case 1:
+		fmt.Fprintf(b, "%s/** %s */\n", indent, strings.ReplaceAll(lines[0], "*/", "* /"))
+	default:
+		fmt.Fprintf(b, "%s/**\n", indent)
+		for _, l := range lines {
+			fmt.Fprintf(b, "%s * %s\n", indent, strings.ReplaceAll(l, "*/", "* /"))
+		}
+		fmt.Fprintf(b, "%s */\n", indent)
+	}
+}
+
+// tsProp returns a property name, quoted when it is not an identifier
+func tsProp(name string) string {
+	if tsIdentRegexp.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// tsAccess returns the access to a property, e.g. .id or ["x-token"]
+func tsAccess(name string) string {
+	if tsIdentRegexp.MatchString(name) {
+		return "." + name
+	}
+	return "[" + strconv.Quote(name) + "]"
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+const tsClientTPL = `export interface ClientOptions {
+  /** baseURL is the URL of the server, such as https://api.example.com */
+  baseURL: string;
+  /** timeout of the requests in milliseconds, no timeout by default */
+  timeout?: number;
+  /** headers added to every request, such as an Authorization header */
+  headers?: Record<string, string>;
+  /** fetch sends the requests, the global fetch by default */
+  fetch?: typeof fetch;
+}
+
+export interface RequestOptions {
+  /** signal aborts the request */
+  signal?: AbortSignal;
+  /** timeout of the request in milliseconds, overrides the timeout of the client */
+  timeout?: number;
+  headers?: Record<string, string>;
+}
+
+/** APIError is thrown for the responses whose status code is not 2xx */
+export class APIError extends Error {
+  constructor(readonly status: number, readonly body: string) {
+    super(` + "`request failed with status ${status}: ${body}`" + `);
+  }
+}
+
+interface APIRequest {
+  method: string;
+  path: string;
+  query?: Record<string, unknown>;
+  headers?: Record<string, unknown>;
+  body?: unknown;
+  form?: Record<string, unknown>;
+  multipart?: boolean;
+}
+
+function formatValue(v: unknown): string {
+  return v instanceof Date ? v.toISOString() : String(v);
+}
+
+function values(v: unknown): unknown[] {
+  if (v === undefined || v === null) {
+    return [];
+  }
+  return Array.isArray(v) ? v : [v];
+}
+
+/** Client calls the API */
+export class Client {
+{{services}}
+  constructor(private readonly options: ClientOptions) {
+{{serviceInits}}  }
+
+  async request<T>(req: APIRequest, options: RequestOptions = {}): Promise<T> {
+    const url = new URL(this.options.baseURL.replace(/\/$/, "") + req.path);
+    for (const [name, value] of Object.entries(req.query ?? {})) {
+      for (const v of values(value)) {
+        url.searchParams.append(name, formatValue(v));
+      }
+    }
+    const headers: Record<string, string> = { Accept: "application/json", ...this.options.headers, ...options.headers };
+    for (const [name, value] of Object.entries(req.headers ?? {})) {
+      for (const v of values(value)) {
+        headers[name] = formatValue(v);
+      }
+    }
+
+    let body: BodyInit | undefined;
+    if (req.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      body = JSON.stringify(req.body);
+    } else if (req.form && req.multipart) {
+      const form = new FormData();
+      for (const [name, value] of Object.entries(req.form)) {
+        for (const v of values(value)) {
+          form.append(name, v instanceof Blob ? v : formatValue(v));
+        }
+      }
+      body = form;
+    } else if (req.form) {
+      const form = new URLSearchParams();
+      for (const [name, value] of Object.entries(req.form)) {
+        for (const v of values(value)) {
+          form.append(name, formatValue(v));
+        }
+      }
+      body = form;
+    }
+
+    const controller = new AbortController();
+    const timeout = options.timeout ?? this.options.timeout;
+    const timer = timeout ? setTimeout(() => controller.abort(), timeout) : undefined;
+    options.signal?.addEventListener("abort", () => controller.abort());
+    try {
+      const send = this.options.fetch ?? fetch;
+      const res = await send(url.toString(), { method: req.method, headers, body, signal: controller.signal });
+      const text = await res.text();
+      if (!res.ok) {
+        throw new APIError(res.status, text);
+      }
+      return (text ? JSON.parse(text) : undefined) as T;
+    } finally {
+      if (timer) {
+        clearTimeout(timer);
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,403 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// 对比新生成的文档和之前的文档（swagger 2 或 OpenAPI 3 均可），
+// 把变化分为破坏性的（删除路由、新增必填参数、类型变化等）和非破坏性的。
+
+// APIChange is a change between two versions of the API
+type APIChange struct {
+	Breaking bool
+	Route    string // e.g. GET /v1/object/{objectId}, or the model name
+	Message  string
+}
+
+func (c APIChange) String() string {
+	return c.Route + ": " + c.Message
+}
+
+// apiIndex is a document indexed for the comparison
+type apiIndex struct {
+	ops    map[string]*apiOp // method and path, path parameters replaced by {}
+	models map[string]map[string]interface{}
+}
+
+type apiOp struct {
+	route        string
+	params       map[string]apiParam // location:name
+	body         map[string]interface{}
+	bodyRequired bool
+	responses    map[string]map[string]interface{} // status code: schema, nil without schema
+}
+
+type apiParam struct {
+	required bool
+	schema   map[string]interface{}
+}
+
+// DiffDocs generates the docs of the application in curpath like GenerateDocs,
+// and compares them with the previous spec in oldFile.
+func DiffDocs(curpath, spec, oldFile string) ([]APIChange, error) {
+	// 先读取旧文档，它可能正是将被覆盖的 swagger/swagger.json
+	data, err := os.ReadFile(oldFile)
+	if err != nil {
+		return nil, err
+	}
+	var old map[string]interface{}
+	if err := json.Unmarshal(data, &old); err != nil {
+		return nil, fmt.Errorf("%s is not a JSON document: %s", oldFile, err)
+	}
+	oldIndex := newAPIIndex()
+	oldIndex.add(old)
+
+	GenerateDocs(curpath, spec)
+
+	newIndex := newAPIIndex()
+	for _, api := range rootapiMap {
+		data, err := json.Marshal(api)
+		if err != nil {
+			return nil, err
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		newIndex.add(doc)
+	}
+	return diffAPI(oldIndex, newIndex), nil
+}
+
+func newAPIIndex() *apiIndex {
+	return &apiIndex{ops: map[string]*apiOp{}, models: map[string]map[string]interface{}{}}
+}
+
+// add indexes a swagger 2 or an OpenAPI 3 document
+func (idx *apiIndex) add(doc map[string]interface{}) {
+	basePath := jsonString(doc, "basePath")
+	if servers := jsonSlice(doc, "servers"); len(servers) > 0 {
+		if server, ok := servers[0].(map[string]interface{}); ok {
+			if u, err := url.Parse(jsonString(server, "url")); err == nil {
+				basePath = u.Path
+			}
+		}
+	}
+	basePath = strings.TrimSuffix(basePath, "/")
+
+	for name, s := range jsonMap(doc, "definitions") {
+		if m, ok := s.(map[string]interface{}); ok {
+			idx.models[name] = m
+		}
+	}
+	for name, s := range jsonMap(jsonMap(doc, "components"), "schemas") {
+		if m, ok := s.(map[string]interface{}); ok {
+			idx.models[name] = m
+		}
+	}
+
+	for p, item := range jsonMap(doc, "paths") {
+		item, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, method := range []string{"get", "post", "put", "patch", "delete", "head", "options"} {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			route := strings.ToUpper(method) + " " + basePath + p
+			idx.ops[pathParamRegexp.ReplaceAllString(route, "{}")] = indexOperation(route, op)
+		}
+	}
+}
+
+func indexOperation(route string, op map[string]interface{}) *apiOp {
+	out := &apiOp{
+		route:     route,
+		params:    map[string]apiParam{},
+		responses: map[string]map[string]interface{}{},
+	}
+	for _, p := range jsonSlice(op, "parameters") {
+		p, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		in, name := jsonString(p, "in"), jsonString(p, "name")
+		required, _ := p["required"].(bool)
+		schema := jsonMap(p, "schema")
+		switch in {
+		case "body":
+			out.body, out.bodyRequired = schema, required
+			continue
+		case "path":
+			// the path parameters are compared by their position, their names by the path
+			for i, m := range pathParamRegexp.FindAllStringSubmatch(route, -1) {
+				if m[1] == name {
+					name = fmt.Sprintf("#%d", i+1)
+				}
+			}
+		}
+		if schema == nil {
+			// swagger 2 parameters which are not in the body hold their type
+			schema = p
+		}
+		if in == "formData" {
+			in = "form"
+		}
+		out.params[in+":"+name] = apiParam{required: required || in == "path", schema: schema}
+	}
+	if body := jsonMap(op, "requestBody"); body != nil {
+		required, _ := body["required"].(bool)
+		for mediaType, c := range jsonMap(body, "content") {
+			c, _ := c.(map[string]interface{})
+			schema := jsonMap(c, "schema")
+			if mediaType == aform || mediaType == aurlencoded {
+				// form parameters
+				req := map[string]bool{}
+				for _, r := range jsonSlice(schema, "required") {
+					req[fmt.Sprint(r)] = true
+				}
+				for name, s := range jsonMap(schema, "properties") {
+					s, _ := s.(map[string]interface{})
+					out.params["form:"+name] = apiParam{required: req[name], schema: s}
+				}
+			} else {
+				out.body, out.bodyRequired = schema, required
+			}
+			break
+		}
+	}
+	for code, r := range jsonMap(op, "responses") {
+		r, _ := r.(map[string]interface{})
+		schema := jsonMap(r, "schema")
+		for _, c := range jsonMap(r, "content") {
+			c, _ := c.(map[string]interface{})
+			schema = jsonMap(c, "schema")
+			break
+		}
+		out.responses[code] = schema
+	}
+	return out
+}
+
+// diffAPI compares two documents
+func diffAPI(old, new *apiIndex) []APIChange {
+	var changes []APIChange
+	add := func(breaking bool, route, format string, args ...interface{}) {
+		changes = append(changes, APIChange{Breaking: breaking, Route: route, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for key, o := range old.ops {
+		n, ok := new.ops[key]
+		if !ok {
+			add(true, o.route, "route removed")
+			continue
+		}
+		route := n.route
+		for name, op := range o.params {
+			np, ok := n.params[name]
+			if !ok {
+				add(false, route, "parameter %s removed", paramLabel(name))
+				continue
+			}
+			if ot, nt := schemaDesc(op.schema), schemaDesc(np.schema); ot != nt {
+				add(true, route, "parameter %s changed from %s to %s", paramLabel(name), ot, nt)
+			}
+			if !op.required && np.required {
+				add(true, route, "parameter %s is now required", paramLabel(name))
+			} else if op.required && !np.required {
+				add(false, route, "parameter %s is now optional", paramLabel(name))
+			}
+		}
+		for name, np := range n.params {
+			if _, ok := o.params[name]; ok {
+				continue
+			}
+			if np.required {
+				add(true, route, "required parameter %s added", paramLabel(name))
+			} else {
+				add(false, route, "optional parameter %s added", paramLabel(name))
+			}
+		}
+
+		switch {
+		case o.body == nil && n.body != nil:
+			add(n.bodyRequired, route, "request body %s added", schemaDesc(n.body))
+		case o.body != nil && n.body == nil:
+			add(false, route, "request body removed")
+		case o.body != nil:
+			if ot, nt := schemaDesc(o.body), schemaDesc(n.body); ot != nt {
+				add(true, route, "request body changed from %s to %s", ot, nt)
+			}
+			if !o.bodyRequired && n.bodyRequired {
+				add(true, route, "request body is now required")
+			}
+		}
+
+		for code, or := range o.responses {
+			nr, ok := n.responses[code]
+			if !ok {
+				add(strings.HasPrefix(code, "2"), route, "response %s removed", code)
+				continue
+			}
+			if ot, nt := schemaDesc(or), schemaDesc(nr); ot != nt {
+				add(true, route, "response %s changed from %s to %s", code, ot, nt)
+			}
+		}
+		for code := range n.responses {
+			if _, ok := o.responses[code]; !ok {
+				add(false, route, "response %s added", code)
+			}
+		}
+	}
+	for key, n := range new.ops {
+		if _, ok := old.ops[key]; !ok {
+			add(false, n.route, "route added")
+		}
+	}
+
+	for name, om := range old.models {
+		nm, ok := new.models[name]
+		if !ok {
+			add(true, name, "model removed")
+			continue
+		}
+		if ot, nt := schemaDesc(om), schemaDesc(nm); ot != nt {
+			add(true, name, "model changed from %s to %s", ot, nt)
+			continue
+		}
+		oldProps, newProps := jsonMap(om, "properties"), jsonMap(nm, "properties")
+		oldRequired, newRequired := requiredSet(om), requiredSet(nm)
+		for prop, op := range oldProps {
+			np, ok := newProps[prop]
+			if !ok {
+				add(true, name, "property %s removed", prop)
+				continue
+			}
+			opm, _ := op.(map[string]interface{})
+			npm, _ := np.(map[string]interface{})
+			ot, nt := schemaDesc(opm), schemaDesc(npm)
+			if ot != nt {
+				add(true, name, "property %s changed from %s to %s", prop, ot, nt)
+			}
+			if !oldRequired[prop] && newRequired[prop] {
+				add(true, name, "property %s is now required", prop)
+			}
+		}
+		for prop := range newProps {
+			if _, ok := oldProps[prop]; ok {
+				continue
+			}
+			if newRequired[prop] {
+				add(true, name, "required property %s added", prop)
+			} else {
+				add(false, name, "property %s added", prop)
+			}
+		}
+	}
+	for name := range new.models {
+		if _, ok := old.models[name]; !ok {
+			add(false, name, "model added")
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Breaking != changes[j].Breaking {
+			return changes[i].Breaking
+		}
+		if changes[i].Route != changes[j].Route {
+			return changes[i].Route < changes[j].Route
+		}
+		return changes[i].Message < changes[j].Message
+	})
+	return changes
+}
+
+// schemaDesc describes the type of a schema, e.g. array of models.User
+func schemaDesc(s map[string]interface{}) string {
+	if s == nil {
+		return "nothing"
+	}
+	if ref := jsonString(s, "$ref"); ref != "" {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	for _, sub := range jsonSlice(s, "oneOf") {
+		// nullable references
+		if sub, ok := sub.(map[string]interface{}); ok && jsonString(sub, "type") != "null" {
+			return schemaDesc(sub)
+		}
+	}
+	typ := jsonString(s, "type")
+	for _, t := range jsonSlice(s, "type") {
+		// nullable types
+		if t != "null" {
+			typ = fmt.Sprint(t)
+		}
+	}
+	switch typ {
+	case "":
+		return "any"
+	case astTypeArray:
+		return "array of " + schemaDesc(jsonMap(s, "items"))
+	case astTypeObject:
+		if ap := jsonMap(s, "additionalProperties"); ap != nil {
+			return "map of " + schemaDesc(ap)
+		}
+		return astTypeObject
+	case "file":
+		return "string(binary)"
+	}
+	if format := convertFormat(jsonString(s, "format")); format != "" {
+		return typ + "(" + format + ")"
+	}
+	return typ
+}
+
+// paramLabel returns the label of a location:name parameter key
+func paramLabel(key string) string {
+	parts := strings.SplitN(key, ":", 2)
+	return parts[1] + " in " + parts[0]
+}
+
+func requiredSet(s map[string]interface{}) map[string]bool {
+	set := map[string]bool{}
+	for _, r := range jsonSlice(s, "required") {
+		set[fmt.Sprint(r)] = true
+	}
+	return set
+}
+
+func jsonMap(m map[string]interface{}, key string) map[string]interface{} {
+	v, _ := m[key].(map[string]interface{})
+	return v
+}
+
+func jsonSlice(m map[string]interface{}, key string) []interface{} {
+	v, _ := m[key].([]interface{})
+	return v
+}
+
+func jsonString(m map[string]interface{}, key string) string {
+	v, _ := m[key].(string)
+	return v
+}
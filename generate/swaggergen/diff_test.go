@@ -0,0 +1,256 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// swaggerUser is a swagger 2 document of the user API
+const swaggerUser = `{
+	"swagger": "2.0",
+	"basePath": "/v1",
+	"paths": {
+		"/user/{uid}": {
+			"get": {
+				"parameters": [
+					{"in": "path", "name": "uid", "type": "integer", "format": "int64", "required": true},
+					{"in": "query", "name": "fields", "type": "string"}
+				],
+				"responses": {
+					"200": {"description": "", "schema": {"$ref": "#/definitions/models.User"}},
+					"404": {"description": "not found"}
+				}
+			},
+			"delete": {
+				"parameters": [{"in": "path", "name": "uid", "type": "integer", "format": "int64", "required": true}],
+				"responses": {"200": {"description": ""}}
+			}
+		},
+		"/user/": {
+			"post": {
+				"parameters": [{"in": "body", "name": "body", "schema": {"$ref": "#/definitions/models.User"}, "required": true}],
+				"responses": {"200": {"description": "", "schema": {"type": "integer", "format": "int64"}}}
+			}
+		}
+	},
+	"definitions": {
+		"models.User": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"id": {"type": "integer", "format": "int64"},
+				"name": {"type": "string"},
+				"created_at": {"type": "string", "format": "datetime"}
+			}
+		}
+	}
+}`
+
+func TestDiffAPI(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      string
+		new      string
+		expected []string
+	}{
+		{
+			name: "same document",
+			old:  swaggerUser,
+			new:  swaggerUser,
+		},
+		{
+			name: "swagger 2 and OpenAPI 3 versions of the document",
+			old:  swaggerUser,
+			new: `{
+				"openapi": "3.1.0",
+				"servers": [{"url": "http://localhost:8080/v1"}],
+				"paths": {
+					"/user/{id}": {
+						"get": {
+							"parameters": [
+								{"in": "path", "name": "id", "required": true, "schema": {"type": "integer", "format": "int64"}},
+								{"in": "query", "name": "fields", "schema": {"type": "string"}}
+							],
+							"responses": {
+								"200": {"description": "", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/models.User"}}}},
+								"404": {"description": "not found"}
+							}
+						},
+						"delete": {
+							"parameters": [{"in": "path", "name": "id", "required": true, "schema": {"type": "integer", "format": "int64"}}],
+							"responses": {"200": {"description": ""}}
+						}
+					},
+					"/user/": {
+						"post": {
+							"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/models.User"}}}},
+							"responses": {"200": {"description": "", "content": {"application/json": {"schema": {"type": "integer", "format": "int64"}}}}}
+						}
+					}
+				},
+				"components": {
+					"schemas": {
+						"models.User": {
+							"type": "object",
+							"required": ["name"],
+							"properties": {
+								"id": {"type": "integer", "format": "int64"},
+								"name": {"type": "string"},
+								"created_at": {"type": ["string", "null"], "format": "date-time"}
+							}
+						}
+					}
+				}
+			}`,
+		},
+		{
+			name: "removed and added routes",
+			old:  swaggerUser,
+			new: strings.Replace(strings.Replace(swaggerUser, `"delete"`, `"put"`, 1),
+				`"basePath": "/v1"`, `"basePath": "/v1/"`, 1),
+			expected: []string{
+				"breaking DELETE /v1/user/{uid}: route removed",
+				"PUT /v1/user/{uid}: route added",
+			},
+		},
+		{
+			name: "parameters",
+			old:  swaggerUser,
+			new: strings.Replace(strings.Replace(swaggerUser,
+				`{"in": "query", "name": "fields", "type": "string"}`,
+				`{"in": "query", "name": "fields", "type": "string", "required": true},
+				{"in": "query", "name": "expand", "type": "string"},
+				{"in": "header", "name": "X-Tenant", "type": "string", "required": true}`, 1),
+				`"parameters": [{"in": "path", "name": "uid", "type": "integer", "format": "int64", "required": true}]`,
+				`"parameters": [{"in": "path", "name": "uid", "type": "string", "required": true}]`, 1),
+			expected: []string{
+				"breaking DELETE /v1/user/{uid}: parameter #1 in path changed from integer(int64) to string",
+				"breaking GET /v1/user/{uid}: parameter fields in query is now required",
+				"breaking GET /v1/user/{uid}: required parameter X-Tenant in header added",
+				"GET /v1/user/{uid}: optional parameter expand in query added",
+			},
+		},
+		{
+			name: "optional and removed parameters",
+			old: strings.Replace(swaggerUser,
+				`{"in": "query", "name": "fields", "type": "string"}`,
+				`{"in": "query", "name": "fields", "type": "string", "required": true},
+				{"in": "query", "name": "sort", "type": "string"}`, 1),
+			new: swaggerUser,
+			expected: []string{
+				"GET /v1/user/{uid}: parameter fields in query is now optional",
+				"GET /v1/user/{uid}: parameter sort in query removed",
+			},
+		},
+		{
+			name: "request bodies and responses",
+			old:  swaggerUser,
+			new: strings.Replace(strings.Replace(strings.Replace(swaggerUser,
+				`"schema": {"$ref": "#/definitions/models.User"}, "required": true`,
+				`"schema": {"type": "array", "items": {"$ref": "#/definitions/models.User"}}, "required": true`, 1),
+				`"200": {"description": "", "schema": {"type": "integer", "format": "int64"}}`,
+				`"201": {"description": "", "schema": {"type": "integer", "format": "int64"}}`, 1),
+				`"404": {"description": "not found"}`, `"403": {"description": "forbidden"}`, 1),
+			expected: []string{
+				"breaking POST /v1/user/: request body changed from models.User to array of models.User",
+				"breaking POST /v1/user/: response 200 removed",
+				"GET /v1/user/{uid}: response 403 added",
+				"GET /v1/user/{uid}: response 404 removed",
+				"POST /v1/user/: response 201 added",
+			},
+		},
+		{
+			name: "model properties",
+			old:  swaggerUser,
+			new: strings.Replace(swaggerUser,
+				`"required": ["name"],
+			"properties": {
+				"id": {"type": "integer", "format": "int64"},
+				"name": {"type": "string"},
+				"created_at": {"type": "string", "format": "datetime"}
+			}`,
+				`"required": ["name", "email", "role"],
+			"properties": {
+				"id": {"type": "string"},
+				"name": {"type": "string"},
+				"email": {"type": "string"},
+				"role": {"type": "string"},
+				"nickname": {"type": "string"},
+				"tags": {"type": "object", "additionalProperties": {"type": "string"}}
+			}`, 1),
+			expected: []string{
+				"breaking models.User: property created_at removed",
+				"breaking models.User: property id changed from integer(int64) to string",
+				"breaking models.User: required property email added",
+				"breaking models.User: required property role added",
+				"models.User: property nickname added",
+				"models.User: property tags added",
+			},
+		},
+		{
+			name: "form parameters of OpenAPI 3",
+			old: `{"openapi": "3.1.0", "paths": {"/login": {"post": {"requestBody": {"content": {
+				"application/x-www-form-urlencoded": {"schema": {"type": "object", "required": ["username"],
+				"properties": {"username": {"type": "string"}, "password": {"type": "string"}}}}}}}}}}`,
+			new: `{"swagger": "2.0", "paths": {"/login": {"post": {"parameters": [
+				{"in": "formData", "name": "username", "type": "string", "required": true},
+				{"in": "formData", "name": "password", "type": "string", "required": true},
+				{"in": "formData", "name": "avatar", "type": "file"}]}}}}`,
+			expected: []string{
+				"breaking POST /login: parameter password in form is now required",
+				"POST /login: optional parameter avatar in form added",
+			},
+		},
+		{
+			name: "removed and added models",
+			old:  `{"definitions": {"models.User": {"type": "object"}, "models.Status": {"type": "string"}}}`,
+			new:  `{"definitions": {"models.User": {"type": "object"}, "models.Status": {"type": "integer"}, "models.Tag": {"type": "object"}}}`,
+			expected: []string{
+				"breaking models.Status: model changed from string to integer",
+				"models.Tag: model added",
+			},
+		},
+	}
+
+	index := func(t *testing.T, doc string) *apiIndex {
+		t.Helper()
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(doc), &m); err != nil {
+			t.Fatal(err)
+		}
+		idx := newAPIIndex()
+		idx.add(m)
+		return idx
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var changes []string
+			for _, c := range diffAPI(index(t, tc.old), index(t, tc.new)) {
+				s := c.String()
+				if c.Breaking {
+					s = "breaking " + s
+				}
+				changes = append(changes, s)
+			}
+			if !reflect.DeepEqual(changes, tc.expected) {
+				t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(tc.expected, "\n"), strings.Join(changes, "\n"))
+			}
+		})
+	}
+}
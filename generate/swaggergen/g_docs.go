@@ -15,7 +15,6 @@
 package swaggergen
 
 import (
-	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,7 +22,6 @@ import (
 	"go/build"
 	"go/parser"
 	"go/token"
-	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -37,6 +35,8 @@ import (
 
 	yaml "gopkg.in/yaml.v2"
 
+	"github.com/beego/bee/v2/internal/pkg/generated"
+	"github.com/beego/bee/v2/internal/pkg/swaggerui"
 	bu "github.com/beego/bee/v2/utils"
 
 	beeLogger "github.com/beego/bee/v2/logger"
@@ -65,6 +65,7 @@ var controllerComments map[string]string
 var importlist map[string]string
 var controllerList map[string]map[string]*swagger.Item //controllername Paths items
 var modelsList map[string]map[string]swagger.Schema
+var nullableProperties map[string]map[string]bool // definition name: names of the properties of pointer fields
 
 var rootapiSingle = false
 var rootapiDefault swagger.Swagger
@@ -108,14 +109,25 @@ var customObject = map[string]string{
 }
 
 func init() {
+	resetDocs()
+}
+
+// resetDocs clears the controllers, the models and the documents of the previous analysis
+func resetDocs() {
 	pkgCache = make(map[string]struct{})
 	controllerComments = make(map[string]string)
 	importlist = make(map[string]string)
 	controllerList = make(map[string]map[string]*swagger.Item)
+	controllerMethods = make(map[string]map[string]*swagger.Operation)
+	annotatedRoutes = make(map[string]map[string]string)
 	modelsList = make(map[string]map[string]swagger.Schema)
+	nullableProperties = make(map[string]map[string]bool)
 	rootapiMap = make(map[string]*swagger.Swagger)
+	rootapiSingle = false
+	rootapiDefault = swagger.Swagger{}
 	astPkgs = make([]*ast.Package, 0)
 	pkgLoadedCache = make(map[string]struct{})
+	unknownModels = make(map[string]bool)
 }
 
 // parsePackagesFromDir parses packages from a given directory
@@ -181,23 +193,60 @@ func parsePackageFromDir(path string) error {
 	return nil
 }
 
-// GenerateDocs generates documentations for a given path.
-func GenerateDocs(curpath string) {
+// GenerateDocs generates the API documents of the application in curpath,
+// following the spec specification: either SpecSwagger2 or SpecOpenAPI3.
+func GenerateDocs(curpath, spec string) {
+	if spec == "" {
+		spec = SpecSwagger2
+	}
+	if spec != SpecSwagger2 && spec != SpecOpenAPI3 {
+		beeLogger.Log.Fatalf("Unknown spec '%s'. Must be either %s or %s", spec, SpecSwagger2, SpecOpenAPI3)
+	}
+	analyseDocs(curpath)
+
+	docName := "swagger"
+	if spec == SpecOpenAPI3 {
+		docName = "openapi"
+	}
+	for version, api := range rootapiMap {
+		api.Definitions = rootapiDefault.Definitions
+		var doc interface{} = api
+		if spec == SpecOpenAPI3 {
+			doc = toOpenAPI3(api)
+		}
+
+		dir := path.Join(curpath, "swagger", version)
+		dt, err := json.MarshalIndent(doc, "", "    ")
+		dtyml, erryml := yaml.Marshal(doc)
+		if err != nil || erryml != nil {
+			panic(err)
+		}
+		err = generated.WriteFile(path.Join(dir, docName+".json"), dt)
+		erryml = generated.WriteFile(path.Join(dir, docName+".yml"), dtyml)
+		if err != nil || erryml != nil {
+			panic(err)
+		}
+	}
+
+	modifySwaggerIndexFile(curpath, docName+".json", rootapiSingle, rootapiMap)
+}
+
+// analyseDocs parses the routers and the controllers of the application in curpath
+func analyseDocs(curpath string) {
+	resetDocs()
 	pkgspath := curpath
 	workspace := os.Getenv("BeeWorkspace")
 	if workspace != "" {
 		pkgspath = workspace
 	}
 	parsePackagesFromDir(pkgspath)
+	loadTypedPackages(pkgspath)
 
 	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, filepath.Join(curpath, "routers", "router.go"), nil, parser.ParseComments)
-	if err != nil {
-		beeLogger.Log.Fatalf("Error while parsing router.go: %s", err)
-	}
+	files := parseRouterFiles(fset, curpath)
 
 	// Analyse API comments
-	if f.Comments != nil {
+	for _, f := range files {
 		for _, c := range f.Comments {
 			var namespacePrefix string
 			rootapi := swagger.Swagger{
@@ -241,57 +290,29 @@ func GenerateDocs(curpath string) {
 				} else if strings.HasPrefix(s, "@Host") {
 					rootapi.Host = strings.TrimSpace(s[len("@Host"):])
 				} else if strings.HasPrefix(s, "@SecurityDefinition") {
+					name, out, err := parseSecurityDefinition(strings.TrimSpace(s[len("@SecurityDefinition"):]))
+					if err != nil {
+						if annotationProblem(commentPosition(fset, c, s), "@SecurityDefinition: %s", err) {
+							continue
+						}
+						beeLogger.Log.Fatalf("%s\n", err)
+					}
 					if len(rootapi.SecurityDefinitions) == 0 {
 						rootapi.SecurityDefinitions = make(map[string]swagger.Security)
 					}
-					var out swagger.Security
-					p := getparams(strings.TrimSpace(s[len("@SecurityDefinition"):]))
-					if len(p) < 2 {
-						beeLogger.Log.Fatalf("Not enough params for security: %d\n", len(p))
-					}
-					out.Type = p[1]
-					switch out.Type {
-					case "oauth2":
-						if len(p) < 6 {
-							beeLogger.Log.Fatalf("Not enough params for oauth2: %d\n", len(p))
-						}
-						if !(p[3] == "implicit" || p[3] == "password" || p[3] == "application" || p[3] == "accessCode") {
-							beeLogger.Log.Fatalf("Unknown flow type: %s. Possible values are `implicit`, `password`, `application` or `accessCode`.\n", p[1])
-						}
-						out.AuthorizationURL = p[2]
-						out.Flow = p[3]
-						if len(p)%2 != 0 {
-							out.Description = strings.Trim(p[len(p)-1], `" `)
-						}
-						out.Scopes = make(map[string]string)
-						for i := 4; i < len(p)-1; i += 2 {
-							out.Scopes[p[i]] = strings.Trim(p[i+1], `" `)
-						}
-					case "apiKey":
-						if len(p) < 4 {
-							beeLogger.Log.Fatalf("Not enough params for apiKey: %d\n", len(p))
-						}
-						if !(p[3] == "header" || p[3] == "query") {
-							beeLogger.Log.Fatalf("Unknown in type: %s. Possible values are `query` or `header`.\n", p[4])
-						}
-						out.Name = p[2]
-						out.In = p[3]
-						if len(p) > 4 {
-							out.Description = strings.Trim(p[4], `" `)
-						}
-					case "basic":
-						if len(p) > 2 {
-							out.Description = strings.Trim(p[2], `" `)
+					rootapi.SecurityDefinitions[name] = out
+				} else if strings.HasPrefix(s, "@Security") {
+					security, err := getSecurity(s)
+					if err != nil {
+						if annotationProblem(commentPosition(fset, c, s), "@Security: %s", err) {
+							continue
 						}
-					default:
-						beeLogger.Log.Fatalf("Unknown security type: %s. Possible values are `oauth2`, `apiKey` or `basic`.\n", p[1])
+						beeLogger.Log.Fatalf("%s\n", err)
 					}
-					rootapi.SecurityDefinitions[p[0]] = out
-				} else if strings.HasPrefix(s, "@Security") {
 					if len(rootapi.Security) == 0 {
 						rootapi.Security = make([]map[string][]string, 0)
 					}
-					rootapi.Security = append(rootapi.Security, getSecurity(s))
+					rootapi.Security = append(rootapi.Security, security)
 				}
 			}
 
@@ -303,202 +324,21 @@ func GenerateDocs(curpath string) {
 		}
 	}
 	// Analyse controller package
-	for _, im := range f.Imports {
-		localName := ""
-		if im.Name != nil {
-			localName = im.Name.Name
-		}
-		analyseControllerPkg(localName, im.Path.Value)
-	}
-
-	for _, d := range f.Decls {
-		switch specDecl := d.(type) {
-		case *ast.FuncDecl:
-			for _, l := range specDecl.Body.List {
-				switch stmt := l.(type) {
-				case *ast.AssignStmt:
-					for _, l := range stmt.Rhs {
-						if v, ok := l.(*ast.CallExpr); ok {
-							// Analyze NewNamespace, it will return version and the subfunction
-							selExpr, selOK := v.Fun.(*ast.SelectorExpr)
-							if !selOK || selExpr.Sel.Name != "NewNamespace" {
-								continue
-							}
-
-							version, params := analyseNewNamespace(v)
-							var rootapi *swagger.Swagger
-							if rootapiSingle {
-								rootapi = &rootapiDefault
-							} else {
-								_rootapi, rootapiExist := rootapiMap[version]
-								if !rootapiExist {
-									rootapi = &swagger.Swagger{
-										Infos:          swagger.Information{},
-										SwaggerVersion: "2.0",
-									}
-									rootapiMap[version] = rootapi
-								} else {
-									rootapi = _rootapi
-								}
-							}
-
-							if rootapi.BasePath == "" {
-								rootapi.BasePath = version
-							}
-
-							for _, p := range params {
-								switch pp := p.(type) {
-								case *ast.CallExpr:
-									var controllerName string
-									if selname := pp.Fun.(*ast.SelectorExpr).Sel.String(); selname == "NSNamespace" {
-										s, params := analyseNewNamespace(pp)
-										if rootapi.BasePath == "" {
-											s = path.Join(version, s)
-										}
-
-										for _, sp := range params {
-											switch pp := sp.(type) {
-											case *ast.CallExpr:
-												if pp.Fun.(*ast.SelectorExpr).Sel.String() == "NSInclude" {
-													controllerName = analyseNSInclude(s, pp, rootapi)
-													if v, ok := controllerComments[controllerName]; ok {
-														rootapi.Tags = append(rootapi.Tags, swagger.Tag{
-															Name:        strings.Trim(s, "/"),
-															Description: v,
-														})
-													}
-												}
-											}
-										}
-									} else if selname == "NSInclude" {
-										controllerName = analyseNSInclude("", pp, rootapi)
-										if v, ok := controllerComments[controllerName]; ok {
-											rootapi.Tags = append(rootapi.Tags, swagger.Tag{
-												Name:        controllerName, // if the NSInclude has no prefix, we use the controllername as the tag
-												Description: v,
-											})
-										}
-									}
-								}
-							}
-						}
-
-					}
-				}
+	for _, f := range files {
+		for _, im := range f.Imports {
+			localName := ""
+			if im.Name != nil {
+				localName = im.Name.Name
 			}
+			analyseControllerPkg(localName, im.Path.Value)
 		}
 	}
 
+	discoverRoutes(fset, files)
+
 	if rootapiSingle {
 		rootapiMap[defaultNamespacePrefix] = &rootapiDefault
 	}
-
-	for version, api := range rootapiMap {
-		api.Definitions = rootapiDefault.Definitions
-
-		dir := path.Join(curpath, "swagger", version)
-		os.MkdirAll(dir, 0755)
-
-		fd, err := os.Create(path.Join(dir, "swagger.json"))
-		if err != nil {
-			panic(err)
-		}
-		defer fd.Close()
-
-		fdyml, err := os.Create(path.Join(dir, "swagger.yml"))
-		if err != nil {
-			panic(err)
-		}
-		defer fdyml.Close()
-
-		dt, err := json.MarshalIndent(api, "", "    ")
-		dtyml, erryml := yaml.Marshal(api)
-		if err != nil || erryml != nil {
-			panic(err)
-		}
-		_, err = fd.Write(dt)
-		_, erryml = fdyml.Write(dtyml)
-		if err != nil || erryml != nil {
-			panic(err)
-		}
-	}
-
-	modifySwaggerIndexFile(curpath, rootapiSingle, rootapiMap)
-}
-
-// analyseNewNamespace returns version and the others params
-func analyseNewNamespace(ce *ast.CallExpr) (first string, others []ast.Expr) {
-	for i, p := range ce.Args {
-		if i == 0 {
-			switch pp := p.(type) {
-			case *ast.BasicLit:
-				first = strings.Trim(pp.Value, `"`)
-			}
-			continue
-		}
-		others = append(others, p)
-	}
-	return
-}
-
-func analyseNSInclude(baseurl string, ce *ast.CallExpr, rootapi *swagger.Swagger) string {
-	cname := ""
-	for _, p := range ce.Args {
-		var x *ast.SelectorExpr
-		var p1 interface{} = p
-		if ident, ok := p1.(*ast.Ident); ok {
-			if assign, ok := ident.Obj.Decl.(*ast.AssignStmt); ok {
-				if len(assign.Rhs) > 0 {
-					p1 = assign.Rhs[0].(*ast.UnaryExpr)
-				}
-			}
-		}
-		if _, ok := p1.(*ast.UnaryExpr); ok {
-			x = p1.(*ast.UnaryExpr).X.(*ast.CompositeLit).Type.(*ast.SelectorExpr)
-		} else {
-			beeLogger.Log.Warnf("Couldn't determine type\n")
-			continue
-		}
-		if v, ok := importlist[fmt.Sprint(x.X)]; ok {
-			cname = v + x.Sel.Name
-		}
-		if apis, ok := controllerList[cname]; ok {
-			for rt, item := range apis {
-				tag := cname
-				if baseurl != "" {
-					rt = baseurl + rt
-					tag = strings.Trim(baseurl, "/")
-				}
-				if item.Get != nil {
-					item.Get.Tags = []string{tag}
-				}
-				if item.Post != nil {
-					item.Post.Tags = []string{tag}
-				}
-				if item.Put != nil {
-					item.Put.Tags = []string{tag}
-				}
-				if item.Patch != nil {
-					item.Patch.Tags = []string{tag}
-				}
-				if item.Head != nil {
-					item.Head.Tags = []string{tag}
-				}
-				if item.Delete != nil {
-					item.Delete.Tags = []string{tag}
-				}
-				if item.Options != nil {
-					item.Options.Tags = []string{tag}
-				}
-				if len(rootapi.Paths) == 0 {
-					rootapi.Paths = make(map[string]*swagger.Item)
-				}
-				rt = urlReplace(rt)
-				rootapi.Paths[rt] = item
-			}
-		}
-	}
-	return cname
 }
 
 func analyseControllerPkg(localName, pkgpath string) {
@@ -506,7 +346,8 @@ func analyseControllerPkg(localName, pkgpath string) {
 	if isSystemPackage(pkgpath) {
 		return
 	}
-	if pkgpath == "github.com/beego/beego/v2/server/web" {
+	// the packages of beego itself, such as web/context imported by the routers, hold no controllers
+	if pkgpath == "github.com/beego/beego/v2/server/web" || strings.HasPrefix(pkgpath, "github.com/beego/beego/v2/") {
 		return
 	}
 	if localName != "" {
@@ -547,7 +388,7 @@ func analyseControllerPkg(localName, pkgpath string) {
 					if specDecl.Recv != nil && len(specDecl.Recv.List) > 0 {
 						if t, ok := specDecl.Recv.List[0].Type.(*ast.StarExpr); ok {
 							// Parse controller method
-							parserComments(specDecl, fmt.Sprint(t.X), pkgpath)
+							parserComments(fileSet, specDecl, fmt.Sprint(t.X), pkgpath)
 						}
 					}
 				case *ast.GenDecl:
@@ -600,9 +441,21 @@ func peekNextSplitString(ss string) (s string, spacePos int) {
 }
 
 // parse the func comments
-func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
+func parserComments(fset *token.FileSet, f *ast.FuncDecl, controllerName, pkgpath string) error {
 	var routerPath string
 	var HTTPMethod string
+	var routerPos token.Position
+	pathParams := map[string]token.Position{} // @Param ... path
+	// problem reports a malformed annotation in check mode
+	problem := func(c *ast.Comment, format string, args ...interface{}) bool {
+		return annotationProblem(fset.Position(c.Pos()), "[%s.%s] %s", controllerName, f.Name.String(), fmt.Sprintf(format, args...))
+	}
+	// checkModel reports the unknown model types in check mode
+	checkModel := func(c *ast.Comment, typ string) {
+		if unknownModels[strings.TrimPrefix(typ, "[]")] {
+			problem(c, "unknown model type %s", typ)
+		}
+	}
 	opts := swagger.Operation{
 		Responses: make(map[string]swagger.Response),
 	}
@@ -616,16 +469,27 @@ func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
 			if strings.HasPrefix(t, "@router") {
 				elements := strings.TrimSpace(t[len("@router"):])
 				e1 := strings.SplitN(elements, " ", 2)
-				if len(e1) < 1 {
+				if len(e1) < 1 || e1[0] == "" {
+					if problem(c, "@router should have a path") {
+						continue
+					}
 					return errors.New("you should has router information")
 				}
 				routerPath = e1[0]
+				routerPos = fset.Position(c.Pos())
 				if len(e1) == 2 && e1[1] != "" {
 					e1 = strings.SplitN(e1[1], " ", 2)
 					HTTPMethod = strings.ToUpper(strings.Trim(e1[0], "[]"))
 				} else {
 					HTTPMethod = "GET"
 				}
+				for _, hm := range strings.Split(HTTPMethod, ",") {
+					if !isHTTPMethod(hm) {
+						problem(c, "unknown HTTP method %s in @router", hm)
+						continue
+					}
+					annotateRoute(routerPos, pkgpath+controllerName, hm, urlReplace(routerPath))
+				}
 			} else if strings.HasPrefix(t, "@Title") {
 				opts.OperationID = controllerName + "." + strings.TrimSpace(t[len("@Title"):])
 			} else if strings.HasPrefix(t, "@Description") {
@@ -636,6 +500,9 @@ func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
 				ss := strings.TrimSpace(t[len("@Success"):])
 				rs := swagger.Response{}
 				respCode, pos := peekNextSplitString(ss)
+				if !isResponseCode(respCode) {
+					problem(c, "@Success should start with a status code, got %q", respCode)
+				}
 				ss = strings.TrimSpace(ss[pos:])
 				respType, pos := peekNextSplitString(ss)
 				if respType == "{object}" || respType == "{array}" {
@@ -643,6 +510,9 @@ func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
 					ss = strings.TrimSpace(ss[pos:])
 					schemaName, pos := peekNextSplitString(ss)
 					if schemaName == "" {
+						if problem(c, "schema must follow %s", respType) {
+							continue
+						}
 						beeLogger.Log.Fatalf("[%s.%s] Schema must follow {object} or {array}", controllerName, funcName)
 					}
 					if strings.HasPrefix(schemaName, "[]") {
@@ -662,6 +532,7 @@ func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
 						}
 						modelsList[pkgpath+controllerName][schemaName] = mod
 						appendModels(pkgpath, controllerName, realTypes)
+						checkModel(c, schemaName)
 					}
 					if isArray {
 						rs.Schema = &swagger.Schema{
@@ -680,8 +551,14 @@ func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
 				para := swagger.Parameter{}
 				p := getparams(strings.TrimSpace(t[len("@Param "):]))
 				if len(p) < 4 {
+					if problem(c, "@Param should have at least 4 fields: name, location, type and description, got %d", len(p)) {
+						continue
+					}
 					beeLogger.Log.Fatal(controllerName + "_" + funcName + "'s comments @Param should have at least 4 params")
 				}
+				if len(p) > 6 {
+					problem(c, "@Param should have at most 6 fields, got %d, the description must be quoted", len(p))
+				}
 				paramNames := strings.SplitN(p[0], "=>", 2)
 				para.Name = paramNames[0]
 				funcParamName := para.Name
@@ -705,7 +582,12 @@ func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
 				case "body":
 					break
 				default:
-					beeLogger.Log.Warnf("[%s.%s] Unknown param location: %s. Possible values are `query`, `header`, `path`, `formData` or `body`.\n", controllerName, funcName, p[1])
+					if !problem(c, "unknown param location %s, possible values are query, header, path, formData or body", p[1]) {
+						beeLogger.Log.Warnf("[%s.%s] Unknown param location: %s. Possible values are `query`, `header`, `path`, `formData` or `body`.\n", controllerName, funcName, p[1])
+					}
+				}
+				if p[1] == "path" {
+					pathParams[para.Name] = fset.Position(c.Pos())
 				}
 				para.In = p[1]
 				pp := strings.Split(p[2], ".")
@@ -735,11 +617,13 @@ func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
 					}
 					modelsList[pkgpath+controllerName][typ] = mod
 					appendModels(pkgpath, controllerName, realTypes)
+					checkModel(c, p[2])
 				} else {
 					if typ == "auto" {
 						typ = paramType
 					}
 					setParamType(&para, typ, pkgpath, controllerName)
+					checkModel(c, typ)
 				}
 				switch len(p) {
 				case 5:
@@ -770,6 +654,9 @@ func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
 					start = true
 					cd = append(cd, s)
 				}
+				if !isResponseCode(string(cd)) {
+					problem(c, "@Failure should start with a status code, got %q", string(cd))
+				}
 				opts.Responses[string(cd)] = rs
 			} else if strings.HasPrefix(t, "@Deprecated") {
 				opts.Deprecated, _ = strconv.ParseBool(strings.TrimSpace(t[len("@Deprecated"):]))
@@ -794,14 +681,33 @@ func parserComments(f *ast.FuncDecl, controllerName, pkgpath string) error {
 					}
 				}
 			} else if strings.HasPrefix(t, "@Security") {
+				security, err := getSecurity(t)
+				if err != nil {
+					if problem(c, "@Security: %s", err) {
+						continue
+					}
+					beeLogger.Log.Fatalf("[%s.%s] %s\n", controllerName, funcName, err)
+				}
 				if len(opts.Security) == 0 {
 					opts.Security = make([]map[string][]string, 0)
 				}
-				opts.Security = append(opts.Security, getSecurity(t))
+				opts.Security = append(opts.Security, security)
 			}
 		}
 	}
+	if routerPath != "" {
+		checkPathParams(routerPath, routerPos, pathParams, funcParamMap, controllerName, f.Name.String())
+		if _, ok := annotatedRoutes[pkgpath+controllerName]; !ok {
+			annotatedRoutes[pkgpath+controllerName] = make(map[string]string)
+		}
+		annotatedRoutes[pkgpath+controllerName][urlReplace(routerPath)] = routerPath
+	}
 	routerPath = urlReplace(routerPath)
+	if _, ok := controllerMethods[pkgpath+controllerName]; !ok {
+		controllerMethods[pkgpath+controllerName] = make(map[string]*swagger.Operation)
+	}
+	controllerMethods[pkgpath+controllerName][funcName] = &opts
+
 	if routerPath != "" {
 		//Go over function parameters which were not mapped and create swagger params for them
 		for name, typ := range funcParamMap {
@@ -992,6 +898,9 @@ func getparams(str string) []string {
 }
 
 func getModel(str string) (definitionName string, m swagger.Schema, realTypes []string) {
+	if name, tm, ok := getTypedModel(str); ok {
+		return name, tm, nil
+	}
 	strs := strings.Split(str, ".")
 	// strs = [packageName].[objectName]
 	packageName := strs[0]
@@ -1022,6 +931,7 @@ L:
 	}
 
 	if m.Title == "" {
+		unknownModels[str] = true
 		// Don't log when error has already been logged
 		if _, found := rootapiDefault.Definitions[str]; !found {
 			beeLogger.Log.Warnf("Cannot find the object: %s", str)
@@ -1207,6 +1117,7 @@ func parseStruct(imports []*ast.ImportSpec, st *ast.StructType, k string, m *swa
 				// if no tag skip tag processing
 				if field.Tag == nil {
 					m.Properties[name] = mp
+					markNullable(packageName+"."+k, name, field)
 					continue
 				}
 
@@ -1273,6 +1184,7 @@ func parseStruct(imports []*ast.ImportSpec, st *ast.StructType, k string, m *swa
 					}
 
 					m.Properties[name] = mp
+					markNullable(packageName+"."+k, name, field)
 				}
 			} else {
 				// only parse case of when embedded field is TypeName
@@ -1294,6 +1206,7 @@ func parseStruct(imports []*ast.ImportSpec, st *ast.StructType, k string, m *swa
 					} else {
 						//if json tag is "something", output: something #definition/pkgname.Type
 						m.Properties[tagValues[0]] = mp
+						markNullable(packageName+"."+k, tagValues[0], field)
 						continue
 					}
 				} else {
@@ -1304,6 +1217,9 @@ func parseStruct(imports []*ast.ImportSpec, st *ast.StructType, k string, m *swa
 							for nameOfObj, obj := range fl.Scope.Objects {
 								if pkg.Name+"."+obj.Name == realType {
 									parseObject(imports, obj, nameOfObj, nm, realTypes, astPkgs, pkg.Name)
+									for name := range nullableProperties[realType] {
+										markNullable(packageName+"."+k, name, nil)
+									}
 								}
 							}
 						}
@@ -1319,6 +1235,20 @@ func parseStruct(imports []*ast.ImportSpec, st *ast.StructType, k string, m *swa
 	}
 }
 
+// markNullable records that the property name of the definition may be null,
+// which is the case of pointer fields. A nil field is always nullable.
+func markNullable(definition, name string, field *ast.Field) {
+	if field != nil {
+		if _, ok := field.Type.(*ast.StarExpr); !ok {
+			return
+		}
+	}
+	if nullableProperties[definition] == nil {
+		nullableProperties[definition] = make(map[string]bool)
+	}
+	nullableProperties[definition][name] = true
+}
+
 func typeAnalyser(f *ast.Field) (isSlice bool, realType, swaggerType string) {
 	if arr, ok := f.Type.(*ast.ArrayType); ok {
 		if isBasicType(fmt.Sprint(arr.Elt)) {
@@ -1386,17 +1316,65 @@ func appendModels(pkgpath, controllerName string, realTypes []string) {
 	}
 }
 
-func getSecurity(t string) (security map[string][]string) {
-	security = make(map[string][]string)
+func getSecurity(t string) (map[string][]string, error) {
+	security := make(map[string][]string)
 	p := getparams(strings.TrimSpace(t[len("@Security"):]))
 	if len(p) == 0 {
-		beeLogger.Log.Fatalf("No params for security specified\n")
+		return nil, errors.New("no params for security specified")
 	}
 	security[p[0]] = make([]string, 0)
 	for i := 1; i < len(p); i++ {
 		security[p[0]] = append(security[p[0]], p[i])
 	}
-	return
+	return security, nil
+}
+
+// parseSecurityDefinition parses the params of a @SecurityDefinition annotation:
+// the name and the type of the security scheme followed by the fields of the type
+func parseSecurityDefinition(params string) (string, swagger.Security, error) {
+	var out swagger.Security
+	p := getparams(params)
+	if len(p) < 2 {
+		return "", out, fmt.Errorf("not enough params for security: %d", len(p))
+	}
+	out.Type = p[1]
+	switch out.Type {
+	case "oauth2":
+		if len(p) < 6 {
+			return "", out, fmt.Errorf("not enough params for oauth2: %d", len(p))
+		}
+		if !(p[3] == "implicit" || p[3] == "password" || p[3] == "application" || p[3] == "accessCode") {
+			return "", out, fmt.Errorf("unknown flow type: %s. Possible values are `implicit`, `password`, `application` or `accessCode`", p[3])
+		}
+		out.AuthorizationURL = p[2]
+		out.Flow = p[3]
+		if len(p)%2 != 0 {
+			out.Description = strings.Trim(p[len(p)-1], `" `)
+		}
+		out.Scopes = make(map[string]string)
+		for i := 4; i < len(p)-1; i += 2 {
+			out.Scopes[p[i]] = strings.Trim(p[i+1], `" `)
+		}
+	case "apiKey":
+		if len(p) < 4 {
+			return "", out, fmt.Errorf("not enough params for apiKey: %d", len(p))
+		}
+		if !(p[3] == "header" || p[3] == "query") {
+			return "", out, fmt.Errorf("unknown in type: %s. Possible values are `query` or `header`", p[3])
+		}
+		out.Name = p[2]
+		out.In = p[3]
+		if len(p) > 4 {
+			out.Description = strings.Trim(p[4], `" `)
+		}
+	case "basic":
+		if len(p) > 2 {
+			out.Description = strings.Trim(p[2], `" `)
+		}
+	default:
+		return "", out, fmt.Errorf("unknown security type: %s. Possible values are `oauth2`, `apiKey` or `basic`", p[1])
+	}
+	return p[0], out, nil
 }
 
 func urlReplace(src string) string {
@@ -1513,55 +1491,24 @@ func checkAndLoadPackage(imports []*ast.ImportSpec, realType, curPkgName string)
 	pkgLoadedCache[pkgPath] = struct{}{}
 }
 
-func modifySwaggerIndexFile(curpath string, rootapiSingle bool, rootapiMap map[string]*swagger.Swagger) {
-	swaggerIndexFilename := "index.html"
-	swaggerIndexFullname := path.Join(curpath, "swagger", swaggerIndexFilename)
-
-	swaggerJsonFilename := "swagger.json"
-	swaggerUIBundleSign := `SwaggerUIBundle(`
-	var swaggerUIBundleSignPass bool
-
-	if _, err := os.Stat(swaggerIndexFullname); !os.IsNotExist(err) {
-		var swaggerJsonUrl string
-		if rootapiSingle {
-			swaggerJsonUrl = fmt.Sprintf(`        url: "%s",`, swaggerJsonFilename)
-		} else {
-			urls := make([]string, 0)
-			for namespace, _ := range rootapiMap {
-				namespace = strings.TrimLeft(namespace, "/")
-				urls = append(urls, fmt.Sprintf(`{url: "%s", name: "%s"}`, path.Join(namespace, swaggerJsonFilename), namespace))
-			}
-			swaggerJsonUrl = fmt.Sprintf(`        urls: [%s],`, strings.Join(urls, ", "))
-		}
-
-		var indexFileContent string
-		if f, err := os.Open(swaggerIndexFullname); err == nil {
-			defer f.Close()
-
-			buf := bufio.NewReader(f)
-			for {
-				line, _, c := buf.ReadLine()
-				if c == io.EOF {
-					break
-				}
-
-				if strings.Contains(string(line), swaggerUIBundleSign) {
-					swaggerUIBundleSignPass = true
-				}
-				if swaggerUIBundleSignPass && (strings.Contains(string(line), "url:") || strings.Contains(string(line), "urls:")) {
-					indexFileContent += swaggerJsonUrl + "\n"
-				} else {
-					indexFileContent += string(line) + "\n"
-				}
-			}
-		}
-
-		if fw, err := os.OpenFile(swaggerIndexFullname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666); err == nil {
-			defer fw.Close()
+func modifySwaggerIndexFile(curpath, swaggerJsonFilename string, rootapiSingle bool, rootapiMap map[string]*swagger.Swagger) {
+	swaggerIndexFullname := path.Join(curpath, "swagger", "index.html")
+	index, err := generated.ReadFile(swaggerIndexFullname)
+	if err != nil {
+		return
+	}
 
-			w := bufio.NewWriter(fw)
-			w.WriteString(indexFileContent)
-			w.Flush()
+	var urls []swaggerui.URL
+	if rootapiSingle {
+		urls = append(urls, swaggerui.URL{URL: swaggerJsonFilename})
+	} else {
+		for namespace := range rootapiMap {
+			namespace = strings.TrimLeft(namespace, "/")
+			urls = append(urls, swaggerui.URL{Name: namespace, URL: path.Join(namespace, swaggerJsonFilename)})
 		}
+		sort.Slice(urls, func(i, j int) bool { return urls[i].Name < urls[j].Name })
+	}
+	if err := generated.WriteFile(swaggerIndexFullname, swaggerui.SetURLs(index, urls)); err != nil {
+		beeLogger.Log.Warnf("Could not update '%s': %s", swaggerIndexFullname, err)
 	}
 }
@@ -0,0 +1,477 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web/swagger"
+)
+
+// Supported specifications of the generated documents
+const (
+	SpecSwagger2 = "swagger2"
+	SpecOpenAPI3 = "openapi3"
+)
+
+const (
+	openAPIVersion = "3.1.0"
+	definitionsRef = "#/definitions/"
+	schemasRef     = "#/components/schemas/"
+	aurlencoded    = "application/x-www-form-urlencoded"
+)
+
+// 将注释解析得到的 Swagger 2.0 文档转换为 OpenAPI 3.1 文档：
+//   - definitions 转换为 components/schemas
+//   - in: body 和 in: formData 参数转换为 requestBody
+//   - @Host、@Schemes 和 BasePath 转换为 servers
+//   - 指针字段转换为可为 null 的类型（基本类型使用 type 数组，引用类型使用 oneOf）
+
+// OpenAPI is the root document object of an OpenAPI 3.1 specification
+type OpenAPI struct {
+	OpenAPI      string                `json:"openapi" yaml:"openapi"`
+	Info         swagger.Information   `json:"info" yaml:"info"`
+	Servers      []Server              `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths        map[string]*PathItem  `json:"paths" yaml:"paths"`
+	Components   *Components           `json:"components,omitempty" yaml:"components,omitempty"`
+	Security     []map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+	Tags         []swagger.Tag         `json:"tags,omitempty" yaml:"tags,omitempty"`
+	ExternalDocs *swagger.ExternalDocs `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+}
+
+// Server represents a server hosting the API
+type Server struct {
+	URL         string `json:"url" yaml:"url"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// PathItem describes the operations available on a single path
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty" yaml:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty" yaml:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty" yaml:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty" yaml:"patch,omitempty"`
+}
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// Parameter describes a single operation parameter, in the path, query, header or cookie
+type Parameter struct {
+	Name        string  `json:"name" yaml:"name"`
+	In          string  `json:"in" yaml:"in"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty" yaml:"required,omitempty"`
+	Content     map[string]MediaType `json:"content" yaml:"content"`
+}
+
+// Response describes a single response of an operation
+type Response struct {
+	Description string               `json:"description" yaml:"description"`
+	Content     map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// MediaType describes the content of a request or a response for a media type
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// Components holds the reusable objects of the document
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+}
+
+// Schema is a JSON Schema (draft 2020-12) object. Type is either a string
+// or a list of strings such as ["string", "null"].
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Type                 interface{}        `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Default              interface{}        `json:"default,omitempty" yaml:"default,omitempty"`
+	Example              interface{}        `json:"example,omitempty" yaml:"example,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+}
+
+// SecurityScheme defines a security scheme used by the operations
+type SecurityScheme struct {
+	Type        string      `json:"type" yaml:"type"` // apiKey, http or oauth2
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Name        string      `json:"name,omitempty" yaml:"name,omitempty"`
+	In          string      `json:"in,omitempty" yaml:"in,omitempty"`
+	Scheme      string      `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	Flows       *OAuthFlows `json:"flows,omitempty" yaml:"flows,omitempty"`
+}
+
+// OAuthFlows lists the OAuth flows of an oauth2 security scheme
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty" yaml:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty" yaml:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty" yaml:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty" yaml:"authorizationCode,omitempty"`
+}
+
+// OAuthFlow describes an OAuth flow
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty" yaml:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty" yaml:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes" yaml:"scopes"`
+}
+
+// toOpenAPI3 converts a Swagger 2.0 document to an OpenAPI 3.1 document
+func toOpenAPI3(api *swagger.Swagger) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI:      openAPIVersion,
+		Info:         api.Infos,
+		Servers:      servers(api),
+		Paths:        make(map[string]*PathItem, len(api.Paths)),
+		Security:     api.Security,
+		Tags:         api.Tags,
+		ExternalDocs: api.ExternalDocs,
+	}
+	for p, item := range api.Paths {
+		doc.Paths[p] = &PathItem{
+			Get:     convertOperation(api, item.Get),
+			Put:     convertOperation(api, item.Put),
+			Post:    convertOperation(api, item.Post),
+			Delete:  convertOperation(api, item.Delete),
+			Options: convertOperation(api, item.Options),
+			Head:    convertOperation(api, item.Head),
+			Patch:   convertOperation(api, item.Patch),
+		}
+	}
+
+	components := &Components{}
+	if len(api.Definitions) > 0 {
+		components.Schemas = make(map[string]*Schema, len(api.Definitions))
+		for name, def := range api.Definitions {
+			def := def
+			components.Schemas[name] = convertSchema(&def, nullableProperties[name])
+		}
+	}
+	if len(api.SecurityDefinitions) > 0 {
+		components.SecuritySchemes = make(map[string]SecurityScheme, len(api.SecurityDefinitions))
+		for name, sec := range api.SecurityDefinitions {
+			components.SecuritySchemes[name] = convertSecurity(sec)
+		}
+	}
+	if components.Schemas != nil || components.SecuritySchemes != nil {
+		doc.Components = components
+	}
+	return doc
+}
+
+// servers builds the server objects from @Host, @Schemes and the base path
+func servers(api *swagger.Swagger) []Server {
+	if api.Host == "" {
+		if api.BasePath == "" {
+			return nil
+		}
+		return []Server{{URL: api.BasePath}}
+	}
+	schemes := api.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	var list []Server
+	for _, scheme := range schemes {
+		list = append(list, Server{URL: strings.TrimSpace(scheme) + "://" + api.Host + api.BasePath})
+	}
+	return list
+}
+
+func convertOperation(api *swagger.Swagger, op *swagger.Operation) *Operation {
+	if op == nil {
+		return nil
+	}
+	out := &Operation{
+		Tags:        op.Tags,
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: op.OperationID,
+		Security:    op.Security,
+		Deprecated:  op.Deprecated,
+	}
+	consumes := op.Consumes
+	if len(consumes) == 0 {
+		consumes = api.Consumes
+	}
+	produces := op.Produces
+	if len(produces) == 0 {
+		produces = api.Produces
+	}
+
+	var form []swagger.Parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "body":
+			out.RequestBody = &RequestBody{
+				Description: p.Description,
+				Required:    p.Required,
+				Content:     content(consumes, ajson, convertParamSchema(p)),
+			}
+		case "formData":
+			form = append(form, p)
+		default:
+			out.Parameters = append(out.Parameters, Parameter{
+				Name:        p.Name,
+				In:          p.In,
+				Description: p.Description,
+				Required:    p.Required || p.In == "path",
+				Schema:      convertParamSchema(p),
+			})
+		}
+	}
+	if len(form) > 0 && out.RequestBody == nil {
+		out.RequestBody = formBody(form)
+	}
+
+	if len(op.Responses) > 0 {
+		out.Responses = make(map[string]Response, len(op.Responses))
+		for code, resp := range op.Responses {
+			r := Response{Description: resp.Description}
+			if resp.Schema != nil {
+				r.Content = content(produces, ajson, convertSchema(resp.Schema, nil))
+			}
+			out.Responses[code] = r
+		}
+	}
+	return out
+}
+
+// formBody converts the formData parameters to a request body,
+// multipart/form-data if one of them is a file.
+func formBody(params []swagger.Parameter) *RequestBody {
+	mediaType := aurlencoded
+	schema := &Schema{Type: astTypeObject, Properties: make(map[string]*Schema, len(params))}
+	body := &RequestBody{}
+	for _, p := range params {
+		s := convertParamSchema(p)
+		if p.Type == "file" {
+			mediaType = aform
+			s = &Schema{Type: "string", Format: "binary"}
+		}
+		s.Description = p.Description
+		schema.Properties[p.Name] = s
+		if p.Required {
+			schema.Required = append(schema.Required, p.Name)
+			body.Required = true
+		}
+	}
+	sort.Strings(schema.Required)
+	body.Content = map[string]MediaType{mediaType: {Schema: schema}}
+	return body
+}
+
+// content returns the content of a request or a response for the given media types
+func content(mediaTypes []string, fallback string, schema *Schema) map[string]MediaType {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{fallback}
+	}
+	c := make(map[string]MediaType, len(mediaTypes))
+	for _, t := range mediaTypes {
+		c[t] = MediaType{Schema: schema}
+	}
+	return c
+}
+
+func convertParamSchema(p swagger.Parameter) *Schema {
+	if p.Schema != nil {
+		return convertSchema(p.Schema, nil)
+	}
+	s := &Schema{Format: convertFormat(p.Format), Default: p.Default}
+	if p.Type != "" {
+		s.Type = p.Type
+	}
+	if p.Items != nil {
+		s.Items = convertParamItems(p.Items)
+	}
+	return s
+}
+
+func convertParamItems(items *swagger.ParameterItems) *Schema {
+	s := &Schema{Format: convertFormat(items.Format)}
+	if items.Type != "" {
+		s.Type = items.Type
+	}
+	if items.Default != "" {
+		s.Default = items.Default
+	}
+	if len(items.Items) > 0 {
+		s.Items = convertParamItems(items.Items[0])
+	}
+	return s
+}
+
+// convertSchema converts a schema, nullable being the names of its
+// properties generated from pointer fields.
+func convertSchema(in *swagger.Schema, nullable map[string]bool) *Schema {
+	if in.Ref != "" {
+		return &Schema{Ref: convertRef(in.Ref), Description: in.Description}
+	}
+	s := &Schema{
+		Title:       in.Title,
+		Format:      convertFormat(in.Format),
+		Description: in.Description,
+		Required:    in.Required,
+		Enum:        in.Enum,
+		Example:     in.Example,
+	}
+	if in.Type != "" {
+		s.Type = in.Type
+	}
+	if in.Items != nil {
+		s.Items = convertSchema(in.Items, nil)
+	}
+	if len(in.Properties) > 0 {
+		s.Properties = make(map[string]*Schema, len(in.Properties))
+		for name, p := range in.Properties {
+			prop := convertProperty(p)
+			if nullable[name] {
+				prop = nullableSchema(prop)
+			}
+			s.Properties[name] = prop
+		}
+	}
+	return s
+}
+
+func convertProperty(p swagger.Propertie) *Schema {
+	if p.Ref != "" {
+		return &Schema{Ref: convertRef(p.Ref), Description: p.Description}
+	}
+	s := &Schema{
+		Title:       p.Title,
+		Format:      convertFormat(p.Format),
+		Description: p.Description,
+		Default:     p.Default,
+		Example:     p.Example,
+		Required:    p.Required,
+		ReadOnly:    p.ReadOnly,
+	}
+	if p.Type != "" {
+		s.Type = p.Type
+	}
+	if p.Items != nil {
+		s.Items = convertProperty(*p.Items)
+	}
+	if p.AdditionalProperties != nil {
+		s.Type = astTypeObject
+		s.AdditionalProperties = convertProperty(*p.AdditionalProperties)
+	}
+	if len(p.Properties) > 0 {
+		s.Properties = make(map[string]*Schema, len(p.Properties))
+		for name, sub := range p.Properties {
+			s.Properties[name] = convertProperty(sub)
+		}
+	}
+	return s
+}
+
+// nullableSchema allows null besides the values of s, e.g. for pointer fields
+func nullableSchema(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{
+			Description: s.Description,
+			OneOf:       []*Schema{{Ref: s.Ref}, {Type: "null"}},
+		}
+	}
+	if t, ok := s.Type.(string); ok && t != "" {
+		s.Type = []string{t, "null"}
+	}
+	return s
+}
+
+func convertRef(ref string) string {
+	if strings.HasPrefix(ref, definitionsRef) {
+		return schemasRef + ref[len(definitionsRef):]
+	}
+	return ref
+}
+
+// convertFormat returns the registered format name, e.g. date-time for datetime
+func convertFormat(format string) string {
+	if format == "datetime" {
+		return "date-time"
+	}
+	return format
+}
+
+func convertSecurity(sec swagger.Security) SecurityScheme {
+	out := SecurityScheme{Type: sec.Type, Description: sec.Description}
+	switch sec.Type {
+	case "basic":
+		out.Type = "http"
+		out.Scheme = "basic"
+	case "apiKey":
+		out.Name = sec.Name
+		out.In = sec.In
+	case "oauth2":
+		flow := &OAuthFlow{AuthorizationURL: sec.AuthorizationURL, TokenURL: sec.TokenURL, Scopes: sec.Scopes}
+		if flow.Scopes == nil {
+			flow.Scopes = map[string]string{}
+		}
+		out.Flows = &OAuthFlows{}
+		switch sec.Flow {
+		case "implicit":
+			out.Flows.Implicit = flow
+		case "password":
+			out.Flows.Password = tokenFlow(flow)
+		case "application":
+			out.Flows.ClientCredentials = tokenFlow(flow)
+		case "accessCode":
+			out.Flows.AuthorizationCode = flow
+		}
+	}
+	return out
+}
+
+// tokenFlow moves the URL of a password or client credentials flow, given
+// as the authorization URL in @SecurityDefinition, to the token URL
+func tokenFlow(flow *OAuthFlow) *OAuthFlow {
+	if flow.TokenURL == "" {
+		flow.TokenURL, flow.AuthorizationURL = flow.AuthorizationURL, ""
+	}
+	return flow
+}
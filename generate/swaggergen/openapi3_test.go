@@ -0,0 +1,444 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web/swagger"
+)
+
+func TestToOpenAPI3(t *testing.T) {
+	testCases := []struct {
+		name     string
+		api      swagger.Swagger
+		nullable map[string]map[string]bool
+		expected string
+	}{
+		{
+			name: "request bodies",
+			api: swagger.Swagger{
+				Consumes: []string{"application/json", "application/xml"},
+				Paths: map[string]*swagger.Item{
+					"/user/{id}": {
+						Put: &swagger.Operation{
+							OperationID: "UserController.Put",
+							Parameters: []swagger.Parameter{
+								{In: "path", Name: "id", Type: "integer", Format: "int64"},
+								{In: "query", Name: "tags", Type: "array", Items: &swagger.ParameterItems{Type: "string"}},
+								{In: "body", Name: "body", Description: "the user", Required: true, Schema: &swagger.Schema{Ref: "#/definitions/models.User"}},
+							},
+							Responses: map[string]swagger.Response{
+								"200": {Description: "the user", Schema: &swagger.Schema{Ref: "#/definitions/models.User"}},
+								"404": {Description: "not found"},
+							},
+						},
+					},
+					"/user/avatar": {
+						Post: &swagger.Operation{
+							Consumes: []string{"multipart/form-data"},
+							Parameters: []swagger.Parameter{
+								{In: "formData", Name: "name", Type: "string", Required: true, Description: "the name"},
+								{In: "formData", Name: "file", Type: "file", Description: "the avatar"},
+							},
+						},
+					},
+					"/user/login": {
+						Post: &swagger.Operation{
+							Parameters: []swagger.Parameter{
+								{In: "formData", Name: "username", Type: "string", Required: true},
+								{In: "formData", Name: "password", Type: "string", Required: true},
+							},
+						},
+					},
+				},
+			},
+			expected: `{
+  "openapi": "3.1.0",
+  "info": {
+    "contact": {}
+  },
+  "paths": {
+    "/user/avatar": {
+      "post": {
+        "requestBody": {
+          "required": true,
+          "content": {
+            "multipart/form-data": {
+              "schema": {
+                "type": "object",
+                "required": [
+                  "name"
+                ],
+                "properties": {
+                  "file": {
+                    "type": "string",
+                    "format": "binary",
+                    "description": "the avatar"
+                  },
+                  "name": {
+                    "type": "string",
+                    "description": "the name"
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/user/login": {
+      "post": {
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/x-www-form-urlencoded": {
+              "schema": {
+                "type": "object",
+                "required": [
+                  "password",
+                  "username"
+                ],
+                "properties": {
+                  "password": {
+                    "type": "string"
+                  },
+                  "username": {
+                    "type": "string"
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/user/{id}": {
+      "put": {
+        "operationId": "UserController.Put",
+        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "tags",
+            "in": "query",
+            "schema": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            }
+          }
+        ],
+        "requestBody": {
+          "description": "the user",
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/models.User"
+              }
+            },
+            "application/xml": {
+              "schema": {
+                "$ref": "#/components/schemas/models.User"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "the user",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/models.User"
+                }
+              }
+            }
+          },
+          "404": {
+            "description": "not found"
+          }
+        }
+      }
+    }
+  }
+}`,
+		},
+		{
+			name: "nullable properties",
+			api: swagger.Swagger{
+				Definitions: map[string]swagger.Schema{
+					"models.User": {
+						Title:    "User",
+						Type:     "object",
+						Required: []string{"name"},
+						Properties: map[string]swagger.Propertie{
+							"name":       {Type: "string"},
+							"email":      {Type: "string", Description: "the email"},
+							"profile":    {Ref: "#/definitions/models.Profile", Description: "the profile"},
+							"created_at": {Type: "string", Format: "datetime"},
+							"labels":     {AdditionalProperties: &swagger.Propertie{Type: "string"}},
+						},
+					},
+					"models.Status": {
+						Title: "Status",
+						Type:  "string",
+						Enum:  []interface{}{"draft", "published"},
+					},
+				},
+			},
+			nullable: map[string]map[string]bool{"models.User": {"email": true, "profile": true}},
+			expected: `{
+  "openapi": "3.1.0",
+  "info": {
+    "contact": {}
+  },
+  "paths": {},
+  "components": {
+    "schemas": {
+      "models.Status": {
+        "title": "Status",
+        "type": "string",
+        "enum": [
+          "draft",
+          "published"
+        ]
+      },
+      "models.User": {
+        "title": "User",
+        "type": "object",
+        "required": [
+          "name"
+        ],
+        "properties": {
+          "created_at": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "email": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "description": "the email"
+          },
+          "labels": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            }
+          },
+          "name": {
+            "type": "string"
+          },
+          "profile": {
+            "description": "the profile",
+            "oneOf": [
+              {
+                "$ref": "#/components/schemas/models.Profile"
+              },
+              {
+                "type": "null"
+              }
+            ]
+          }
+        }
+      }
+    }
+  }
+}`,
+		},
+		{
+			name: "servers",
+			api: swagger.Swagger{
+				Host:     "api.example.com",
+				BasePath: "/v1",
+				Schemes:  []string{"https", " http"},
+			},
+			expected: `{
+  "openapi": "3.1.0",
+  "info": {
+    "contact": {}
+  },
+  "servers": [
+    {
+      "url": "https://api.example.com/v1"
+    },
+    {
+      "url": "http://api.example.com/v1"
+    }
+  ],
+  "paths": {}
+}`,
+		},
+		{
+			name: "servers without host",
+			api: swagger.Swagger{
+				BasePath: "/v1",
+			},
+			expected: `{
+  "openapi": "3.1.0",
+  "info": {
+    "contact": {}
+  },
+  "servers": [
+    {
+      "url": "/v1"
+    }
+  ],
+  "paths": {}
+}`,
+		},
+		{
+			name: "default scheme",
+			api: swagger.Swagger{
+				Host: "localhost:8080",
+			},
+			expected: `{
+  "openapi": "3.1.0",
+  "info": {
+    "contact": {}
+  },
+  "servers": [
+    {
+      "url": "http://localhost:8080"
+    }
+  ],
+  "paths": {}
+}`,
+		},
+		{
+			name: "security schemes",
+			api: swagger.Swagger{
+				Security: []map[string][]string{{"token": {}}},
+				SecurityDefinitions: map[string]swagger.Security{
+					"basic":    {Type: "basic", Description: "the users"},
+					"token":    {Type: "apiKey", Name: "Authorization", In: "header"},
+					"implicit": {Type: "oauth2", Flow: "implicit", AuthorizationURL: "https://example.com/auth", Scopes: map[string]string{"read": "read the data"}},
+					"password": {Type: "oauth2", Flow: "password", AuthorizationURL: "https://example.com/token"},
+					"client":   {Type: "oauth2", Flow: "application", TokenURL: "https://example.com/token"},
+					"code":     {Type: "oauth2", Flow: "accessCode", AuthorizationURL: "https://example.com/auth", TokenURL: "https://example.com/token"},
+				},
+				Paths: map[string]*swagger.Item{
+					"/user": {
+						Get: &swagger.Operation{Security: []map[string][]string{{"implicit": {"read"}}}},
+					},
+				},
+			},
+			expected: `{
+  "openapi": "3.1.0",
+  "info": {
+    "contact": {}
+  },
+  "paths": {
+    "/user": {
+      "get": {
+        "security": [
+          {
+            "implicit": [
+              "read"
+            ]
+          }
+        ]
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {
+      "basic": {
+        "type": "http",
+        "description": "the users",
+        "scheme": "basic"
+      },
+      "client": {
+        "type": "oauth2",
+        "flows": {
+          "clientCredentials": {
+            "tokenUrl": "https://example.com/token",
+            "scopes": {}
+          }
+        }
+      },
+      "code": {
+        "type": "oauth2",
+        "flows": {
+          "authorizationCode": {
+            "authorizationUrl": "https://example.com/auth",
+            "tokenUrl": "https://example.com/token",
+            "scopes": {}
+          }
+        }
+      },
+      "implicit": {
+        "type": "oauth2",
+        "flows": {
+          "implicit": {
+            "authorizationUrl": "https://example.com/auth",
+            "scopes": {
+              "read": "read the data"
+            }
+          }
+        }
+      },
+      "password": {
+        "type": "oauth2",
+        "flows": {
+          "password": {
+            "tokenUrl": "https://example.com/token",
+            "scopes": {}
+          }
+        }
+      },
+      "token": {
+        "type": "apiKey",
+        "name": "Authorization",
+        "in": "header"
+      }
+    }
+  },
+  "security": [
+    {
+      "token": []
+    }
+  ]
+}`,
+		},
+	}
+
+	defer func(saved map[string]map[string]bool) { nullableProperties = saved }(nullableProperties)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nullableProperties = tc.nullable
+			doc, err := json.MarshalIndent(toOpenAPI3(&tc.api), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(doc); got != strings.TrimSpace(tc.expected) {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,891 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/beego/v2/server/web/swagger"
+)
+
+// 路由发现：从 routers 包的 init 函数（以及包内没有被调用的导出函数）开始，
+// 跟踪所有的路由注册，包括：
+//   - NewNamespace / NSNamespace / NSInclude / NSRouter，以及 Namespace 的 Include、Router、Namespace 方法
+//   - 顶层的 Include 和 Router
+//   - 包内的辅助函数调用，以及返回命名空间选项的辅助函数
+//   - 保存命名空间或控制器的变量
+// 无法解析的路由注册会在生成文档后统一报告。
+
+// beegoImportPaths are the packages registering routes
+var beegoImportPaths = map[string]bool{
+	"github.com/beego/beego/v2/server/web": true,
+	"github.com/beego/beego/v2":            true,
+	"github.com/astaxie/beego":             true,
+}
+
+// httpMethods are the methods of a route mapped with "*"
+var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// controllerMethods holds the operations of all controller methods, with or without @router
+var controllerMethods map[string]map[string]*swagger.Operation // controller: method: operation
+
+// annotatedRoutes holds the paths of the @router annotations, with the constraints of their parameters
+var annotatedRoutes map[string]map[string]string // controller: swagger path: annotated path
+
+// namespace is the context of the routes registered in a namespace
+type namespace struct {
+	rootapi  *swagger.Swagger
+	base     string // path relative to the base path of rootapi
+	prefix   string // full path of the namespace
+	topLevel bool   // the routes are registered outside of a namespace, with absolute paths
+}
+
+// RouteInfo is a route registered by the routers package
+type RouteInfo struct {
+	Method     string // HTTP method
+	Path       string // full path, e.g. /v1/object/:objectId
+	Package    string // package path of the controller
+	Controller string // type name of the controller
+	Func       string // controller method
+	Source     string // position of the registration, e.g. routers/router.go:21
+}
+
+// routeTable holds the routes found by discoverRoutes, in the order of registration
+var routeTable []RouteInfo
+
+type routeDiscoverer struct {
+	fset       *token.FileSet
+	funcs      map[string]*ast.FuncDecl // functions of the routers package
+	vars       map[string]ast.Expr      // package-level variables of the routers package
+	aliases    map[string]bool          // local names of the beego packages
+	nsVars     map[*ast.Object]*namespace
+	pkgNsVars  map[string]*namespace // package-level namespace variables, used from the other files
+	walking    map[*ast.FuncDecl]bool
+	topLevel   []topLevelRoute
+	unresolved []string
+}
+
+// topLevelRoute is a route registered outside of a namespace. They are
+// added after the namespaces, which set the base paths of the documents.
+type topLevelRoute struct {
+	call *ast.CallExpr
+	fn   func(ns *namespace)
+}
+
+// Routes returns the routes registered by the routers package of the
+// application in curpath, in the order of registration.
+func Routes(curpath string) []RouteInfo {
+	analyseDocs(curpath)
+	return routeTable
+}
+
+// parseRouterFiles parses the files of the routers package, router.go first
+func parseRouterFiles(fset *token.FileSet, curpath string) []*ast.File {
+	dir := filepath.Join(curpath, "routers")
+	names, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	sort.SliceStable(names, func(i, j int) bool {
+		return filepath.Base(names[i]) == "router.go" && filepath.Base(names[j]) != "router.go"
+	})
+	var files []*ast.File
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			beeLogger.Log.Fatalf("Error while parsing %s: %s", filepath.Base(name), err)
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		beeLogger.Log.Fatalf("No Go files found in '%s'", dir)
+	}
+	return files
+}
+
+// discoverRoutes adds the routes registered by the routers package to the documents
+func discoverRoutes(fset *token.FileSet, files []*ast.File) {
+	routeTable = nil
+	d := &routeDiscoverer{
+		fset:      fset,
+		funcs:     map[string]*ast.FuncDecl{},
+		vars:      map[string]ast.Expr{},
+		aliases:   map[string]bool{},
+		nsVars:    map[*ast.Object]*namespace{},
+		pkgNsVars: map[string]*namespace{},
+		walking:   map[*ast.FuncDecl]bool{},
+	}
+	var inits []*ast.FuncDecl
+	var varDecls []*ast.GenDecl
+	for _, f := range files {
+		for _, im := range f.Imports {
+			p := strings.Trim(im.Path.Value, `"`)
+			if !beegoImportPaths[p] {
+				continue
+			}
+			if im.Name != nil {
+				d.aliases[im.Name.Name] = true
+			} else {
+				d.aliases[path.Base(p)] = true
+			}
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv != nil {
+					continue
+				}
+				if decl.Name.Name == "init" {
+					inits = append(inits, decl)
+				} else {
+					d.funcs[decl.Name.Name] = decl
+				}
+			case *ast.GenDecl:
+				if decl.Tok != token.VAR {
+					continue
+				}
+				varDecls = append(varDecls, decl)
+				for _, spec := range decl.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, name := range vs.Names {
+						if i < len(vs.Values) {
+							d.vars[name.Name] = vs.Values[i]
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Package-level variables are initialized before the init functions
+	for _, decl := range varDecls {
+		d.walk(decl)
+	}
+	for _, fn := range inits {
+		d.walkFunc(fn)
+	}
+	// Exported functions which are not called in the package, such as a
+	// Register function called by main, are entry points too.
+	called := d.calledFuncs()
+	var entries []string
+	for name, fn := range d.funcs {
+		if ast.IsExported(name) && !called[name] && fn.Body != nil {
+			entries = append(entries, name)
+		}
+	}
+	sort.Strings(entries)
+	for _, name := range entries {
+		d.walkFunc(d.funcs[name])
+	}
+
+	for _, r := range d.topLevel {
+		ns := &namespace{rootapi: d.rootapi(""), topLevel: true}
+		r.fn(ns)
+	}
+	d.report()
+}
+
+// calledFuncs returns the names of the functions of the package which are called in the package
+func (d *routeDiscoverer) calledFuncs() map[string]bool {
+	called := map[string]bool{}
+	for _, fn := range d.funcs {
+		if fn.Body == nil {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if ce, ok := n.(*ast.CallExpr); ok {
+				if id, ok := ce.Fun.(*ast.Ident); ok {
+					called[id.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return called
+}
+
+// rootapi returns the document of the namespace version
+func (d *routeDiscoverer) rootapi(version string) *swagger.Swagger {
+	if rootapiSingle {
+		return &rootapiDefault
+	}
+	if rootapi, ok := rootapiMap[version]; ok {
+		return rootapi
+	}
+	rootapi := &swagger.Swagger{
+		Infos:          swagger.Information{},
+		SwaggerVersion: "2.0",
+	}
+	rootapiMap[version] = rootapi
+	return rootapi
+}
+
+func (d *routeDiscoverer) walkFunc(fn *ast.FuncDecl) {
+	if fn.Body == nil || d.walking[fn] {
+		return
+	}
+	d.walking[fn] = true
+	defer delete(d.walking, fn)
+	d.walk(fn.Body)
+}
+
+// walk follows the route registrations of the statements
+func (d *routeDiscoverer) walk(node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			// ns := web.NewNamespace(...)
+			handled := false
+			for i, rhs := range n.Rhs {
+				ce, ok := rhs.(*ast.CallExpr)
+				if !ok || d.beegoFunc(ce) != "NewNamespace" || i >= len(n.Lhs) {
+					continue
+				}
+				ns := d.newNamespace(ce)
+				if id, ok := n.Lhs[i].(*ast.Ident); ok && id.Obj != nil {
+					d.nsVars[id.Obj] = ns
+				}
+				handled = true
+			}
+			return !handled
+		case *ast.ValueSpec:
+			// var ns = web.NewNamespace(...)
+			handled := false
+			for i, value := range n.Values {
+				ce, ok := value.(*ast.CallExpr)
+				if !ok || d.beegoFunc(ce) != "NewNamespace" || i >= len(n.Names) {
+					continue
+				}
+				ns := d.newNamespace(ce)
+				d.nsVars[n.Names[i].Obj] = ns
+				d.pkgNsVars[n.Names[i].Name] = ns
+				handled = true
+			}
+			return !handled
+		case *ast.CallExpr:
+			return d.call(n)
+		}
+		return true
+	})
+}
+
+// call handles a call outside of a namespace and reports whether its arguments must be walked
+func (d *routeDiscoverer) call(ce *ast.CallExpr) bool {
+	if ns, method := d.namespaceMethod(ce); ns != nil {
+		d.namespaceCall(ns, method, ce)
+		return false
+	}
+	switch name := d.beegoFunc(ce); name {
+	case "NewNamespace":
+		d.newNamespace(ce)
+		return false
+	case "Include":
+		d.topLevel = append(d.topLevel, topLevelRoute{ce, func(ns *namespace) { d.include(ns, ce.Args, ce) }})
+		return false
+	case "Router":
+		d.topLevel = append(d.topLevel, topLevelRoute{ce, func(ns *namespace) { d.router(ns, ce) }})
+		return false
+	case "AutoRouter", "AutoPrefix", "Get", "Post", "Put", "Patch", "Delete", "Head", "Options", "Any", "Handler",
+		"CtrlGet", "CtrlPost", "CtrlPut", "CtrlPatch", "CtrlDelete", "CtrlHead", "CtrlOptions", "CtrlAny":
+		d.unresolve(ce, fmt.Sprintf("routes registered with %s are not documented", name))
+		return false
+	}
+	if id, ok := ce.Fun.(*ast.Ident); ok {
+		if fn, ok := d.funcs[id.Name]; ok {
+			d.bindParams(fn, ce.Args)
+			d.walkFunc(fn)
+		}
+	}
+	return true
+}
+
+// bindParams binds the namespaces passed to a helper function to its parameters,
+// e.g. registerAdmin(ns) with func registerAdmin(ns *web.Namespace)
+func (d *routeDiscoverer) bindParams(fn *ast.FuncDecl, args []ast.Expr) {
+	i := 0
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			if i < len(args) && name.Obj != nil {
+				if id, ok := args[i].(*ast.Ident); ok {
+					if ns, ok := d.nsVars[id.Obj]; ok && id.Obj != nil {
+						d.nsVars[name.Obj] = ns
+					} else if ns, ok := d.pkgNsVars[id.Name]; ok && id.Obj == nil {
+						d.nsVars[name.Obj] = ns
+					}
+				}
+			}
+			i++
+		}
+		if len(field.Names) == 0 {
+			i++
+		}
+	}
+}
+
+// newNamespace adds the routes of a top-level namespace
+func (d *routeDiscoverer) newNamespace(ce *ast.CallExpr) *namespace {
+	version, ok := d.stringValue(firstArg(ce))
+	if !ok {
+		d.unresolve(ce, "the prefix of the namespace is not a constant string")
+	}
+	rootapi := d.rootapi(version)
+	if rootapi.BasePath == "" {
+		rootapi.BasePath = version
+	}
+	ns := &namespace{rootapi: rootapi, prefix: version}
+	if rootapi.BasePath != version {
+		// 多个命名空间合并到同一个文档中时，路径相对于第一个命名空间
+		ns.base = version
+	}
+	d.options(ns, restArgs(ce), ce)
+	return ns
+}
+
+// namespaceMethod returns the namespace of a call such as ns.Include(...)
+func (d *routeDiscoverer) namespaceMethod(ce *ast.CallExpr) (*namespace, string) {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, ""
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, ""
+	}
+	if id.Obj == nil {
+		return d.pkgNsVars[id.Name], sel.Sel.Name
+	}
+	return d.nsVars[id.Obj], sel.Sel.Name
+}
+
+func (d *routeDiscoverer) namespaceCall(ns *namespace, method string, ce *ast.CallExpr) {
+	switch method {
+	case "Include":
+		d.include(ns, ce.Args, ce)
+	case "Router":
+		d.router(ns, ce)
+	case "Namespace":
+		for _, arg := range ce.Args {
+			sub, ok := d.resolve(arg).(*ast.CallExpr)
+			if !ok || d.beegoFunc(sub) != "NewNamespace" {
+				d.unresolve(arg, "cannot resolve the nested namespace")
+				continue
+			}
+			prefix, _ := d.stringValue(firstArg(sub))
+			nested := &namespace{rootapi: ns.rootapi, base: ns.base + prefix, prefix: ns.prefix + prefix}
+			if cname := d.firstInclude(restArgs(sub)); cname != "" {
+				addTag(ns.rootapi, strings.Trim(nested.base, "/"), cname)
+			}
+			d.options(nested, restArgs(sub), sub)
+		}
+	case "AutoRouter", "Get", "Post", "Put", "Patch", "Delete", "Head", "Options", "Any", "Handler":
+		d.unresolve(ce, fmt.Sprintf("routes registered with %s are not documented", method))
+	}
+}
+
+// options adds the routes of the options of a namespace
+func (d *routeDiscoverer) options(ns *namespace, opts []ast.Expr, at ast.Node) {
+	for _, opt := range opts {
+		d.option(ns, opt, at)
+	}
+}
+
+func (d *routeDiscoverer) option(ns *namespace, opt ast.Expr, at ast.Node) {
+	switch expr := d.resolve(opt).(type) {
+	case *ast.CompositeLit:
+		// []web.LinkNamespace{...}
+		d.options(ns, expr.Elts, expr)
+		return
+	case *ast.CallExpr:
+		if id, ok := expr.Fun.(*ast.Ident); ok {
+			if id.Name == "append" && len(expr.Args) > 0 {
+				d.options(ns, expr.Args, expr)
+				return
+			}
+			if fn, ok := d.funcs[id.Name]; ok {
+				d.helperOptions(ns, fn)
+				return
+			}
+		}
+		switch name := d.beegoFunc(expr); name {
+		case "NSNamespace":
+			prefix, ok := d.stringValue(firstArg(expr))
+			if !ok {
+				d.unresolve(expr, "the prefix of the namespace is not a constant string")
+			}
+			sub := &namespace{rootapi: ns.rootapi, base: ns.base + prefix, prefix: ns.prefix + prefix}
+			if cname := d.firstInclude(restArgs(expr)); cname != "" {
+				addTag(ns.rootapi, strings.Trim(sub.base, "/"), cname)
+			}
+			d.options(sub, restArgs(expr), expr)
+		case "NSInclude":
+			for _, cname := range d.include(ns, expr.Args, expr) {
+				if ns.base == "" {
+					// if the NSInclude has no prefix, we use the controllername as the tag
+					addTag(ns.rootapi, cname, cname)
+				}
+			}
+		case "NSRouter":
+			d.router(ns, expr)
+		case "NSBefore", "NSAfter", "NSCond", "NSFilter":
+		case "NSAutoRouter", "NSGet", "NSPost", "NSPut", "NSPatch", "NSDelete", "NSHead", "NSOptions", "NSAny", "NSHandler",
+			"NSCtrlGet", "NSCtrlPost", "NSCtrlPut", "NSCtrlPatch", "NSCtrlDelete", "NSCtrlHead", "NSCtrlOptions", "NSCtrlAny":
+			d.unresolve(expr, fmt.Sprintf("routes registered with %s are not documented", name))
+		default:
+			d.unresolve(expr, "cannot resolve the namespace option")
+		}
+		return
+	}
+	d.unresolve(opt, "cannot resolve the namespace option")
+}
+
+// helperOptions adds the namespace options returned by a helper function
+func (d *routeDiscoverer) helperOptions(ns *namespace, fn *ast.FuncDecl) {
+	if fn.Body == nil || d.walking[fn] {
+		return
+	}
+	d.walking[fn] = true
+	defer delete(d.walking, fn)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			d.options(ns, n.Results, n)
+		}
+		return true
+	})
+}
+
+// firstInclude returns the first controller included in the options, for the tag of a namespace
+func (d *routeDiscoverer) firstInclude(opts []ast.Expr) string {
+	for _, opt := range opts {
+		if ce, ok := d.resolve(opt).(*ast.CallExpr); ok && d.beegoFunc(ce) == "NSInclude" {
+			for _, arg := range ce.Args {
+				if cname, ok := d.controllerName(arg); ok {
+					return cname
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// include adds the routes annotated with @router of the controllers
+func (d *routeDiscoverer) include(ns *namespace, args []ast.Expr, at ast.Node) []string {
+	var names []string
+	for _, arg := range args {
+		cname, ok := d.controllerName(arg)
+		if !ok {
+			d.unresolve(arg, "cannot resolve the controller")
+			continue
+		}
+		apis, ok := controllerList[cname]
+		if !ok {
+			d.unresolve(arg, fmt.Sprintf("controller %s has no @router annotation", controllerDisplayName(cname)))
+			continue
+		}
+		tag := cname
+		if ns.base != "" {
+			tag = strings.Trim(ns.base, "/")
+		}
+		var routes []string
+		for rt := range apis {
+			routes = append(routes, rt)
+		}
+		sort.Strings(routes)
+		for _, rt := range routes {
+			item := apis[rt]
+			for _, hm := range append(httpMethods, "HEAD", "OPTIONS") {
+				if op := *itemOperations(item)[hm]; op != nil {
+					annotated, ok := annotatedRoutes[cname][rt]
+					if !ok {
+						annotated = rt
+					}
+					full := d.record(ns, hm, annotated, cname, operationFunc(cname, op), arg)
+					checkRoute(hm, full, routePositions(cname, hm, rt, d.fset.Position(arg.Pos())))
+				}
+			}
+			d.addPath(ns, ns.base+rt, taggedItem(item, tag), arg)
+		}
+		names = append(names, cname)
+	}
+	return names
+}
+
+// router adds a route registered with Router or NSRouter, e.g.
+// web.Router("/api/:id", &controllers.ObjectController{}, "get:GetOne;put,patch:Update")
+func (d *routeDiscoverer) router(ns *namespace, ce *ast.CallExpr) {
+	if len(ce.Args) < 2 {
+		d.unresolve(ce, "not enough arguments")
+		return
+	}
+	rt, ok := d.stringValue(ce.Args[0])
+	if !ok {
+		d.unresolve(ce, "the path of the route is not a constant string")
+		return
+	}
+	cname, ok := d.controllerName(ce.Args[1])
+	if !ok {
+		d.unresolve(ce.Args[1], "cannot resolve the controller")
+		return
+	}
+	methods := controllerMethods[cname]
+
+	mapping := map[string]string{} // HTTP method: controller method
+	if len(ce.Args) > 2 {
+		s, ok := d.stringValue(ce.Args[2])
+		if !ok {
+			d.unresolve(ce.Args[2], "the mapping of the route is not a constant string")
+			return
+		}
+		for _, m := range strings.Split(s, ";") {
+			parts := strings.SplitN(m, ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			for _, hm := range strings.Split(parts[0], ",") {
+				hm = strings.ToUpper(strings.TrimSpace(hm))
+				if hm == "*" {
+					for _, any := range httpMethods {
+						if _, ok := mapping[any]; !ok {
+							mapping[any] = strings.TrimSpace(parts[1])
+						}
+					}
+					continue
+				}
+				mapping[hm] = strings.TrimSpace(parts[1])
+			}
+		}
+	} else {
+		// RESTful routes: the methods named after the HTTP methods
+		for _, hm := range append(httpMethods, "HEAD", "OPTIONS") {
+			name := strings.Title(strings.ToLower(hm))
+			if _, ok := methods[name]; ok {
+				mapping[hm] = name
+			}
+		}
+	}
+
+	item := &swagger.Item{}
+	tag := strings.Trim(ns.base, "/")
+	if tag == "" {
+		tag = cname
+	}
+	found := false
+	for _, hm := range append(httpMethods, "HEAD", "OPTIONS") {
+		fn, ok := mapping[hm]
+		if !ok {
+			continue
+		}
+		op, ok := methods[fn]
+		if !ok {
+			d.unresolve(ce, fmt.Sprintf("controller %s has no method %s", controllerDisplayName(cname), fn))
+			continue
+		}
+		full := d.record(ns, hm, rt, cname, fn, ce)
+		checkRoute(hm, full, []token.Position{d.fset.Position(ce.Pos())})
+		o := *op
+		o.Tags = []string{tag}
+		if o.OperationID == "" {
+			o.OperationID = controllerShortName(cname) + "." + fn
+		}
+		if setOperation(item, hm, &o) {
+			found = true
+		}
+	}
+	if found {
+		d.addPath(ns, ns.base+rt, item, ce)
+	}
+}
+
+func (d *routeDiscoverer) addPath(ns *namespace, rt string, item *swagger.Item, at ast.Node) {
+	rootapi := ns.rootapi
+	if ns.topLevel && rootapi.BasePath != "" && rootapi.BasePath != "/" {
+		// 顶层路由是绝对路径，需要转换为相对于文档 basePath 的路径
+		if !strings.HasPrefix(rt, rootapi.BasePath+"/") && rt != rootapi.BasePath {
+			d.unresolve(at, fmt.Sprintf("route %s is outside of the base path %s", rt, rootapi.BasePath))
+			return
+		}
+		rt = strings.TrimPrefix(rt, rootapi.BasePath)
+	}
+	if len(rootapi.Paths) == 0 {
+		rootapi.Paths = make(map[string]*swagger.Item)
+	}
+	rt = urlReplace(rt)
+	prev, ok := rootapi.Paths[rt]
+	if !ok {
+		rootapi.Paths[rt] = item
+		return
+	}
+	// 同一路径注册了多个控制器时合并各个方法
+	merged := *prev
+	ops := itemOperations(&merged)
+	for method, op := range itemOperations(item) {
+		if *op == nil {
+			continue
+		}
+		*ops[method] = *op
+	}
+	rootapi.Paths[rt] = &merged
+}
+
+// record adds a route to the route table and returns its full path, rt is relative to the namespace
+func (d *routeDiscoverer) record(ns *namespace, method, rt, cname, fn string, at ast.Node) string {
+	rt = strings.TrimSuffix(ns.prefix, "/") + rt
+	pos := d.fset.Position(at.Pos())
+	short := controllerShortName(cname)
+	routeTable = append(routeTable, RouteInfo{
+		Method:     method,
+		Path:       rt,
+		Package:    strings.TrimSuffix(cname, short),
+		Controller: short,
+		Func:       fn,
+		Source:     fmt.Sprintf("routers/%s:%d", filepath.Base(pos.Filename), pos.Line),
+	})
+	return rt
+}
+
+// operationFunc returns the controller method of an operation parsed from the controller
+func operationFunc(cname string, op *swagger.Operation) string {
+	for fn, o := range controllerMethods[cname] {
+		if o == op {
+			return fn
+		}
+	}
+	return ""
+}
+
+// controllerName returns the name of a controller such as &controllers.ObjectController{},
+// or controllers.NewObjectController(...) for a constructor named after the controller,
+// as registered by analyseControllerPkg
+func (d *routeDiscoverer) controllerName(expr ast.Expr) (string, bool) {
+	var typ ast.Expr
+	switch e := d.resolve(expr).(type) {
+	case *ast.UnaryExpr:
+		if cl, ok := e.X.(*ast.CompositeLit); ok {
+			typ = cl.Type
+		}
+	case *ast.CompositeLit:
+		typ = e.Type
+	case *ast.CallExpr:
+		switch fun := e.Fun.(type) {
+		case *ast.Ident:
+			if fun.Name == "new" && len(e.Args) == 1 {
+				typ = e.Args[0]
+			}
+		case *ast.SelectorExpr:
+			if name := strings.TrimPrefix(fun.Sel.Name, "New"); name != fun.Sel.Name {
+				typ = &ast.SelectorExpr{X: fun.X, Sel: ast.NewIdent(name)}
+			}
+		}
+	}
+	sel, ok := typ.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	pkg, ok := importlist[fmt.Sprint(sel.X)]
+	if !ok {
+		return "", false
+	}
+	return pkg + sel.Sel.Name, true
+}
+
+// resolve returns the value of a variable, or the expression itself
+func (d *routeDiscoverer) resolve(expr ast.Expr) ast.Expr {
+	for i := 0; i < 10; i++ {
+		id, ok := expr.(*ast.Ident)
+		if !ok {
+			return expr
+		}
+		var value ast.Expr
+		if id.Obj != nil {
+			switch decl := id.Obj.Decl.(type) {
+			case *ast.AssignStmt:
+				for j, lhs := range decl.Lhs {
+					if l, ok := lhs.(*ast.Ident); ok && l.Name == id.Name && j < len(decl.Rhs) {
+						value = decl.Rhs[j]
+					}
+				}
+			case *ast.ValueSpec:
+				for j, name := range decl.Names {
+					if name.Name == id.Name && j < len(decl.Values) {
+						value = decl.Values[j]
+					}
+				}
+			}
+		} else {
+			value = d.vars[id.Name]
+		}
+		if value == nil {
+			return expr
+		}
+		expr = value
+	}
+	return expr
+}
+
+// stringValue returns the value of a constant string expression
+func (d *routeDiscoverer) stringValue(expr ast.Expr) (string, bool) {
+	if expr == nil {
+		return "", false
+	}
+	switch e := d.resolve(expr).(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			s, err := strconv.Unquote(e.Value)
+			return s, err == nil
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			x, ok1 := d.stringValue(e.X)
+			y, ok2 := d.stringValue(e.Y)
+			return x + y, ok1 && ok2
+		}
+	case *ast.Ident:
+		if e.Obj != nil && e.Obj.Kind == ast.Con {
+			if vs, ok := e.Obj.Decl.(*ast.ValueSpec); ok {
+				for j, name := range vs.Names {
+					if name.Name == e.Name && j < len(vs.Values) {
+						return d.stringValue(vs.Values[j])
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// beegoFunc returns the name of the beego function called, e.g. NSInclude for web.NSInclude(...)
+func (d *routeDiscoverer) beegoFunc(ce *ast.CallExpr) string {
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if id, ok := sel.X.(*ast.Ident); ok && d.aliases[id.Name] && (id.Obj == nil) {
+		return sel.Sel.Name
+	}
+	return ""
+}
+
+func (d *routeDiscoverer) unresolve(at ast.Node, reason string) {
+	pos := d.fset.Position(at.Pos())
+	d.unresolved = append(d.unresolved, fmt.Sprintf("%s:%d: %s", filepath.Base(pos.Filename), pos.Line, reason))
+}
+
+// report warns about the route registrations which could not be resolved
+func (d *routeDiscoverer) report() {
+	if len(d.unresolved) == 0 {
+		return
+	}
+	beeLogger.Log.Warnf("%d route registration(s) could not be resolved, they are missing from the docs:", len(d.unresolved))
+	for _, u := range d.unresolved {
+		beeLogger.Log.Warnf("  routers/%s", u)
+	}
+}
+
+// addTag adds the tag of a controller to the document, described by the controller comments
+func addTag(rootapi *swagger.Swagger, name, cname string) {
+	v, ok := controllerComments[cname]
+	if !ok {
+		return
+	}
+	for _, t := range rootapi.Tags {
+		if t.Name == name {
+			return
+		}
+	}
+	rootapi.Tags = append(rootapi.Tags, swagger.Tag{
+		Name:        name,
+		Description: v,
+	})
+}
+
+// taggedItem returns a copy of the item with the tag, a controller can be
+// included in several namespaces
+func taggedItem(item *swagger.Item, tag string) *swagger.Item {
+	tagged := *item
+	for _, op := range itemOperations(&tagged) {
+		if *op != nil {
+			o := **op
+			o.Tags = []string{tag}
+			*op = &o
+		}
+	}
+	return &tagged
+}
+
+// itemOperations returns the operations of an item by HTTP method
+func itemOperations(item *swagger.Item) map[string]**swagger.Operation {
+	return map[string]**swagger.Operation{
+		"GET":     &item.Get,
+		"POST":    &item.Post,
+		"PUT":     &item.Put,
+		"PATCH":   &item.Patch,
+		"DELETE":  &item.Delete,
+		"HEAD":    &item.Head,
+		"OPTIONS": &item.Options,
+	}
+}
+
+// setOperation sets the operation of the HTTP method, it returns false for unknown methods
+func setOperation(item *swagger.Item, method string, op *swagger.Operation) bool {
+	o, ok := itemOperations(item)[method]
+	if ok {
+		*o = op
+	}
+	return ok
+}
+
+// controllerShortName returns the type name of a controller name such as
+// github.com/app/controllersObjectController
+func controllerShortName(cname string) string {
+	i := strings.LastIndex(cname, "/")
+	name := cname[i+1:]
+	for j, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			return name[j:]
+		}
+	}
+	return name
+}
+
+// controllerDisplayName returns a controller name as written in the code, such as controllers.ObjectController
+func controllerDisplayName(cname string) string {
+	short := controllerShortName(cname)
+	return path.Base(strings.TrimSuffix(cname, short)) + "." + short
+}
+
+func firstArg(ce *ast.CallExpr) ast.Expr {
+	if len(ce.Args) == 0 {
+		return nil
+	}
+	return ce.Args[0]
+}
+
+func restArgs(ce *ast.CallExpr) []ast.Expr {
+	if len(ce.Args) < 2 {
+		return nil
+	}
+	return ce.Args[1:]
+}
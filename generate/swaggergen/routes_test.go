@@ -0,0 +1,236 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	beeLogger "github.com/beego/bee/v2/logger"
+)
+
+const routesControllers = `
+package controllers
+
+type UserController struct{}
+
+// @router /:id [get]
+func (c *UserController) Get() {}
+
+// @router / [post]
+func (c *UserController) Post() {}
+
+type AdminController struct{}
+
+// @router /stats [get]
+func (c *AdminController) Stats() {}
+
+type HealthController struct{}
+
+func (c *HealthController) Check() {}
+
+type ObjectController struct{}
+
+// @router /object [get,post]
+func (c *ObjectController) Object() {}
+`
+
+func TestRoutes(t *testing.T) {
+	testCases := []struct {
+		name       string
+		routers    map[string]string
+		expected   []string
+		unresolved []string
+	}{
+		{
+			name: "namespaces",
+			routers: map[string]string{
+				"routers/router.go": `
+package routers
+
+import (
+	"example.com/app/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	ns := beego.NewNamespace("/v1",
+		beego.NSNamespace("/user", beego.NSInclude(&controllers.UserController{})),
+		beego.NSRouter("/health", &controllers.HealthController{}, "get:Check"),
+	)
+	beego.AddNamespace(ns)
+}
+`,
+			},
+			expected: []string{
+				"POST /v1/user/ UserController.Post routers/router.go:11",
+				"GET /v1/user/:id UserController.Get routers/router.go:11",
+				"GET /v1/health HealthController.Check routers/router.go:12",
+			},
+		},
+		{
+			name: "registrations across files",
+			routers: map[string]string{
+				"routers/router.go": `
+package routers
+
+import (
+	"example.com/app/controllers"
+
+	web "github.com/beego/beego/v2/server/web"
+)
+
+var api = web.NewNamespace("/api")
+
+func init() {
+	web.Include(&controllers.ObjectController{})
+}
+`,
+				"routers/admin.go": `
+package routers
+
+import (
+	"example.com/app/controllers"
+
+	web "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	api.Namespace(web.NewNamespace("/admin", web.NSInclude(&controllers.AdminController{})))
+	web.AddNamespace(api)
+}
+`,
+			},
+			expected: []string{
+				"GET /api/admin/stats AdminController.Stats routers/admin.go:10",
+				"GET /object ObjectController.Object routers/router.go:12",
+				"POST /object ObjectController.Object routers/router.go:12",
+			},
+		},
+		{
+			name: "helper functions",
+			routers: map[string]string{
+				"routers/router.go": `
+package routers
+
+import (
+	"example.com/app/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	ns := beego.NewNamespace("/v1", userNamespace(), beego.NSNamespace("/admin", adminOptions()...))
+	beego.AddNamespace(ns)
+	health(ns)
+}
+
+func userNamespace() beego.LinkNamespace {
+	return beego.NSNamespace("/user", beego.NSInclude(&controllers.UserController{}))
+}
+
+func adminOptions() []beego.LinkNamespace {
+	return []beego.LinkNamespace{beego.NSInclude(&controllers.AdminController{})}
+}
+
+func health(ns *beego.Namespace) {
+	ns.Router("/health", &controllers.HealthController{}, "get:Check")
+}
+
+// Register is called by main
+func Register() {
+	beego.AddNamespace(beego.NewNamespace("/v1", beego.NSInclude(&controllers.ObjectController{})))
+}
+`,
+			},
+			expected: []string{
+				"POST /v1/user/ UserController.Post routers/router.go:16",
+				"GET /v1/user/:id UserController.Get routers/router.go:16",
+				"GET /v1/admin/stats AdminController.Stats routers/router.go:20",
+				"GET /v1/health HealthController.Check routers/router.go:24",
+				"GET /v1/object ObjectController.Object routers/router.go:29",
+				"POST /v1/object ObjectController.Object routers/router.go:29",
+			},
+		},
+		{
+			name: "unresolved routes",
+			routers: map[string]string{
+				"routers/router.go": `
+package routers
+
+import (
+	"os"
+
+	"example.com/app/controllers"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+func init() {
+	beego.Router(os.Getenv("HEALTH"), &controllers.HealthController{}, "get:Check")
+	beego.Include(newController())
+	beego.Router("/user", &controllers.UserController{}, "get:Missing")
+	beego.Router("/health", &controllers.HealthController{}, "get:Check")
+}
+
+func newController() *controllers.UserController {
+	return &controllers.UserController{}
+}
+`,
+			},
+			expected: []string{
+				"GET /health HealthController.Check routers/router.go:15",
+			},
+			unresolved: []string{
+				"routers/router.go:12: the path of the route is not a constant string",
+				"routers/router.go:13: cannot resolve the controller",
+				"routers/router.go:14: controller controllers.UserController has no method Missing",
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	beeLogger.Log.SetOutput(&out)
+	defer beeLogger.Log.SetOutput(os.Stdout)
+	unresolvedRegexp := regexp.MustCompile(`routers/\S+:\d+: .*`)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"controllers/controllers.go": routesControllers}
+			for name, content := range tc.routers {
+				files[name] = content
+			}
+			dir := writeApp(t, files)
+			out.Reset()
+
+			var routes []string
+			for _, r := range Routes(dir) {
+				routes = append(routes, fmt.Sprintf("%s %s %s.%s %s", r.Method, r.Path, r.Controller, r.Func, r.Source))
+			}
+			if !reflect.DeepEqual(routes, tc.expected) {
+				t.Errorf("expected the routes:\n%s\ngot:\n%s", strings.Join(tc.expected, "\n"), strings.Join(routes, "\n"))
+			}
+			unresolved := unresolvedRegexp.FindAllString(out.String(), -1)
+			if !reflect.DeepEqual(unresolved, tc.unresolved) {
+				t.Errorf("expected the unresolved routes:\n%s\ngot:\n%s", strings.Join(tc.unresolved, "\n"), out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,725 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/beego/v2/server/web/swagger"
+)
+
+// 基于 go/types 构建模型的 schema，使其与 encoding/json 实际输出的结构一致：
+//   - 支持泛型（例如 Resp[models.User]）、类型别名和匿名结构体
+//   - 匿名嵌入的结构体（以及 json:",inline"）的字段按 encoding/json 的规则提升
+//   - 实现了 json.Marshaler / encoding.TextMarshaler 的类型按其序列化结果处理
+//   - json 标签的 omitempty 和 string 选项会影响 required、nullable 和类型
+// 无法通过 go/packages 加载应用代码时，回退到基于 AST 的解析。
+
+// 包的元数据通过 go/packages 获取，类型检查按需进行（只检查用到的模型所在的包）。
+// 依赖包（包括标准库）从 go list -export 生成的导出数据加载，
+// 导出数据无法读取时（例如 bee 和 go 命令的版本不同）才从源码检查，并忽略函数体。
+
+const typedLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+	packages.NeedImports | packages.NeedDeps | packages.NeedExportFile
+
+var (
+	typedPackages    []*packages.Package       // packages of the application
+	checkedPackages  map[string]*types.Package // package ID: type-checked package
+	typedFileSet     *token.FileSet
+	exportImporter   types.Importer       // imports the dependencies from their export data
+	fieldComments    map[token.Pos]string // field position: comment
+	typedDefinitions map[string]bool      // definitions built from go/types
+	defaultPattern   = regexp.MustCompile(`default\((.*)\)`)
+)
+
+// jsonField is a struct field as seen by encoding/json
+type jsonField struct {
+	name      string
+	depth     int
+	tagged    bool
+	v         *types.Var
+	tag       reflect.StructTag
+	omitEmpty bool
+	asString  bool
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// loadTypedPackages loads the metadata of the packages of the application in dir
+func loadTypedPackages(dir string) {
+	typedPackages = nil
+	checkedPackages = make(map[string]*types.Package)
+	typedFileSet = token.NewFileSet()
+	fieldComments = make(map[token.Pos]string)
+	typedDefinitions = make(map[string]bool)
+
+	pkgs, err := packages.Load(&packages.Config{Dir: dir, Mode: typedLoadMode}, "./...")
+	if err != nil {
+		beeLogger.Log.Warnf("Could not load the packages, falling back to the syntax to parse the models: %s", err)
+		return
+	}
+	typedPackages = pkgs
+
+	exportFiles := map[string]string{}
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		if p.ExportFile != "" {
+			exportFiles[p.PkgPath] = p.ExportFile
+		}
+	})
+	exportImporter = importer.ForCompiler(typedFileSet, "gc", func(path string) (io.ReadCloser, error) {
+		file, ok := exportFiles[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(file)
+	})
+}
+
+// checkPackage type-checks the package of the application, its dependencies
+// are imported from their export data. Only the packages of the application
+// are checked with their function bodies.
+func checkPackage(pkg *packages.Package) *types.Package {
+	if pkg.PkgPath == "unsafe" {
+		return types.Unsafe
+	}
+	if tp, ok := checkedPackages[pkg.ID]; ok {
+		return tp
+	}
+	app := isAppPackagePath(pkg.PkgPath)
+	if !app && pkg.ExportFile != "" {
+		if tp, err := exportImporter.Import(pkg.PkgPath); err == nil {
+			checkedPackages[pkg.ID] = tp
+			return tp
+		}
+	}
+	mode := parser.SkipObjectResolution
+	if app {
+		mode |= parser.ParseComments
+	}
+	files := pkg.CompiledGoFiles
+	if len(files) == 0 {
+		files = pkg.GoFiles
+	}
+	var syntax []*ast.File
+	for _, name := range files {
+		f, err := parser.ParseFile(typedFileSet, name, nil, mode)
+		if err != nil {
+			continue
+		}
+		syntax = append(syntax, f)
+		if app {
+			collectFieldComments(f)
+		}
+	}
+
+	var firstErr error
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			imp, ok := pkg.Imports[path]
+			if !ok {
+				return nil, fmt.Errorf("could not import %s", path)
+			}
+			return checkPackage(imp), nil
+		}),
+		Sizes:            types.SizesFor("gc", runtime.GOARCH),
+		IgnoreFuncBodies: !app,
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	tp, _ := conf.Check(pkg.PkgPath, typedFileSet, syntax, nil)
+	if firstErr != nil && app {
+		beeLogger.Log.Warnf("Package '%s' has errors, its models may be incomplete: %s", pkg.PkgPath, firstErr)
+	}
+	checkedPackages[pkg.ID] = tp
+	return tp
+}
+
+// collectFieldComments records the comments of the struct fields of the file
+func collectFieldComments(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		st, ok := n.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		for _, field := range st.Fields.List {
+			text := strings.TrimSpace(field.Comment.Text())
+			if text == "" {
+				text = strings.TrimSpace(field.Doc.Text())
+			}
+			if text == "" {
+				continue
+			}
+			for _, name := range field.Names {
+				fieldComments[name.Pos()] = text
+			}
+		}
+		return true
+	})
+}
+
+// getTypedModel builds the definition of the type expression str, such as
+// models.Object or models.Resp[models.User], and of all the types it uses.
+func getTypedModel(str string) (definitionName string, m swagger.Schema, ok bool) {
+	if len(typedPackages) == 0 {
+		return "", m, false
+	}
+	expr, err := parser.ParseExpr(str)
+	if err != nil {
+		return "", m, false
+	}
+	t, err := exprType(expr)
+	if err != nil {
+		beeLogger.Log.Warnf("Cannot resolve the type '%s': %s", str, err)
+		return "", m, false
+	}
+	named, isNamed := t.(*types.Named)
+	if !isNamed {
+		return "", m, false
+	}
+	definitionName = defineNamed(named)
+	return definitionName, rootapiDefault.Definitions[definitionName], true
+}
+
+// exprType resolves a type expression of an annotation
+func exprType(expr ast.Expr) (types.Type, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if obj, ok := types.Universe.Lookup(e.Name).(*types.TypeName); ok {
+			return obj.Type(), nil
+		}
+		return nil, fmt.Errorf("unknown type '%s', types must be qualified with their package name", e.Name)
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("invalid type '%s'", types.ExprString(e))
+		}
+		obj := lookupTypeName(pkg.Name, e.Sel.Name)
+		if obj == nil {
+			return nil, fmt.Errorf("cannot find the type '%s'", types.ExprString(e))
+		}
+		return unalias(obj.Type()), nil
+	case *ast.StarExpr:
+		elem, err := exprType(e.X)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewPointer(elem), nil
+	case *ast.ArrayType:
+		elem, err := exprType(e.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewSlice(elem), nil
+	case *ast.MapType:
+		key, err := exprType(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := exprType(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewMap(key, elem), nil
+	case *ast.IndexExpr:
+		return instantiate(e.X, []ast.Expr{e.Index})
+	case *ast.IndexListExpr:
+		return instantiate(e.X, e.Indices)
+	}
+	return nil, fmt.Errorf("unsupported type expression '%s'", types.ExprString(expr))
+}
+
+// instantiate instantiates the generic type base with the type arguments
+func instantiate(base ast.Expr, args []ast.Expr) (types.Type, error) {
+	t, err := exprType(base)
+	if err != nil {
+		return nil, err
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.TypeParams().Len() == 0 {
+		return nil, fmt.Errorf("'%s' is not a generic type", types.ExprString(base))
+	}
+	targs := make([]types.Type, len(args))
+	for i, arg := range args {
+		if targs[i], err = exprType(arg); err != nil {
+			return nil, err
+		}
+	}
+	return types.Instantiate(nil, named, targs, true)
+}
+
+// lookupTypeName finds the type name in the packages named pkgName used
+// by the application, preferring the package imported by the routers.
+func lookupTypeName(pkgName, name string) *types.TypeName {
+	var candidates []*packages.Package
+	seen := map[string]bool{}
+	var visit func(p *packages.Package)
+	visit = func(p *packages.Package) {
+		if seen[p.ID] {
+			return
+		}
+		seen[p.ID] = true
+		if p.Name == pkgName {
+			candidates = append(candidates, p)
+		}
+		for _, imp := range p.Imports {
+			visit(imp)
+		}
+	}
+	for _, pkg := range typedPackages {
+		visit(pkg)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].PkgPath == importlist[pkgName] && candidates[j].PkgPath != importlist[pkgName]
+	})
+	for _, p := range candidates {
+		if obj, ok := checkPackage(p).Scope().Lookup(name).(*types.TypeName); ok {
+			return obj
+		}
+	}
+	return nil
+}
+
+// typeName returns the name of a type in a definition name, e.g. models.User
+func typeName(t types.Type) string {
+	switch tt := unalias(t).(type) {
+	case *types.Named:
+		obj := tt.Obj()
+		name := obj.Name()
+		if obj.Pkg() != nil {
+			name = obj.Pkg().Name() + "." + name
+		}
+		if targs := tt.TypeArgs(); targs.Len() > 0 {
+			for i := 0; i < targs.Len(); i++ {
+				name += "_" + typeName(targs.At(i))
+			}
+		}
+		return name
+	case *types.Pointer:
+		return typeName(tt.Elem())
+	case *types.Slice:
+		return "array_" + typeName(tt.Elem())
+	case *types.Array:
+		return "array_" + typeName(tt.Elem())
+	case *types.Map:
+		return "map_" + typeName(tt.Elem())
+	case *types.Basic:
+		return tt.Name()
+	}
+	return "object"
+}
+
+// defineNamed adds the definition of the named type and returns its name.
+// Generic types are named after their type arguments, e.g. models.Resp_models.User
+func defineNamed(named *types.Named) string {
+	name := typeName(named)
+	if typedDefinitions[name] {
+		return name
+	}
+	typedDefinitions[name] = true
+	if len(rootapiDefault.Definitions) == 0 {
+		rootapiDefault.Definitions = make(map[string]swagger.Schema)
+	}
+
+	m := swagger.Schema{Title: named.Obj().Name(), Type: astTypeObject}
+	switch u := named.Underlying().(type) {
+	case *types.Struct:
+		m.Properties, m.Required = structProperties(u, name)
+	case *types.Basic:
+		p, _ := typeProperty(u)
+		m.Type, m.Format = p.Type, p.Format
+		setEnum(&m, named)
+	default:
+		p, _ := typeProperty(u)
+		m.Type, m.Format = p.Type, p.Format
+		if p.Items != nil {
+			m.Items = propertySchema(*p.Items)
+		}
+	}
+	rootapiDefault.Definitions[name] = m
+	return name
+}
+
+// propertySchema converts a property to a schema
+func propertySchema(p swagger.Propertie) *swagger.Schema {
+	s := &swagger.Schema{Ref: p.Ref, Type: p.Type, Format: p.Format, Description: p.Description, Properties: p.Properties}
+	if p.Items != nil {
+		s.Items = propertySchema(*p.Items)
+	}
+	return s
+}
+
+// setEnum sets the values of the constants of the named type as the enum of m
+func setEnum(m *swagger.Schema, named *types.Named) {
+	obj := named.Obj()
+	if !isAppPackage(obj.Pkg()) {
+		return
+	}
+	var consts []*types.Const
+	scope := obj.Pkg().Scope()
+	for _, n := range scope.Names() {
+		if c, ok := scope.Lookup(n).(*types.Const); ok && types.Identical(c.Type(), named) {
+			consts = append(consts, c)
+		}
+	}
+	if len(consts) == 0 {
+		return
+	}
+	sort.Slice(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+	var desc []string
+	for _, c := range consts {
+		v := constantValue(c.Val())
+		m.Enum = append(m.Enum, v)
+		desc = append(desc, fmt.Sprintf("%s = %v", c.Name(), c.Val()))
+	}
+	m.Example = m.Enum[0]
+	m.Description = strings.Join(desc, "\n")
+}
+
+func constantValue(v constant.Value) interface{} {
+	switch v.Kind() {
+	case constant.Int:
+		if i, ok := constant.Int64Val(v); ok {
+			return i
+		}
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return f
+	case constant.String:
+		return constant.StringVal(v)
+	case constant.Bool:
+		return constant.BoolVal(v)
+	}
+	return v.ExactString()
+}
+
+// typeProperty returns the property of a value of type t as encoded by
+// encoding/json. It returns false for types which cannot be encoded.
+func typeProperty(t types.Type) (swagger.Propertie, bool) {
+	t = unalias(t)
+	if p, ok := marshalerProperty(t); ok {
+		return p, true
+	}
+	switch tt := t.(type) {
+	case *types.Named:
+		switch u := tt.Underlying().(type) {
+		case *types.Struct:
+			return swagger.Propertie{Ref: "#/definitions/" + defineNamed(tt)}, true
+		case *types.Basic:
+			if hasConstants(tt) {
+				return swagger.Propertie{Ref: "#/definitions/" + defineNamed(tt)}, true
+			}
+			return typeProperty(u)
+		default:
+			return typeProperty(u)
+		}
+	case *types.Pointer:
+		return typeProperty(tt.Elem())
+	case *types.Slice:
+		if b, ok := tt.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			// []byte is encoded as a base64 string
+			return swagger.Propertie{Type: "string", Format: "byte"}, true
+		}
+		return arrayProperty(tt.Elem())
+	case *types.Array:
+		return arrayProperty(tt.Elem())
+	case *types.Map:
+		elem, ok := typeProperty(tt.Elem())
+		if !ok {
+			return swagger.Propertie{}, false
+		}
+		return swagger.Propertie{Type: astTypeObject, AdditionalProperties: &elem}, true
+	case *types.Struct:
+		p := swagger.Propertie{Type: astTypeObject}
+		p.Properties, p.Required = structProperties(tt, "")
+		return p, true
+	case *types.Interface:
+		// any value
+		return swagger.Propertie{}, true
+	case *types.Basic:
+		if typeFormat, ok := basicTypes[tt.Name()]; ok {
+			tf := strings.Split(typeFormat, ":")
+			return swagger.Propertie{Type: tf[0], Format: tf[1]}, true
+		}
+		return swagger.Propertie{}, false
+	case *types.TypeParam:
+		return typeProperty(tt.Constraint())
+	}
+	// channels, functions and unsafe pointers cannot be encoded
+	return swagger.Propertie{}, false
+}
+
+func arrayProperty(elem types.Type) (swagger.Propertie, bool) {
+	items, ok := typeProperty(elem)
+	if !ok {
+		return swagger.Propertie{}, false
+	}
+	return swagger.Propertie{Type: astTypeArray, Items: &items}, true
+}
+
+// marshalerProperty handles the types encoded by their own methods
+func marshalerProperty(t types.Type) (swagger.Propertie, bool) {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return swagger.Propertie{}, false
+	}
+	switch named.Obj().Pkg().Path() + "." + named.Obj().Name() {
+	case "time.Time":
+		tf := strings.Split(basicTypes["time.Time"], ":")
+		return swagger.Propertie{Type: tf[0], Format: tf[1]}, true
+	case "encoding/json.RawMessage":
+		return swagger.Propertie{}, true
+	case "encoding/json.Number":
+		return swagger.Propertie{Type: "number"}, true
+	}
+	json := hasMethod(named, "MarshalJSON")
+	text := hasMethod(named, "MarshalText")
+	switch {
+	case json:
+		// unknown encoding, encoding/json prefers MarshalJSON to MarshalText
+		return swagger.Propertie{}, true
+	case text:
+		// encoded as a string, e.g. decimal.Decimal
+		return swagger.Propertie{Type: "string"}, true
+	}
+	return swagger.Propertie{}, false
+}
+
+func hasMethod(named *types.Named, name string) bool {
+	return types.NewMethodSet(types.NewPointer(named)).Lookup(named.Obj().Pkg(), name) != nil
+}
+
+// hasConstants reports whether constants of the named type, declared in
+// the application, enumerate its values
+func hasConstants(named *types.Named) bool {
+	if !isAppPackage(named.Obj().Pkg()) {
+		return false
+	}
+	scope := named.Obj().Pkg().Scope()
+	for _, n := range scope.Names() {
+		if c, ok := scope.Lookup(n).(*types.Const); ok && types.Identical(c.Type(), named) {
+			return true
+		}
+	}
+	return false
+}
+
+// structProperties returns the properties of the struct as encoded by
+// encoding/json. definition is the name of the definition of the struct,
+// used to record the nullable properties.
+func structProperties(st *types.Struct, definition string) (map[string]swagger.Propertie, []string) {
+	var fields []jsonField
+	collectFields(st, 0, map[*types.Struct]bool{}, &fields)
+
+	props := make(map[string]swagger.Propertie)
+	var required []string
+	for _, f := range dominantFields(fields) {
+		p, ok := typeProperty(f.v.Type())
+		if !ok {
+			continue
+		}
+		if f.asString {
+			if b, isBasic := deref(f.v.Type()).Underlying().(*types.Basic); isBasic && b.Info()&(types.IsNumeric|types.IsBoolean) != 0 {
+				p = swagger.Propertie{Type: "string"}
+			}
+		}
+		basicName := ""
+		if b, isBasic := deref(f.v.Type()).Underlying().(*types.Basic); isBasic {
+			basicName = b.Name()
+		}
+		if doc := f.tag.Get("doc"); doc != "" {
+			if res := defaultPattern.FindStringSubmatch(doc); res != nil {
+				p.Default = str2RealType(res[1], basicName)
+			} else {
+				beeLogger.Log.Warnf("Invalid default value: %s", doc)
+			}
+		}
+		if desc := f.tag.Get("description"); desc != "" {
+			p.Description = desc
+		} else if p.Description == "" {
+			p.Description = fieldComments[f.v.Pos()]
+		}
+		if example := f.tag.Get("example"); example != "" && p.Ref == "" && p.Type != astTypeArray && p.Type != astTypeObject {
+			p.Example = str2RealType(example, basicName)
+		}
+		if strings.EqualFold(f.tag.Get("required"), "true") && !f.omitEmpty {
+			required = append(required, f.name)
+		}
+		if _, isPointer := unalias(f.v.Type()).(*types.Pointer); isPointer && !f.omitEmpty && definition != "" {
+			// nil pointers are encoded as null, unless omitted
+			markNullable(definition, f.name, nil)
+		}
+		props[f.name] = p
+	}
+	sort.Strings(required)
+	return props, required
+}
+
+// collectFields collects the fields of the struct and of its embedded structs
+func collectFields(st *types.Struct, depth int, visiting map[*types.Struct]bool, out *[]jsonField) {
+	if visiting[st] {
+		return
+	}
+	visiting[st] = true
+	defer delete(visiting, st)
+
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		if (!v.Exported() && !v.Embedded()) || tag.Get("ignore") != "" {
+			continue
+		}
+		jsonTag := tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, opts := jsonTag, ""
+		if idx := strings.Index(jsonTag, ","); idx >= 0 {
+			name, opts = jsonTag[:idx], jsonTag[idx+1:]
+		}
+		if thrift := strings.Split(tag.Get("thrift"), ","); thrift[0] != "" {
+			name = thrift[0]
+		}
+
+		if inline := hasOption(opts, "inline"); inline || v.Embedded() {
+			ft := unalias(v.Type())
+			_, isPointer := ft.(*types.Pointer)
+			if embedded, ok := deref(ft).Underlying().(*types.Struct); ok && (inline || name == "") {
+				if !v.Exported() && isPointer {
+					// encoding/json ignores embedded pointers to unexported struct types
+					continue
+				}
+				collectFields(embedded, depth+1, visiting, out)
+				continue
+			}
+		}
+		if !v.Exported() {
+			continue
+		}
+		f := jsonField{
+			name:      name,
+			depth:     depth,
+			tagged:    name != "",
+			v:         v,
+			tag:       tag,
+			omitEmpty: hasOption(opts, "omitempty"),
+			asString:  hasOption(opts, "string"),
+		}
+		if f.name == "" {
+			f.name = v.Name()
+		}
+		*out = append(*out, f)
+	}
+}
+
+// dominantFields applies the rules of encoding/json to fields with the same
+// name: the shallowest field wins, then the tagged one, otherwise none.
+func dominantFields(fields []jsonField) []jsonField {
+	byName := map[string][]jsonField{}
+	var names []string
+	for _, f := range fields {
+		if _, ok := byName[f.name]; !ok {
+			names = append(names, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+	var out []jsonField
+	for _, name := range names {
+		fs := byName[name]
+		sort.SliceStable(fs, func(i, j int) bool { return fs[i].depth < fs[j].depth })
+		var top []jsonField
+		for _, f := range fs {
+			if f.depth == fs[0].depth {
+				top = append(top, f)
+			}
+		}
+		if len(top) == 1 {
+			out = append(out, top[0])
+			continue
+		}
+		var tagged []jsonField
+		for _, f := range top {
+			if f.tagged {
+				tagged = append(tagged, f)
+			}
+		}
+		if len(tagged) == 1 {
+			out = append(out, tagged[0])
+		}
+	}
+	return out
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isAppPackage reports whether p is a package of the application
+func isAppPackage(p *types.Package) bool {
+	return p != nil && isAppPackagePath(p.Path())
+}
+
+func isAppPackagePath(path string) bool {
+	for _, pkg := range typedPackages {
+		if pkg.PkgPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+func deref(t types.Type) types.Type {
+	if p, ok := unalias(t).(*types.Pointer); ok {
+		return unalias(p.Elem())
+	}
+	return unalias(t)
+}
+
+// unalias resolves type aliases, which recent versions of go/types
+// represent with their own type
+func unalias(t types.Type) types.Type {
+	for {
+		alias, ok := t.(interface{ Rhs() types.Type })
+		if !ok {
+			return t
+		}
+		t = alias.Rhs()
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggergen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web/swagger"
+)
+
+const typedModels = `
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Base struct {
+	ID        int       ` + "`json:\"id\"`" + `
+	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
+}
+
+type Meta struct {
+	Source string ` + "`json:\"source\"`" + `
+}
+
+type User struct {
+	Base
+	Extra   Meta    ` + "`json:\",inline\"`" + `
+	Name    string  ` + "`json:\"name\" required:\"true\"`" + `
+	Note    string  ` + "`json:\"note,omitempty\" required:\"true\"`" + `
+	Email   *string ` + "`json:\"email\"`" + `
+	Nick    *string ` + "`json:\"nick,omitempty\"`" + `
+	Age     int     ` + "`json:\"age,string\"`" + `
+	Secret  string  ` + "`json:\"-\"`" + `
+	private string
+	Address struct {
+		City string ` + "`json:\"city\"`" + `
+	} ` + "`json:\"address\"`" + `
+}
+
+type Resp[T any] struct {
+	Code  int ` + "`json:\"code\"`" + `
+	Data  T   ` + "`json:\"data\"`" + `
+	Items []T ` + "`json:\"items\"`" + `
+}
+
+// Money is encoded as a decimal string
+type Money struct{ cents int64 }
+
+func (m Money) MarshalText() ([]byte, error) { return nil, nil }
+
+// Point is encoded by MarshalJSON, not by MarshalText
+type Point struct{ x, y int }
+
+func (p Point) MarshalJSON() ([]byte, error) { return nil, nil }
+
+func (p Point) MarshalText() ([]byte, error) { return nil, nil }
+
+type Payment struct {
+	Amount Money           ` + "`json:\"amount\"`" + `
+	Where  *Point          ` + "`json:\"where,omitempty\"`" + `
+	Raw    json.RawMessage ` + "`json:\"raw\"`" + `
+	When   time.Time       ` + "`json:\"when\"`" + `
+	Tags   []string        ` + "`json:\"tags\"`" + `
+	Blob   []byte          ` + "`json:\"blob\"`" + `
+}
+`
+
+func loadTypedModels(t *testing.T) {
+	t.Helper()
+	dir := writeApp(t, map[string]string{"models/models.go": typedModels})
+	resetDocs()
+	loadTypedPackages(dir)
+	if len(typedPackages) == 0 {
+		t.Fatal("could not load the packages of the application")
+	}
+}
+
+func TestGetTypedModel(t *testing.T) {
+	loadTypedModels(t)
+
+	testCases := []struct {
+		expr       string
+		definition string
+		expected   swagger.Schema
+	}{
+		{
+			expr:       "models.User",
+			definition: "models.User",
+			expected: swagger.Schema{
+				Title: "User",
+				Type:  "object",
+				Properties: map[string]swagger.Propertie{
+					"id":         {Type: "integer", Format: "int64"},
+					"created_at": {Type: "string", Format: "datetime"},
+					"source":     {Type: "string"},
+					"name":       {Type: "string"},
+					"note":       {Type: "string"},
+					"email":      {Type: "string"},
+					"nick":       {Type: "string"},
+					"age":        {Type: "string"},
+					"address": {
+						Type:       "object",
+						Properties: map[string]swagger.Propertie{"city": {Type: "string"}},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		{
+			expr:       "models.Resp[models.User]",
+			definition: "models.Resp_models.User",
+			expected: swagger.Schema{
+				Title: "Resp",
+				Type:  "object",
+				Properties: map[string]swagger.Propertie{
+					"code":  {Type: "integer", Format: "int64"},
+					"data":  {Ref: "#/definitions/models.User"},
+					"items": {Type: "array", Items: &swagger.Propertie{Ref: "#/definitions/models.User"}},
+				},
+			},
+		},
+		{
+			expr:       "models.Payment",
+			definition: "models.Payment",
+			expected: swagger.Schema{
+				Title: "Payment",
+				Type:  "object",
+				Properties: map[string]swagger.Propertie{
+					"amount": {Type: "string"},
+					"where":  {},
+					"raw":    {},
+					"when":   {Type: "string", Format: "datetime"},
+					"tags":   {Type: "array", Items: &swagger.Propertie{Type: "string"}},
+					"blob":   {Type: "string", Format: "byte"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			name, m, ok := getTypedModel(tc.expr)
+			if !ok {
+				t.Fatalf("could not build the model %s", tc.expr)
+			}
+			if name != tc.definition {
+				t.Errorf("expected the definition %s, got %s", tc.definition, name)
+			}
+			if !reflect.DeepEqual(m, tc.expected) {
+				t.Errorf("expected:\n%#v\ngot:\n%#v", tc.expected, m)
+			}
+		})
+	}
+}
+
+func TestTypedModelNullable(t *testing.T) {
+	loadTypedModels(t)
+	if _, _, ok := getTypedModel("models.User"); !ok {
+		t.Fatal("could not build the model models.User")
+	}
+	// nil pointers are encoded as null, unless omitted
+	expected := map[string]bool{"email": true}
+	if got := nullableProperties["models.User"]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the nullable properties %v, got %v", expected, got)
+	}
+}
+
+func TestGetTypedModelErrors(t *testing.T) {
+	loadTypedModels(t)
+	for _, expr := range []string{"models.Missing", "User", "models.User[models.Meta]", "missing.User", "models."} {
+		if _, _, ok := getTypedModel(expr); ok {
+			t.Errorf("expected no model for %s", expr)
+		}
+	}
+}
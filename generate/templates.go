@@ -0,0 +1,165 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"bytes"
+	"embed"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/beego/bee/v2/internal/pkg/generated"
+	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
+)
+
+// bee generate 的各个生成器都通过 text/template 渲染代码。默认模板嵌入在 bee 中，
+// 项目可以在 .bee/templates 目录下放置同名文件覆盖默认模板，
+// 用 bee generate templates export 导出默认模板作为起点。
+// 每个模板文件开头的注释说明了可以使用的变量。
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+// TemplatesDir is the directory of the templates overriding the default ones, relative to the application
+var TemplatesDir = filepath.Join(".bee", "templates")
+
+// Template is a template used by the generators
+type Template struct {
+	Name        string // file name, relative to the templates directory
+	Description string
+	Delims      [2]string // the action delimiters, empty for {{ and }}
+}
+
+// Templates are the templates used by the generators
+var Templates = []Template{
+	{Name: "controller.go.tmpl", Description: "controller generated by 'bee generate controller'"},
+	{Name: "controller_model.go.tmpl", Description: "controller generated by 'bee generate controller' when the model exists"},
+	{Name: "controller_views.go.tmpl", Description: "controller generated by 'bee generate controller' when the model and the views exist"},
+	{Name: "model.go.tmpl", Description: "model generated by 'bee generate model'"},
+	{Name: "query.go.tmpl", Description: "list queries of the GetAll functions, generated with the models and the repositories"},
+	{Name: "migration.go.tmpl", Description: "migration generated by 'bee generate migration'"},
+	{Name: "view/index.tpl.tmpl", Description: "index view generated by 'bee generate view'", Delims: [2]string{"[[", "]]"}},
+	{Name: "view/show.tpl.tmpl", Description: "show view generated by 'bee generate view'", Delims: [2]string{"[[", "]]"}},
+	{Name: "view/create.tpl.tmpl", Description: "create view generated by 'bee generate view'", Delims: [2]string{"[[", "]]"}},
+	{Name: "view/edit.tpl.tmpl", Description: "edit view generated by 'bee generate view'", Delims: [2]string{"[[", "]]"}},
+	{Name: "test/controller_test.go.tmpl", Description: "test of a controller generated by 'bee generate test'"},
+	{Name: "test/model_test.go.tmpl", Description: "test of a model generated by 'bee generate test'"},
+	{Name: "test/main_test.go.tmpl", Description: "TestMain registering an SQLite database, generated by 'bee generate test'"},
+	{Name: "appcode/model.go.tmpl", Description: "model of a table with a primary key, generated by 'bee generate appcode'"},
+	{Name: "appcode/struct_model.go.tmpl", Description: "model of a table without primary key, generated by 'bee generate appcode'"},
+	{Name: "appcode/controller.go.tmpl", Description: "controller generated by 'bee generate appcode'"},
+	{Name: "appcode/router.go.tmpl", Description: "router generated by 'bee generate appcode'"},
+	{Name: "appcode/layered/model.go.tmpl", Description: "model of a table with a primary key, generated by 'bee generate appcode -arch=layered'"},
+	{Name: "appcode/layered/common.go.tmpl", Description: "errors and helpers shared by the repositories, generated by 'bee generate appcode -arch=layered'"},
+	{Name: "appcode/layered/repository.go.tmpl", Description: "repository interface and its ORM implementation, generated by 'bee generate appcode -arch=layered'"},
+	{Name: "appcode/layered/service.go.tmpl", Description: "service generated by 'bee generate appcode -arch=layered'"},
+	{Name: "appcode/layered/controller.go.tmpl", Description: "controller generated by 'bee generate appcode -arch=layered'"},
+	{Name: "appcode/layered/router.go.tmpl", Description: "router generated by 'bee generate appcode -arch=layered'"},
+	{Name: "grpc/service.proto.tmpl", Description: "protobuf messages and gRPC service of a model, generated by 'bee generate grpc'"},
+	{Name: "grpc/generate.go.tmpl", Description: "go:generate directive running protoc on the .proto files, generated by 'bee generate grpc'"},
+	{Name: "grpc/server.go.tmpl", Description: "gRPC server of a model, generated by 'bee generate grpc'"},
+	{Name: "grpc/rpc.go.tmpl", Description: "registration of the gRPC servers, generated by 'bee generate grpc'"},
+	{Name: "grpc/main.go.tmpl", Description: "main.go serving the gRPC services next to the HTTP server, generated by 'bee generate grpc'"},
+	{Name: "hprose/model.go.tmpl", Description: "model of a table with a primary key, generated by 'bee hprose'"},
+	{Name: "hprose/struct_model.go.tmpl", Description: "model of a table without primary key, generated by 'bee hprose'"},
+	{Name: "hprose/functions.go.tmpl", Description: "functions of a model published by the main.go generated by 'bee hprose'"},
+}
+
+// templateFuncs are the functions available in the templates
+var templateFuncs = template.FuncMap{
+	"camelCase": utils.CamelCase,
+	"snakeCase": utils.SnakeString,
+	"title":     strings.Title,
+	"lower":     strings.ToLower,
+	"lowerFirst": func(s string) string {
+		r, n := utf8.DecodeRuneInString(s)
+		return string(unicode.ToLower(r)) + s[n:]
+	},
+	"upper": strings.ToUpper,
+	"add":   func(a, b int) int { return a + b },
+}
+
+func findTemplate(name string) (Template, bool) {
+	for _, t := range Templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// overridden records the templates of the application already reported
+var overridden = map[string]bool{}
+
+// loadTemplate returns the template of the application overriding name, or the default one
+func loadTemplate(currpath, name string) (*template.Template, error) {
+	text, err := os.ReadFile(filepath.Join(currpath, TemplatesDir, filepath.FromSlash(name)))
+	if err == nil {
+		if !overridden[name] {
+			beeLogger.Log.Infof("Using the template '%s'", filepath.Join(TemplatesDir, filepath.FromSlash(name)))
+			overridden[name] = true
+		}
+	} else if os.IsNotExist(err) {
+		if text, err = defaultTemplates.ReadFile("templates/" + name); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+	t := template.New(name).Funcs(templateFuncs)
+	if info, ok := findTemplate(name); ok && info.Delims[0] != "" {
+		t = t.Delims(info.Delims[0], info.Delims[1])
+	}
+	return t.Parse(string(text))
+}
+
+// renderTemplate renders the template name with data
+func renderTemplate(currpath, name string, data interface{}) string {
+	t, err := loadTemplate(currpath, name)
+	if err != nil {
+		beeLogger.Log.Fatalf("Could not load the template '%s': %s", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		beeLogger.Log.Fatalf("Could not render the template '%s': %s", name, err)
+	}
+	return buf.String()
+}
+
+// ExportTemplates writes the default templates into the templates directory
+// of the application. The existing templates are kept unless force is set.
+func ExportTemplates(currpath string, force bool) error {
+	for _, t := range Templates {
+		text, err := defaultTemplates.ReadFile("templates/" + t.Name)
+		if err != nil {
+			return err
+		}
+		fpath := filepath.Join(currpath, TemplatesDir, filepath.FromSlash(t.Name))
+		if generated.Exists(fpath) && !force {
+			beeLogger.Log.Warnf("Skipped '%s': the file already exists", fpath)
+			continue
+		}
+		if err := generated.WriteFile(fpath, text); err != nil {
+			return err
+		}
+		beeLogger.Log.Infof("Exported '%s'", fpath)
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web"
+	"golang.org/x/mod/modfile"
+)
+
+// 渲染生成器的默认模板，并用 go vet 检查生成的代码能否编译。
+// 生成的应用使用 bee 自身的依赖版本，go vet 不需要下载模块。
+
+// vetApp is an application of module example.com/app requiring the dependencies of bee
+type vetApp struct {
+	dir string
+	env []string // the environment of go vet
+}
+
+// newVetApp creates an application. The generators read its module path from
+// go.mod, GOPATH being empty until the end of the test.
+func newVetApp(t *testing.T) *vetApp {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("go vet of the generated code is skipped in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go vet of the generated code needs the go command")
+	}
+
+	data, err := os.ReadFile(filepath.Join("..", "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mod.AddModuleStmt("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	// the tests blank import the SQLite driver, which is not a dependency of bee
+	if err := mod.AddRequire("github.com/mattn/go-sqlite3", "v1.14.7"); err != nil {
+		t.Fatal(err)
+	}
+	gomod, err := mod.Format()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gosum, err := os.ReadFile(filepath.Join("..", "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"go.mod": gomod,
+		"go.sum": gosum,
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	goEnv := func(name string) string {
+		out, err := exec.Command("go", "env", name).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	// the modules are not downloaded
+	env := []string{
+		"GOPATH=" + goEnv("GOPATH"),
+		"GOMODCACHE=" + goEnv("GOMODCACHE"),
+		"GOFLAGS=-mod=mod",
+		"GOPROXY=off",
+		"GOSUMDB=off",
+	}
+	t.Setenv("GOPATH", "")
+	return &vetApp{dir: dir, env: append(os.Environ(), env...)}
+}
+
+// vet runs go vet on the packages of the application
+func (app *vetApp) vet(t *testing.T) {
+	t.Helper()
+	cmd := exec.Command("go", "vet", "./...")
+	cmd.Dir = app.dir
+	cmd.Env = app.env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go vet failed: %s\n%s", err, out)
+	}
+}
+
+// protoc generates the Go code of the .proto files of dir with protoc-gen-go and
+// protoc-gen-go-grpc, run by testdata/protoc where protoc is not installed, and
+// requires the gRPC modules of testdata/protoc/go.mod in the application
+func (app *vetApp) protoc(t *testing.T, dir string) {
+	t.Helper()
+	tools, err := filepath.Abs(filepath.Join("testdata", "protoc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	cmd := exec.Command("go", "build", "-o", bin+string(filepath.Separator), ".",
+		"google.golang.org/protobuf/cmd/protoc-gen-go", "google.golang.org/grpc/cmd/protoc-gen-go-grpc")
+	cmd.Dir = tools
+	cmd.Env = app.env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("the protoc plugins could not be built: %s\n%s", err, out)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := []string{dir, filepath.Join(bin, "protoc-gen-go"), filepath.Join(bin, "protoc-gen-go-grpc"), "--"}
+	for _, file := range files {
+		args = append(args, filepath.Base(file))
+	}
+	if out, err := exec.Command(filepath.Join(bin, "protoc"), args...).CombinedOutput(); err != nil {
+		t.Fatalf("protoc failed: %s\n%s", err, out)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tools, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit := []string{"mod", "edit"}
+	for _, r := range mod.Require {
+		edit = append(edit, "-require="+r.Mod.Path+"@"+r.Mod.Version)
+	}
+	cmd = exec.Command("go", edit...)
+	cmd.Dir = app.dir
+	cmd.Env = app.env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go mod edit failed: %s\n%s", err, out)
+	}
+}
+
+// test runs the tests of the packages of the application against SQLite,
+// whose driver needs cgo
+func (app *vetApp) test(t *testing.T, pkgs ...string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("the generated tests are skipped in short mode")
+	}
+	out, err := exec.Command("go", "env", "CGO_ENABLED", "CC").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := strings.Fields(string(out))
+	if len(env) < 2 || env[0] != "1" {
+		t.Skip("the generated tests need cgo for SQLite")
+	}
+	if _, err := exec.LookPath(env[1]); err != nil {
+		t.Skip("the generated tests need a C compiler for SQLite")
+	}
+	cmd := exec.Command("go", append([]string{"test", "-count=1"}, pkgs...)...)
+	cmd.Dir = app.dir
+	cmd.Env = app.env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("the generated tests failed: %s\n%s", err, out)
+	}
+}
+
+func TestGeneratedScaffoldCompiles(t *testing.T) {
+	// views, controller rendering them, model with its list query, and their tests
+	app := newVetApp(t)
+	dir := app.dir
+
+	fields := "title:string(128),body:text?,views:int:default(0),price:decimal(10,2),status:enum(draft,published):default(draft),published:bool,published_at:datetime?"
+	GenerateModel("post", fields, dir)
+	GenerateView("post", "", dir)
+	GenerateController("post", dir)
+	// a controller with a model and without views
+	GenerateModel("comment", "body:text,posted_at:datetime,post:ref(post)", dir)
+	GenerateController("comment", dir)
+	// a controller without model
+	GenerateController("health", dir)
+	GenerateTest("post", dir)
+	GenerateTest("comment", dir)
+
+	for _, file := range []string{
+		"models/query.go",
+		"controllers/post_test.go",
+		"controllers/main_test.go",
+		"models/post_test.go",
+		"models/main_test.go",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("expected %s: %s", file, err)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "controllers", "post.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `c.TplName = "post/index.tpl"`) {
+		t.Errorf("expected the controller rendering the views, got:\n%s", content)
+	}
+
+	app.vet(t)
+
+	// the views are beego templates, built with the template functions of beego
+	if err := web.AddViewPath(filepath.Join(dir, "views")); err != nil {
+		t.Errorf("could not build the views: %s", err)
+	}
+
+	// the filters of the generated list query, with the escaped separators
+	listQueryTest := `package models
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseListQueryFilters(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []ListFilter
+	}{
+		{"title:go,views__gt:10", []ListFilter{{"Title", "exact", []interface{}{"go"}}, {"Views", "gt", []interface{}{"10"}}}},
+		{"title:a\\,b", []ListFilter{{"Title", "exact", []interface{}{"a,b"}}}},
+		{"title:a:b\\\\", []ListFilter{{"Title", "exact", []interface{}{"a:b\\"}}}},
+		{"id__in:1|2|3", []ListFilter{{"Id", "in", []interface{}{"1", "2", "3"}}}},
+		{"status__in:a\\|b|c\\,d", []ListFilter{{"Status", "in", []interface{}{"a|b", "c,d"}}}},
+		{"body__isnull:true", []ListFilter{{"Body", "isnull", []interface{}{true}}}},
+	}
+	for _, tt := range tests {
+		q, err := ParseListQuery(url.Values{"query": {tt.query}}, PostListFields)
+		if err != nil {
+			t.Errorf("%s: %s", tt.query, err)
+		} else if !reflect.DeepEqual(q.Filters, tt.want) {
+			t.Errorf("%s: got the filters %v, want %v", tt.query, q.Filters, tt.want)
+		}
+	}
+	if _, err := ParseListQuery(url.Values{"query": {"title\\:go"}}, PostListFields); err == nil {
+		t.Errorf("expected an error for an escaped field separator")
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "models", "list_query_test.go"), []byte(listQueryTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the generated tests pass from day one
+	app.test(t, "./models/...", "./controllers/...")
+}
+
+// appcodeTables returns the tables of a blog, with their relations
+func appcodeTables() []*Table {
+	user := &Table{Name: "user", Model: "User", Resource: "user", Pk: "id", Fk: map[string]*ForeignKey{}}
+	user.Columns = []*Column{
+		idColumn(),
+		{Name: "Name", Type: "string", Tag: &OrmTag{Column: "name", Size: "64"}},
+		{Name: "CreatedAt", Type: "time.Time", Tag: &OrmTag{Column: "created_at", Type: "datetime", AutoNowAdd: true}},
+	}
+	user.ImportTimePkg = true
+
+	post := &Table{Name: "post", Model: "Post", Resource: "post", Pk: "id", Fk: map[string]*ForeignKey{}}
+	post.Columns = []*Column{
+		idColumn(),
+		fkColumn(post, "author_id", "user", &Column{Name: "AuthorId", Type: "int", Tag: &OrmTag{}}),
+		fkColumn(post, "category_id", "category", &Column{Name: "CategoryId", Type: "uint", Tag: &OrmTag{Null: true}}),
+		{Name: "Title", Type: "string", Tag: &OrmTag{Column: "title", Size: "128"}},
+		{Name: "Price", Type: "float64", Tag: &OrmTag{Column: "price", Digits: "10", Decimals: "2"}},
+		{Name: "Published", Type: "bool", Tag: &OrmTag{Column: "published"}},
+	}
+
+	tag := &Table{Name: "tag", Model: "Tag", Resource: "tag", Pk: "id", Fk: map[string]*ForeignKey{}}
+	tag.Columns = []*Column{idColumn(), {Name: "Name", Type: "string", Tag: &OrmTag{Column: "name", Size: "32"}}}
+
+	postTag := &Table{Name: "post_tag", Model: "PostTag", Resource: "post_tag", Pk: "id", Fk: map[string]*ForeignKey{}}
+	postTag.Columns = []*Column{
+		idColumn(),
+		fkColumn(postTag, "post_id", "post", &Column{Name: "PostId", Type: "int", Tag: &OrmTag{}}),
+		fkColumn(postTag, "tag_id", "tag", &Column{Name: "TagId", Type: "int", Tag: &OrmTag{}}),
+	}
+
+	// a table without primary key
+	visit := &Table{Name: "visit", Model: "Visit", Resource: "visit", Fk: map[string]*ForeignKey{}}
+	visit.Columns = []*Column{{Name: "Path", Type: "string", Tag: &OrmTag{Column: "path", Size: "255"}}}
+
+	return []*Table{user, post, tag, postTag, visit}
+}
+
+func TestGeneratedAppcodeCompiles(t *testing.T) {
+	for _, arch := range []string{"", ArchLayered} {
+		t.Run("arch="+arch, func(t *testing.T) {
+			app := newVetApp(t)
+			dir := app.dir
+			paths := &MvcPath{
+				ModelPath:      path.Join(dir, "models"),
+				ControllerPath: path.Join(dir, "controllers"),
+				RouterPath:     path.Join(dir, "routers"),
+			}
+			if arch == ArchLayered {
+				paths.RepositoryPath = path.Join(dir, "repositories")
+				paths.ServicePath = path.Join(dir, "services")
+			}
+			mode := OModel | OController | ORouter
+			createPaths(mode, paths)
+			tables := appcodeTables()
+			resolveRelations(tables, "example.com/app")
+			writeSourceFiles(dir, "example.com/app", tables, mode, paths)
+
+			query := filepath.Join(dir, "models", "query.go")
+			if arch == ArchLayered {
+				query = filepath.Join(dir, "repositories", "query.go")
+			}
+			if _, err := os.Stat(query); err != nil {
+				t.Errorf("expected the list query: %s", err)
+			}
+
+			app.vet(t)
+		})
+	}
+}
+
+func TestGeneratedGrpcCompiles(t *testing.T) {
+	// the models, the .proto files, the gRPC servers and main.go, vetted with the Go code of protoc
+	app := newVetApp(t)
+	dir := app.dir
+	tables := appcodeTables()
+	resolveRelations(tables, "example.com/app")
+	writeGrpcFiles(dir, "example.com/app", "mysql", tables)
+
+	for _, file := range []string{"main.go", "pb/generate.go", "pb/post.proto", "rpc/rpc.go", "rpc/post.go", "rpc/post_tag.go"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(file))); err != nil {
+			t.Errorf("expected %s: %s", file, err)
+		}
+	}
+	// the table without primary key has no service
+	if _, err := os.Stat(filepath.Join(dir, "pb", "visit.proto")); !os.IsNotExist(err) {
+		t.Errorf("expected no service of the table without primary key, got %v", err)
+	}
+
+	app.protoc(t, filepath.Join(dir, "pb"))
+	app.vet(t)
+}
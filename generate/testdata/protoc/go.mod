@@ -0,0 +1,19 @@
+module protoc
+
+go 1.21
+
+require (
+	github.com/bufbuild/protocompile v0.14.1
+	google.golang.org/grpc/cmd/protoc-gen-go-grpc v1.5.1
+	google.golang.org/protobuf v1.34.2
+)
+
+require golang.org/x/sync v0.8.0 // indirect
+
+// the generated applications build with these versions, genproto is newer
+// than the googleapis packages moved out of it
+require (
+	google.golang.org/genproto v0.0.0-20250603155806-513f23925822
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117
+	google.golang.org/grpc v1.65.0
+)
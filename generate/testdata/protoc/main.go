@@ -0,0 +1,112 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Command protoc compiles the .proto files of a directory and runs protoc
+// plugins on them, like protoc with paths=source_relative. The tests of the
+// gRPC generator use it where protoc is not installed.
+//
+//	protoc <dir> <plugin>... -- <file.proto>...
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: protoc <dir> <plugin>... -- <file.proto>...")
+		os.Exit(2)
+	}
+	dir := os.Args[1]
+	var plugins, files []string
+	for i, arg := range os.Args[2:] {
+		if arg == "--" {
+			files = os.Args[i+3:]
+			break
+		}
+		plugins = append(plugins, arg)
+	}
+	if err := run(dir, plugins, files); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run compiles files, relative to dir, and writes the files generated by the plugins in dir
+func run(dir string, plugins, files []string) error {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{dir}}),
+	}
+	compiled, err := compiler.Compile(context.Background(), files...)
+	if err != nil {
+		return err
+	}
+
+	// the files to generate and their imports, the imports first
+	req := &pluginpb.CodeGeneratorRequest{FileToGenerate: files, Parameter: proto.String("paths=source_relative")}
+	added := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if added[fd.Path()] {
+			return
+		}
+		added[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		req.ProtoFile = append(req.ProtoFile, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range compiled {
+		add(fd)
+	}
+	in, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	for _, plugin := range plugins {
+		cmd := exec.Command(plugin)
+		cmd.Stdin = bytes.NewReader(in)
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("%s: %s", plugin, err)
+		}
+		var resp pluginpb.CodeGeneratorResponse
+		if err := proto.Unmarshal(out, &resp); err != nil {
+			return fmt.Errorf("%s: %s", plugin, err)
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("%s: %s", plugin, resp.GetError())
+		}
+		for _, f := range resp.File {
+			if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(f.GetName())), []byte(f.GetContent()), 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
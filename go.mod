@@ -15,6 +15,7 @@ require (
 	github.com/shopspring/decimal v1.3.1
 	github.com/smartwalle/pongo2render v1.0.1
 	github.com/spf13/viper v1.7.0
+	golang.org/x/mod v0.8.0
 	golang.org/x/tools v0.6.0
 	gopkg.in/yaml.v2 v2.4.0
 )
@@ -53,7 +54,6 @@ require (
 	go.starlark.net v0.0.0-20220816155156-cfacd8902214 // indirect
 	golang.org/x/arch v0.0.0-20190927153633-4e8777c89be4 // indirect
 	golang.org/x/crypto v0.21.0 // indirect
-	golang.org/x/mod v0.8.0 // indirect
 	golang.org/x/net v0.23.0 // indirect
 	golang.org/x/sys v0.18.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
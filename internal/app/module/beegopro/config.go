@@ -1,8 +1,7 @@
 package beegopro
 
 import (
-	"io/ioutil"
-
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	"github.com/beego/bee/v2/internal/pkg/utils"
 	beeLogger "github.com/beego/bee/v2/logger"
 )
@@ -22,7 +21,7 @@ func (c *Container) GenConfig() {
 
 	// 如果文件不存在，使用 ioutil.WriteFile 创建一个新的文件，并写入 BeegoToml 字符串的内容。
 	// 文件权限设置为 0644，意味着所有用户都可以读取文件，但只有文件的所有者可以写入。
-	err := ioutil.WriteFile("beegopro.toml", []byte(BeegoToml), 0644)
+	err := generated.WriteFile("beegopro.toml", []byte(BeegoToml))
 	if err != nil {
 		beeLogger.Log.Fatalf("write beego pro toml err: %s", err)
 		return
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	"github.com/beego/bee/v2/internal/pkg/git"
 	"github.com/beego/bee/v2/internal/pkg/system"
 	beeLogger "github.com/beego/bee/v2/logger"
@@ -53,7 +54,7 @@ var DefaultBeegoPro = &Container{
 	TmplOption:       TmplOption{},
 	CurPath:          system.CurrentDir,
 	EnableModules:    make(map[string]interface{}), // get the user configuration, get the enable module result
-	FunctionOnce:     make(map[string]sync.Once),   // get the tmpl configuration, get the function once result
+	FunctionOnce:     make(map[string]*sync.Once),  // get the tmpl configuration, get the function once result
 }
 
 func (c *Container) Run() {
@@ -135,7 +136,7 @@ func (c *Container) initTemplateOption() {
 
 	for _, value := range c.TmplOption.Descriptor {
 		if value.Once {
-			c.FunctionOnce[value.SrcName] = sync.Once{}
+			c.FunctionOnce[value.SrcName] = &sync.Once{}
 		}
 	}
 }
@@ -178,7 +179,8 @@ func (c *Container) renderModel(m RenderInfo) {
 	m.GenerateTime = c.GenerateTime
 	render := NewRender(m)
 	render.Exec(m.Descriptor.SrcName)
-	if render.Descriptor.IsExistScript() {
+	// the scripts work on the files on disk, which are not written in dry-run mode
+	if render.Descriptor.IsExistScript() && !generated.DryRun {
 		err := render.Descriptor.ExecScript(c.CurPath)
 		if err != nil {
 			beeLogger.Log.Fatalf("beego exec shell error, err: %s", err)
@@ -203,6 +205,9 @@ func (c *Container) initTimestamp() {
 }
 
 func (c *Container) flushTimestamp() {
+	if generated.DryRun {
+		return
+	}
 	tomlByte, err := toml.Marshal(c.Timestamp)
 	if err != nil {
 		beeLogger.Log.Fatalf("marshal timestamp tmpl parse error, err: %s", err)
@@ -223,7 +228,7 @@ func (c *Container) InitToml() {
 		beeLogger.Log.Fatalf("read beegopro.toml file err, %s", err.Error())
 		return
 	}
-	err = ioutil.WriteFile(c.BeegoProFile, input, 0644)
+	err = generated.WriteFile(c.BeegoProFile, input)
 	if err != nil {
 		beeLogger.Log.Fatalf("create beegopro.toml file err, %s", err.Error())
 		return
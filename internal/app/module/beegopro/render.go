@@ -2,7 +2,6 @@ package beegopro
 
 import (
 	"go/format"
-	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -11,6 +10,7 @@ import (
 	"github.com/flosch/pongo2"
 	"github.com/smartwalle/pongo2render"
 
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	"github.com/beego/bee/v2/internal/pkg/system"
 	beeLogger "github.com/beego/bee/v2/logger"
 )
@@ -119,15 +119,9 @@ func (r *RenderFile) Exec(name string) {
 		beeLogger.Log.Fatalf("Could not create the %s render tmpl: %s", name, err)
 		return
 	}
-	_, err = os.Stat(r.Descriptor.DstPath)
-	var orgContent []byte
-	if err == nil {
-		if org, err := os.OpenFile(r.Descriptor.DstPath, os.O_RDONLY, 0666); err == nil {
-			orgContent, _ = ioutil.ReadAll(org)
-			org.Close()
-		} else {
-			beeLogger.Log.Infof("file err %s", err)
-		}
+	orgContent, err := generated.ReadFile(r.Descriptor.DstPath)
+	if err != nil && !os.IsNotExist(err) {
+		beeLogger.Log.Infof("file err %s", err)
 	}
 	// Replace or create when content changes
 	output := []byte(buf)
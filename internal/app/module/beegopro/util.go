@@ -11,59 +11,61 @@ import (
 	"strings"
 	"time"
 
-	"github.com/beego/bee/v2/internal/pkg/utils"
+	"github.com/beego/bee/v2/internal/pkg/generated"
+	"github.com/beego/bee/v2/internal/pkg/system"
 	beeLogger "github.com/beego/bee/v2/logger"
+	"github.com/beego/bee/v2/utils"
 )
 
 // write to file
+// 之前由 bee 生成过的文件与用户的修改合并，其他已存在的文件只有标记了 @BeeOverwrite yes 才备份后覆盖；
+// 生成的版本保存在应用根目录（go.mod 所在目录）的 .bee/generated 中，应用之外的文件不会写入
 func (c *RenderFile) write(filename string, buf []byte) (err error) {
-	if utils.IsExist(filename) && !isNeedOverwrite(filename) {
+	root := appRoot()
+	result, err := generated.Write(root, filename, buf)
+	if err == nil {
+		if result.Conflicts > 0 {
+			beeLogger.Log.Warnf("'%s' has %d conflict(s) between your changes and the generated code, resolve them between the '%s' and '%s' markers",
+				filename, result.Conflicts, generated.MarkerMine, generated.MarkerTheirs)
+		}
 		return
 	}
-
-	filePath := filepath.Dir(filename)
-	err = createPath(filePath)
-	if err != nil {
-		err = errors.New("write create path " + err.Error())
-		return
+	if err != generated.ErrNoBase {
+		return err
 	}
-
-	filePathBak := filePath + "/bak"
-	err = createPath(filePathBak)
-	if err != nil {
-		err = errors.New("write create path bak " + err.Error())
-		return
+	if !isNeedOverwrite(filename) {
+		return nil
 	}
 
+	filePath := filepath.Dir(filename)
+	filePathBak := filePath + "/bak"
 	name := path.Base(filename)
 
-	if utils.IsExist(filename) {
+	if old, err := generated.ReadFile(filename); err == nil {
 		bakName := fmt.Sprintf("%s/%s.%s.bak", filePathBak, filepath.Base(name), time.Now().Format("2006.01.02.15.04.05"))
 		beeLogger.Log.Infof("bak file '%s'", bakName)
-		if err := os.Rename(filename, bakName); err != nil {
-			err = errors.New("file is bak error, path is " + bakName)
-			return err
+		if err := generated.WriteFile(bakName, old); err != nil {
+			return errors.New("file is bak error, path is " + bakName)
 		}
 	}
 
-	file, err := os.Create(filename)
-	defer func() {
-		err = file.Close()
-		if err != nil {
-			beeLogger.Log.Fatalf("file close error, err %s", err)
-		}
-	}()
-	if err != nil {
-		err = errors.New("write create file " + err.Error())
-		return
+	if err = generated.WriteFile(filename, buf); err != nil {
+		return errors.New("write write file " + err.Error())
 	}
+	return generated.SaveBase(root, filename, buf)
+}
 
-	err = ioutil.WriteFile(filename, buf, 0644)
-	if err != nil {
-		err = errors.New("write write file " + err.Error())
-		return
+// appRoot returns the root directory of the application: the directory of the
+// go.mod of the current directory or of its parents, else the current directory
+func appRoot() string {
+	for dir := system.CurrentDir; ; dir = filepath.Dir(dir) {
+		if utils.IsExist(filepath.Join(dir, "go.mod")) {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return system.CurrentDir
+		}
 	}
-	return
 }
 
 func isNeedOverwrite(fileName string) (flag bool) {
@@ -95,11 +97,7 @@ func isNeedOverwrite(fileName string) (flag bool) {
 
 // createPath 调用os.MkdirAll递归创建文件夹
 func createPath(filePath string) error {
-	if !utils.IsExist(filePath) {
-		err := os.MkdirAll(filePath, os.ModePerm)
-		return err
-	}
-	return nil
+	return generated.MkdirAll(filePath)
 }
 
 func getPackagePath() (packagePath string) {
@@ -0,0 +1,111 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generated
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes
+const diffContext = 3
+
+// diffLine is a line of a diff: ' ', '-' or '+' and the text
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// Diff returns the unified diff from a to b, empty when they are equal
+func Diff(nameA, nameB string, a, b []byte) string {
+	linesA := splitLines(strings.TrimSuffix(string(a), "\n"))
+	linesB := splitLines(strings.TrimSuffix(string(b), "\n"))
+	m := match(linesA, linesB)
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(linesA) || j < len(linesB) {
+		next := len(linesB)
+		if i < len(linesA) {
+			next = m[i]
+		}
+		switch {
+		case i < len(linesA) && m[i] < 0:
+			lines = append(lines, diffLine{'-', linesA[i]})
+			i++
+		case j < next:
+			lines = append(lines, diffLine{'+', linesB[j]})
+			j++
+		default:
+			lines = append(lines, diffLine{' ', linesA[i]})
+			i++
+			j++
+		}
+	}
+
+	// posA[k] and posB[k] are the numbers of the lines of a and b before lines[k]
+	posA, posB := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	for k, l := range lines {
+		posA[k+1], posB[k+1] = posA[k], posB[k]
+		if l.op != '+' {
+			posA[k+1]++
+		}
+		if l.op != '-' {
+			posB[k+1]++
+		}
+	}
+
+	var out strings.Builder
+	prev := 0 // the end of the previous hunk
+	for k := 0; k < len(lines); {
+		if lines[k].op == ' ' {
+			k++
+			continue
+		}
+		// the changes separated by less than twice the context share a hunk
+		last := k
+		for n := k; n < len(lines) && n-last <= 2*diffContext; n++ {
+			if lines[n].op != ' ' {
+				last = n
+			}
+		}
+		from, to := k-diffContext, last+1+diffContext
+		if from < prev {
+			from = prev
+		}
+		if to > len(lines) {
+			to = len(lines)
+		}
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(posA[from], posA[to]-posA[from]), hunkRange(posB[from], posB[to]-posB[from]))
+		for _, l := range lines[from:to] {
+			out.WriteString(string(l.op) + l.text + "\n")
+		}
+		prev, k = to, to
+	}
+	return out.String()
+}
+
+// hunkRange formats the range of a hunk, the lines after from
+func hunkRange(from, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", from)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", from+1)
+	}
+	return fmt.Sprintf("%d,%d", from+1, count)
+}
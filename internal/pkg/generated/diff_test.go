@@ -0,0 +1,90 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generated
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbered returns the lines 1 to n, one number by line
+func numbered(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := replace[i]; ok {
+			if line != "" {
+				b.WriteString(line + "\n")
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "%d\n", i)
+	}
+	return b.String()
+}
+
+func TestDiff(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", expected: ""},
+		{name: "created", a: "", b: "a\nb\n", expected: "@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{name: "emptied", a: "a\n", b: "", expected: "@@ -1 +0,0 @@\n-a\n"},
+		{name: "missing final newline", a: "a\nb", b: "a\nb\n", expected: ""},
+		{
+			name:     "changed line with its context",
+			a:        numbered(10, nil),
+			b:        numbered(10, map[int]string{5: "five"}),
+			expected: "@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:     "added at the end",
+			a:        numbered(5, nil),
+			b:        numbered(5, nil) + "6\n",
+			expected: "@@ -3,3 +3,4 @@\n 3\n 4\n 5\n+6\n",
+		},
+		{
+			name:     "removed at the start",
+			a:        numbered(5, nil),
+			b:        numbered(5, map[int]string{1: ""}),
+			expected: "@@ -1,4 +1,3 @@\n-1\n 2\n 3\n 4\n",
+		},
+		{
+			name:     "close changes share a hunk",
+			a:        numbered(12, nil),
+			b:        numbered(12, map[int]string{3: "three", 9: "nine"}),
+			expected: "@@ -1,12 +1,12 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n 7\n 8\n-9\n+nine\n 10\n 11\n 12\n",
+		},
+		{
+			name:     "distant changes in two hunks",
+			a:        numbered(20, nil),
+			b:        numbered(20, map[int]string{2: "two", 18: "eighteen"}),
+			expected: "@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := tc.expected
+			if expected != "" {
+				expected = "--- a/file\n+++ b/file\n" + expected
+			}
+			if actual := Diff("a/file", "b/file", []byte(tc.a), []byte(tc.b)); actual != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generated
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/beego/bee/v2/logger/colors"
+)
+
+// 所有生成器都通过这里的 WriteFile、MkdirAll 写文件。指定 -dry-run 时文件只保存在内存中，
+// 之后的读取（ReadFile、Exists）能看到内存中的内容，这样后面的步骤和真正执行时一样；
+// 命令结束时 Preview 列出新建的文件，并打印已存在文件的 diff，磁盘上的文件不会被修改。
+
+// DryRun keeps the written files in memory instead of writing them, see Preview
+var DryRun bool
+
+// overlay holds the files and the directories written in dry-run mode
+var overlay = struct {
+	files map[string][]byte
+	dirs  map[string]bool
+	order []string // the paths in the order of their first write
+}{files: map[string][]byte{}, dirs: map[string]bool{}}
+
+func absPath(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+	return filepath.Clean(name)
+}
+
+// WriteFile writes a file, creating its directory
+func WriteFile(name string, data []byte) error {
+	if !DryRun {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(name, data, 0644)
+	}
+	name = absPath(name)
+	if _, ok := overlay.files[name]; !ok {
+		overlay.order = append(overlay.order, name)
+	}
+	overlay.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// MkdirAll creates a directory and its parents
+func MkdirAll(dir string) error {
+	if !DryRun {
+		return os.MkdirAll(dir, 0755)
+	}
+	var created []string
+	for dir = absPath(dir); !overlay.dirs[dir] && !isDir(dir); dir = filepath.Dir(dir) {
+		overlay.dirs[dir] = true
+		created = append(created, dir)
+	}
+	// the parents first
+	for i := len(created) - 1; i >= 0; i-- {
+		overlay.order = append(overlay.order, created[i])
+	}
+	return nil
+}
+
+func isDir(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
+
+// ReadFile reads a file, as written by WriteFile in dry-run mode
+func ReadFile(name string) ([]byte, error) {
+	if DryRun {
+		if data, ok := overlay.files[absPath(name)]; ok {
+			return data, nil
+		}
+	}
+	return os.ReadFile(name)
+}
+
+// Exists reports whether a file or a directory exists, or was written in dry-run mode
+func Exists(name string) bool {
+	if DryRun {
+		abs := absPath(name)
+		if _, ok := overlay.files[abs]; ok || overlay.dirs[abs] {
+			return true
+		}
+	}
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// Preview prints what the dry run would have written: the new files and
+// directories, and the diff of the existing files
+func Preview(out io.Writer) {
+	w := colors.NewColorWriter(out)
+	if len(overlay.order) == 0 {
+		fmt.Fprintln(w, colors.Bold("Dry run: no file would be written"))
+		return
+	}
+	fmt.Fprintln(w, colors.Bold("Dry run: nothing was written, the changes would be:"))
+	wd, _ := os.Getwd()
+	bases := 0
+	for _, name := range overlay.order {
+		if isBase(name) {
+			// the generated versions kept for the merges are not worth a line each
+			bases++
+			continue
+		}
+		display := name
+		if rel, err := filepath.Rel(wd, name); err == nil && !strings.HasPrefix(rel, "..") {
+			display = rel
+		}
+		if overlay.dirs[name] {
+			fmt.Fprintf(w, "\t%s\t %s\n", colors.GreenBold("create"), display+string(filepath.Separator))
+			continue
+		}
+		data := overlay.files[name]
+		old, err := os.ReadFile(name)
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, "\t%s\t %s (%d lines)\n", colors.GreenBold("create"), display, len(splitLines(strings.TrimSuffix(string(data), "\n"))))
+		case bytes.Equal(old, data):
+			fmt.Fprintf(w, "\t%s\t %s\n", colors.BlueBold("identical"), display)
+		default:
+			fmt.Fprintf(w, "\t%s\t %s\n", colors.YellowBold("update"), display)
+			diff := Diff("a/"+filepath.ToSlash(display), "b/"+filepath.ToSlash(display), old, data)
+			for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+				switch {
+				case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+					line = colors.Bold(line)
+				case strings.HasPrefix(line, "+"):
+					line = colors.Green(line)
+				case strings.HasPrefix(line, "-"):
+					line = colors.Red(line)
+				case strings.HasPrefix(line, "@@"):
+					line = colors.Cyan(line)
+				}
+				fmt.Fprintln(w, line)
+			}
+		}
+	}
+	if bases > 0 {
+		fmt.Fprintf(w, "\tand %d generated version(s) kept under %s for the next merges\n", bases, Dir)
+	}
+}
+
+// isBase reports whether name is a generated version kept under Dir
+func isBase(name string) bool {
+	return strings.Contains(filepath.ToSlash(name), "/"+filepath.ToSlash(Dir)+"/")
+}
@@ -0,0 +1,149 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generated
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// dryRun turns the dry-run mode on with an empty overlay until the end of the test
+func dryRun(t *testing.T) {
+	t.Helper()
+	DryRun = true
+	overlay.files, overlay.dirs, overlay.order = map[string][]byte{}, map[string]bool{}, nil
+	t.Cleanup(func() {
+		DryRun = false
+		overlay.files, overlay.dirs, overlay.order = map[string][]byte{}, map[string]bool{}, nil
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.go")
+	if err := os.WriteFile(existing, []byte("package app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dryRun(t)
+
+	file := filepath.Join(dir, "controllers", "post.go")
+	if Exists(file) {
+		t.Errorf("expected no %s before it is written", file)
+	}
+	if err := WriteFile(file, []byte("package controllers\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := MkdirAll(filepath.Join(dir, "views", "post")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(existing, []byte("package main\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the later steps read what was written
+	for name, expected := range map[string]string{file: "package controllers\n", existing: "package main\n"} {
+		data, err := ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, data)
+		}
+	}
+	for _, name := range []string{file, filepath.Join(dir, "views"), filepath.Join(dir, "views", "post"), existing} {
+		if !Exists(name) {
+			t.Errorf("expected %s to exist", name)
+		}
+	}
+	if Exists(filepath.Join(dir, "models")) {
+		t.Errorf("expected no models directory")
+	}
+	if _, err := ReadFile(filepath.Join(dir, "models", "post.go")); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+
+	// nothing is written
+	for _, name := range []string{filepath.Join(dir, "controllers"), filepath.Join(dir, "views")} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("expected no %s on disk, got %v", name, err)
+		}
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "package app\n" {
+		t.Errorf("expected %s unchanged on disk, got %q", existing, data)
+	}
+}
+
+// colorCodes matches the escape sequences of the colors
+var colorCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestPreview(t *testing.T) {
+	dryRun(t)
+	var out bytes.Buffer
+	Preview(&out)
+	if actual := colorCodes.ReplaceAllString(out.String(), ""); actual != "Dry run: no file would be written\n" {
+		t.Errorf("unexpected preview of no file:\n%s", actual)
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{"same.go": "package app\n", "changed.go": "package app\n\nvar a = 1\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := MkdirAll(filepath.Join(dir, "models")); err != nil {
+		t.Fatal(err)
+	}
+	files := []struct{ name, content string }{
+		{"models/post.go", "package models\n\ntype Post struct{}\n"},
+		{"same.go", "package app\n"},
+		{"changed.go", "package app\n\nvar a = 2\n"},
+		{".bee/generated/models/post.go", "package models\n\ntype Post struct{}\n"},
+		{".bee/generated/changed.go", "package app\n\nvar a = 2\n"},
+	}
+	for _, f := range files {
+		if err := WriteFile(filepath.Join(dir, filepath.FromSlash(f.name)), []byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out.Reset()
+	Preview(&out)
+
+	display := func(name string) string {
+		return filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(name)))
+	}
+	expected := strings.Join([]string{
+		"Dry run: nothing was written, the changes would be:",
+		"\tcreate\t " + filepath.Join(dir, "models") + string(filepath.Separator),
+		"\tcreate\t " + filepath.Join(dir, "models", "post.go") + " (3 lines)",
+		"\tidentical\t " + filepath.Join(dir, "same.go"),
+		"\tupdate\t " + filepath.Join(dir, "changed.go"),
+		"--- a/" + display("changed.go"),
+		"+++ b/" + display("changed.go"),
+		"@@ -1,3 +1,3 @@",
+		" package app",
+		" ",
+		"-var a = 1",
+		"+var a = 2",
+		"\tand 2 generated version(s) kept under " + Dir + " for the next merges",
+		"",
+	}, "\n")
+	if actual := colorCodes.ReplaceAllString(out.String(), ""); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generated
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// 三方合并：base 是上次生成的版本，mine 是用户修改后的文件，theirs 是这次生成的版本。
+// 只有一方修改的部分取修改后的内容，双方修改不同的部分用冲突标记包围，由用户解决。
+
+// The markers of the conflicts, as git writes them
+const (
+	MarkerMine   = "<<<<<<< yours"
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>> generated"
+)
+
+// Merge merges the changes from base to mine and the changes from base to
+// theirs, and returns the number of conflicts. The declarations of the Go
+// files are merged one by one, the other files line by line.
+func Merge(name string, base, mine, theirs []byte) ([]byte, int) {
+	if filepath.Ext(name) == ".go" {
+		if merged, conflicts, err := mergeGo(base, mine, theirs); err == nil {
+			return merged, conflicts
+		}
+	}
+	merged, conflicts := mergeText(string(base), string(mine), string(theirs))
+	return []byte(merged), conflicts
+}
+
+// mergeText merges texts line by line
+func mergeText(base, mine, theirs string) (string, int) {
+	switch {
+	case mine == base || mine == theirs:
+		return theirs, 0
+	case theirs == base:
+		return mine, 0
+	}
+	lines, conflicts := mergeLines(splitLines(base), splitLines(mine), splitLines(theirs))
+	return strings.Join(lines, "\n"), conflicts
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// mergeLines is the diff3 merge: the lines of base kept by both mine and
+// theirs split the files into chunks, each chunk is resolved on its own.
+func mergeLines(base, mine, theirs []string) ([]string, int) {
+	inMine, inTheirs := match(base, mine), match(base, theirs)
+	var out []string
+	conflicts := 0
+	o, a, b := 0, 0, 0
+	for {
+		i := o
+		for i < len(base) && (inMine[i] < 0 || inTheirs[i] < 0) {
+			i++
+		}
+		endA, endB := len(mine), len(theirs)
+		if i < len(base) {
+			endA, endB = inMine[i], inTheirs[i]
+		}
+		lines, conflict := resolve(base[o:i], mine[a:endA], theirs[b:endB])
+		out = append(out, lines...)
+		if conflict {
+			conflicts++
+		}
+		if i == len(base) {
+			return out, conflicts
+		}
+		out = append(out, base[i])
+		o, a, b = i+1, endA+1, endB+1
+	}
+}
+
+// resolve merges a chunk changed by mine, by theirs, or by both
+func resolve(base, mine, theirs []string) ([]string, bool) {
+	switch {
+	case equal(mine, base) || equal(mine, theirs):
+		return theirs, false
+	case equal(theirs, base):
+		return mine, false
+	}
+	lines := append([]string{MarkerMine}, mine...)
+	lines = append(lines, MarkerSep)
+	lines = append(lines, theirs...)
+	return append(lines, MarkerTheirs), true
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// match returns, for each line of a, the index of the same line of b in a
+// longest common subsequence of a and b, or -1 when the line is not kept
+func match(a, b []string) []int {
+	// lengths[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lengths := make([][]int32, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			m[i] = j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return m
+}
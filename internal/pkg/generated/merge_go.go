@@ -0,0 +1,378 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generated
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Go 文件按顶层声明合并：每个函数、方法、类型和变量是一个单元，以名字对应三个版本。
+// 用户新增的声明、生成器新增的声明互不影响；同一个声明双方都修改时在声明内部逐行合并。
+// import 按导入的包合并，生成器新增的包加入用户的 import 块，生成器不再使用的包在合并后的代码
+// 也不再引用时被删除。
+// 声明的顺序以用户的文件为准，生成器新增的声明放在它在生成版本中的前一个声明之后。
+
+// goFile is a Go file split into top-level declarations
+type goFile struct {
+	header  string            // the package clause and the comments before it
+	tail    string            // the comments after the last declaration
+	keys    []string          // the keys of the declarations, in order
+	decls   map[string]string // the source of the declarations by key, with their doc comments
+	imports []importDecl
+}
+
+// importDecl is an import declaration
+type importDecl struct {
+	key    string
+	paren  bool
+	specs  []string // the keys of the imported packages, see specKey
+	source map[string]string
+	names  map[string]string // the names the packages are referred to by
+}
+
+// importName returns the name a package is referred to by: its explicit name,
+// or the last element of its path which is not a major version
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	path, _ := strconv.Unquote(spec.Path.Value)
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersion.MatchString(name) {
+		name = elems[len(elems)-2]
+	}
+	return name
+}
+
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// specKey identifies an imported package: its name and its path
+func specKey(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name + " " + spec.Path.Value
+	}
+	return spec.Path.Value
+}
+
+func parseGo(src []byte) (*goFile, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+	text := func(from, to int) string { return strings.TrimSpace(string(src[from:to])) }
+
+	f := &goFile{decls: map[string]string{}}
+	end := offset(file.Name.End())
+	f.header = string(src[:end])
+	seen := map[string]int{}
+	for _, d := range file.Decls {
+		key := declKey(d, src, offset)
+		if seen[key]++; seen[key] > 1 {
+			key += "#" + strconv.Itoa(seen[key])
+		}
+		f.keys = append(f.keys, key)
+		f.decls[key] = text(end, offset(d.End()))
+		if g, ok := d.(*ast.GenDecl); ok && g.Tok == token.IMPORT {
+			imp := importDecl{key: key, paren: g.Lparen.IsValid(), source: map[string]string{}, names: map[string]string{}}
+			for _, s := range g.Specs {
+				spec := s.(*ast.ImportSpec)
+				imp.specs = append(imp.specs, specKey(spec))
+				imp.source[specKey(spec)] = text(offset(spec.Pos()), offset(spec.End()))
+				imp.names[specKey(spec)] = importName(spec)
+			}
+			f.imports = append(f.imports, imp)
+		}
+		end = offset(d.End())
+	}
+	f.tail = text(end, len(src))
+	return f, nil
+}
+
+// declKey returns the key of a top-level declaration, e.g. func (Post).Get or type Post
+func declKey(d ast.Decl, src []byte, offset func(token.Pos) int) string {
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil || len(d.Recv.List) == 0 {
+			return "func " + d.Name.Name
+		}
+		recv := d.Recv.List[0].Type
+		recvType := strings.TrimLeft(string(src[offset(recv.Pos()):offset(recv.End())]), "*")
+		return "func (" + recvType + ")." + d.Name.Name
+	case *ast.GenDecl:
+		var names []string
+		for _, s := range d.Specs {
+			switch s := s.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					names = append(names, n.Name)
+				}
+			}
+		}
+		return d.Tok.String() + " " + strings.Join(names, ",")
+	}
+	return "decl"
+}
+
+// mergeGo merges the Go files declaration by declaration. It fails when one of
+// the files does not parse, e.g. because of the markers of an unresolved conflict.
+func mergeGo(base, mine, theirs []byte) ([]byte, int, error) {
+	b, err := parseGo(base)
+	if err != nil {
+		return nil, 0, err
+	}
+	m, err := parseGo(mine)
+	if err != nil {
+		return nil, 0, err
+	}
+	t, err := parseGo(theirs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	header, conflicts := mergeText(b.header, m.header, t.header)
+
+	// the declarations of mine, then the new declarations of theirs after their
+	// predecessor; the imports are merged last, knowing the packages still used
+	var keys []string
+	merged := map[string]string{}
+	for _, key := range m.keys {
+		if isImport(key) {
+			keys = append(keys, key)
+			merged[key] = ""
+			continue
+		}
+		if text, ok, n := mergeDecl(key, b, m, t); ok {
+			keys = append(keys, key)
+			merged[key] = text
+			conflicts += n
+		}
+	}
+	at := 0
+	for _, key := range t.keys {
+		if _, ok := merged[key]; ok {
+			at = indexOf(keys, key) + 1
+			continue
+		}
+		if _, ok := m.decls[key]; ok || isImport(key) {
+			continue
+		}
+		if text, ok, n := mergeDecl(key, b, m, t); ok {
+			keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+			merged[key] = text
+			conflicts += n
+			at++
+		}
+	}
+	tail, n := mergeText(b.tail, m.tail, t.tail)
+	conflicts += n
+
+	var code strings.Builder
+	for _, key := range keys {
+		code.WriteString(merged[key] + "\n")
+	}
+	used := func(name string) bool {
+		return regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\.`).MatchString(code.String())
+	}
+	imports := mergeImports(b, m, t, used)
+	var withImports []string
+	if text, ok := imports[""]; ok {
+		// mine had no import
+		withImports = append(withImports, "import")
+		merged["import"] = text
+	}
+	for _, key := range keys {
+		if isImport(key) {
+			if merged[key] = imports[key]; merged[key] == "" {
+				continue
+			}
+		}
+		withImports = append(withImports, key)
+	}
+	keys = withImports
+
+	var buf strings.Builder
+	buf.WriteString(header)
+	for _, key := range keys {
+		buf.WriteString("\n\n" + merged[key])
+	}
+	if tail != "" {
+		buf.WriteString("\n\n" + tail)
+	}
+	buf.WriteString("\n")
+	if conflicts > 0 {
+		return []byte(buf.String()), conflicts, nil
+	}
+	src, err := format.Source([]byte(buf.String()))
+	if err != nil {
+		return []byte(buf.String()), 0, nil
+	}
+	return src, 0, nil
+}
+
+func isImport(key string) bool {
+	return strings.HasPrefix(key, "import")
+}
+
+func indexOf(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeDecl merges a declaration. It returns false when the declaration is
+// removed: deleted by the user, or no longer generated and not edited.
+func mergeDecl(key string, b, m, t *goFile) (string, bool, int) {
+	base, inBase := b.decls[key]
+	mine, inMine := m.decls[key]
+	theirs, inTheirs := t.decls[key]
+	switch {
+	case inMine && inTheirs:
+		text, n := mergeText(base, mine, theirs)
+		return text, true, n
+	case inMine && !inBase:
+		// added by the user
+		return mine, true, 0
+	case inMine:
+		// no longer generated
+		if mine == base {
+			return "", false, 0
+		}
+		text, n := mergeText(base, mine, "")
+		return text, true, n
+	case inTheirs && !inBase:
+		// added by the generator
+		return theirs, true, 0
+	case inTheirs:
+		// deleted by the user
+		if theirs == base {
+			return "", false, 0
+		}
+		text, n := mergeText(base, "", theirs)
+		return text, true, n
+	}
+	return "", false, 0
+}
+
+// mergeImports returns the merged import declarations of mine by key, empty
+// when the declaration has no package left, and under the empty key a new
+// declaration when mine has none but the generator imports new packages.
+// The packages no longer imported by the generator are kept while used.
+func mergeImports(b, m, t *goFile, used func(name string) bool) map[string]string {
+	packages := func(f *goFile) map[string]string {
+		p := map[string]string{}
+		for _, imp := range f.imports {
+			for k, v := range imp.source {
+				p[k] = v
+			}
+		}
+		return p
+	}
+	inBase, inMine, inTheirs := packages(b), packages(m), packages(t)
+	var added []string
+	for _, imp := range t.imports {
+		for _, k := range imp.specs {
+			if _, ok := inBase[k]; !ok {
+				if _, ok := inMine[k]; !ok {
+					added = append(added, imp.source[k])
+				}
+			}
+		}
+	}
+	removed := map[string]bool{}
+	for _, imp := range b.imports {
+		for _, k := range imp.specs {
+			if _, ok := inTheirs[k]; ok {
+				continue
+			}
+			if name := imp.names[k]; name != "." && !used(name) {
+				removed[k] = true
+			}
+		}
+	}
+
+	merged := map[string]string{}
+	for i, imp := range m.imports {
+		var specs []string
+		for _, k := range imp.specs {
+			if !removed[k] {
+				specs = append(specs, imp.source[k])
+			}
+		}
+		if i == len(m.imports)-1 {
+			specs = append(specs, added...)
+		}
+		switch {
+		case len(specs) == len(imp.specs) && (i < len(m.imports)-1 || len(added) == 0):
+			merged[imp.key] = m.decls[imp.key]
+		case len(specs) == 0:
+			merged[imp.key] = ""
+		case !imp.paren:
+			merged[imp.key] = importBlock(specs)
+		default:
+			merged[imp.key] = editImportBlock(m.decls[imp.key], imp, removed, added, i == len(m.imports)-1)
+		}
+	}
+	if len(m.imports) == 0 && len(added) > 0 {
+		merged[""] = importBlock(added)
+	}
+	return merged
+}
+
+func importBlock(specs []string) string {
+	return "import (\n\t" + strings.Join(specs, "\n\t") + "\n)"
+}
+
+// editImportBlock removes and adds packages to an import block, keeping the
+// groups and the comments of the user
+func editImportBlock(decl string, imp importDecl, removed map[string]bool, added []string, last bool) string {
+	lines := strings.Split(decl, "\n")
+	var out []string
+	for _, line := range lines {
+		drop := false
+		for k := range removed {
+			if src, ok := imp.source[k]; ok && strings.HasPrefix(strings.TrimSpace(line), src) {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			out = append(out, line)
+		}
+	}
+	if last && len(added) > 0 {
+		// before the closing parenthesis
+		closing := out[len(out)-1]
+		out = out[:len(out)-1]
+		for _, src := range added {
+			out = append(out, "\t"+src)
+		}
+		out = append(out, closing)
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,121 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generated
+
+import (
+	"strings"
+	"testing"
+)
+
+const baseGo = `package controllers
+
+import (
+	"strconv"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+type PostController struct {
+	beego.Controller
+}
+
+func (c *PostController) Get() {
+	id, _ := strconv.Atoi(c.Ctx.Input.Param(":id"))
+	c.Data["json"] = id
+	c.ServeJSON()
+}
+
+func (c *PostController) Delete() {
+	c.ServeJSON()
+}
+`
+
+func TestMergeText(t *testing.T) {
+	testCases := []struct {
+		base, mine, theirs string
+		expected           string
+		conflicts          int
+	}{
+		{base: "a\nb\nc\nd", mine: "A\nb\nc\nd", theirs: "a\nb\nc\nD", expected: "A\nb\nc\nD"},
+		{base: "a\nb\nc", mine: "a\nb\nc\nd", theirs: "z\na\nb\nc", expected: "z\na\nb\nc\nd"},
+		{base: "a\nb\nc", mine: "a\nX\nc", theirs: "a\nX\nc", expected: "a\nX\nc"},
+		{
+			base: "a\nb\nc", mine: "a\nX\nc", theirs: "a\nY\nc",
+			expected:  "a\n" + MarkerMine + "\nX\n" + MarkerSep + "\nY\n" + MarkerTheirs + "\nc",
+			conflicts: 1,
+		},
+	}
+	for _, tc := range testCases {
+		merged, conflicts := mergeText(tc.base, tc.mine, tc.theirs)
+		if merged != tc.expected || conflicts != tc.conflicts {
+			t.Errorf("mergeText(%q, %q, %q) = %q, %d; want %q, %d", tc.base, tc.mine, tc.theirs, merged, conflicts, tc.expected, tc.conflicts)
+		}
+	}
+}
+
+func TestMergeGo(t *testing.T) {
+	// the user adds a method and a field, the generator changes Get, drops
+	// Delete and imports a new package
+	mine := strings.Replace(baseGo, "\tbeego.Controller\n", "\tbeego.Controller\n\tcache map[int]string\n", 1) + `
+// Prepare is added by hand
+func (c *PostController) Prepare() {
+	c.cache = map[int]string{}
+}
+`
+	theirs := strings.NewReplacer(
+		"\"strconv\"\n", "\"fmt\"\n\t\"strconv\"\n",
+		"c.Data[\"json\"] = id", "c.Data[\"json\"] = fmt.Sprint(id)",
+		"\nfunc (c *PostController) Delete() {\n\tc.ServeJSON()\n}\n", "",
+	).Replace(baseGo)
+
+	merged, conflicts := Merge("post.go", []byte(baseGo), []byte(mine), []byte(theirs))
+	if conflicts != 0 {
+		t.Fatalf("unexpected conflicts:\n%s", merged)
+	}
+	for _, s := range []string{"\"fmt\"", "cache map[int]string", "fmt.Sprint(id)", "func (c *PostController) Prepare()"} {
+		if !strings.Contains(string(merged), s) {
+			t.Errorf("the merged file misses %s:\n%s", s, merged)
+		}
+	}
+	if strings.Contains(string(merged), "Delete") {
+		t.Errorf("the merged file still has Delete:\n%s", merged)
+	}
+
+	// both change the same line of Get
+	mine = strings.Replace(baseGo, "c.Data[\"json\"] = id", "c.Data[\"json\"] = id * 2", 1)
+	merged, conflicts = Merge("post.go", []byte(baseGo), []byte(mine), []byte(theirs))
+	if conflicts != 1 || !strings.Contains(string(merged), MarkerMine+"\n\tc.Data[\"json\"] = id * 2\n"+MarkerSep) {
+		t.Errorf("expected a conflict in Get, got %d:\n%s", conflicts, merged)
+	}
+
+	// the generator no longer imports strconv, still used by a method added by hand
+	theirs = strings.NewReplacer(
+		"\t\"strconv\"\n\n", "",
+		"id, _ := strconv.Atoi(c.Ctx.Input.Param(\":id\"))", "id := c.Ctx.Input.Param(\":id\")",
+	).Replace(baseGo)
+	mine = baseGo + `
+func (c *PostController) Put() {
+	c.Data["json"] = strconv.Itoa(1)
+}
+`
+	merged, conflicts = Merge("post.go", []byte(baseGo), []byte(mine), []byte(theirs))
+	if conflicts != 0 || !strings.Contains(string(merged), "\"strconv\"") {
+		t.Errorf("strconv should be kept:\n%s", merged)
+	}
+	merged, _ = Merge("post.go", []byte(baseGo), []byte(baseGo+"\n// edited\n"), []byte(theirs))
+	if strings.Contains(string(merged), "\"strconv\"") {
+		t.Errorf("strconv should be removed:\n%s", merged)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package generated writes the generated files. The last generated version of
+// every file is kept under .bee/generated, so that a file generated again is
+// merged with the changes made by hand since.
+package generated
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 重新生成文件时，用 .bee/generated 中保存的上次生成的版本作为共同祖先，
+// 把用户的修改和新生成的内容做三方合并。保存的版本应与源代码一起提交到版本库。
+
+// Dir is the directory of the last generated versions, relative to the application
+var Dir = filepath.Join(".bee", "generated")
+
+// ErrNoBase is returned when the file exists but was not generated by bee,
+// or was generated before the generated versions were kept
+var ErrNoBase = errors.New("the file exists and its generated version is unknown")
+
+// The actions of Write
+const (
+	Create    = "create"    // the file did not exist
+	Identical = "identical" // the file is unchanged
+	Update    = "update"    // the file was not edited, it is replaced
+	Merged    = "merge"     // the edits of the file were merged with the new version
+	Conflict  = "conflict"  // the file has conflicts to resolve
+)
+
+// Result is the result of Write
+type Result struct {
+	Action    string
+	Conflicts int // the number of conflicts
+}
+
+// basePath returns the path of the last generated version of file, which
+// must be in the application
+func basePath(appPath, file string) (string, error) {
+	rel, err := filepath.Rel(appPath, file)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("'%s' is outside of the application '%s'", file, appPath)
+	}
+	return filepath.Join(appPath, Dir, rel), nil
+}
+
+// Base returns the last generated version of file, or nil when there is none
+func Base(appPath, file string) []byte {
+	path, err := basePath(appPath, file)
+	if err != nil {
+		return nil
+	}
+	content, err := ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return content
+}
+
+// Write writes content, generated for file of the application at appPath.
+// An existing file is merged with content when it was edited by hand, it
+// fails with ErrNoBase when its last generated version is unknown. The files
+// outside of the application are not written.
+func Write(appPath, file string, content []byte) (Result, error) {
+	if _, err := basePath(appPath, file); err != nil {
+		return Result{}, err
+	}
+	result, err := write(appPath, file, content)
+	if err != nil {
+		return result, err
+	}
+	return result, SaveBase(appPath, file, content)
+}
+
+func write(appPath, file string, content []byte) (Result, error) {
+	current, err := ReadFile(file)
+	if os.IsNotExist(err) {
+		return Result{Action: Create}, WriteFile(file, content)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	if bytes.Equal(current, content) {
+		return Result{Action: Identical}, nil
+	}
+	base := Base(appPath, file)
+	switch {
+	case base == nil:
+		return Result{}, ErrNoBase
+	case bytes.Equal(current, base):
+		return Result{Action: Update}, WriteFile(file, content)
+	case bytes.Equal(content, base):
+		// only edited by hand
+		return Result{Action: Identical}, nil
+	}
+	merged, conflicts := Merge(file, base, current, content)
+	result := Result{Action: Merged, Conflicts: conflicts}
+	if conflicts > 0 {
+		result.Action = Conflict
+	}
+	return result, WriteFile(file, merged)
+}
+
+// SaveBase records content as the last generated version of file
+func SaveBase(appPath, file string, content []byte) error {
+	path, err := basePath(appPath, file)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, content)
+}
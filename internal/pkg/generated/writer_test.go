@@ -0,0 +1,84 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package generated
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	app := t.TempDir()
+	file := filepath.Join(app, "models", "post.go")
+	steps := []struct {
+		name    string
+		edit    string // the content edited by hand before the step
+		content string
+		action  string
+		err     error
+	}{
+		{name: "created", content: "package models\n", action: Create},
+		{name: "unchanged", content: "package models\n", action: Identical},
+		{name: "not edited", content: "package models\n\ntype Post struct{}\n", action: Update},
+		{name: "edited", edit: "// Package models\npackage models\n\ntype Post struct{}\n", content: "package models\n\ntype Post struct{ Id int }\n", action: Merged},
+	}
+	for _, step := range steps {
+		if step.edit != "" {
+			if err := os.WriteFile(file, []byte(step.edit), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		result, err := Write(app, file, []byte(step.content))
+		if err != nil {
+			t.Fatalf("%s: %s", step.name, err)
+		}
+		if result.Action != step.action {
+			t.Errorf("%s: expected the action %s, got %s", step.name, step.action, result.Action)
+		}
+		if base := Base(app, file); string(base) != step.content {
+			t.Errorf("%s: expected the generated version %q, got %q", step.name, step.content, base)
+		}
+	}
+	if data, _ := os.ReadFile(file); string(data) != "// Package models\npackage models\n\ntype Post struct{ Id int }\n" {
+		t.Errorf("expected the merged file, got:\n%s", data)
+	}
+
+	// a file without generated version is not replaced
+	handmade := filepath.Join(app, "main.go")
+	if err := os.WriteFile(handmade, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Write(app, handmade, []byte("package main\n\nfunc main() {}\n")); err != ErrNoBase {
+		t.Errorf("expected ErrNoBase, got %v", err)
+	}
+
+	// nor a file outside of the application
+	outside := filepath.Join(filepath.Dir(app), "outside.go")
+	if _, err := Write(app, outside, []byte("package outside\n")); err == nil || !strings.Contains(err.Error(), "outside of the application") {
+		t.Errorf("expected an error for a file outside of the application, got %v", err)
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Errorf("expected no %s, got %v", outside, err)
+	}
+	if err := SaveBase(app, outside, []byte("package outside\n")); err == nil {
+		t.Errorf("expected an error saving the generated version of a file outside of the application")
+	}
+	// the generated version was .bee/generated/../outside.go
+	if _, err := os.Stat(filepath.Join(app, ".bee", "outside.go")); !os.IsNotExist(err) {
+		t.Errorf("expected no generated version out of %s, got %v", Dir, err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+//go:build ignore
+
+// gen downloads the swagger-ui-dist package of the given version and copies
+// the files used by the index into dist. Run it with go generate and commit dist.
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var files = []string{
+	"swagger-ui.css",
+	"swagger-ui-bundle.js",
+	"swagger-ui-standalone-preset.js",
+	"oauth2-redirect.html",
+	"favicon-16x16.png",
+	"favicon-32x32.png",
+	"LICENSE",
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: go run gen.go <swagger-ui-dist version>")
+	}
+	url := fmt.Sprintf("https://registry.npmjs.org/swagger-ui-dist/-/swagger-ui-dist-%s.tgz", os.Args[1])
+	resp, err := http.Get(url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("%s: %s", url, resp.Status)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	wanted := map[string]bool{}
+	for _, f := range files {
+		wanted[f] = true
+	}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		name := strings.TrimPrefix(h.Name, "package/")
+		if !wanted[name] {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join("dist", name), data, 0644); err != nil {
+			log.Fatal(err)
+		}
+		delete(wanted, name)
+	}
+	for name := range wanted {
+		log.Fatalf("%s is not in swagger-ui-dist %s", name, os.Args[1])
+	}
+}
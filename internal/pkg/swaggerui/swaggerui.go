@@ -0,0 +1,194 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package swaggerui embeds the Swagger UI assets, so that bee can write them
+// into the application or serve them without downloading anything.
+package swaggerui
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/beego/bee/v2/internal/pkg/generated"
+)
+
+// Swagger UI 的静态文件通过 go:generate 从 swagger-ui-dist 的 npm 包中获取并提交到 dist 目录，
+// 构建时嵌入 bee。index.html 由 bee 维护，SetURLs 将其中的 url 配置指向应用的文档。
+
+//go:generate go run gen.go 5.18.2
+
+// Version is the vendored version of swagger-ui-dist
+const Version = "5.18.2"
+
+//go:embed dist
+var assets embed.FS
+
+// URL is a document listed by Swagger UI
+type URL struct {
+	Name string
+	URL  string // relative to the swagger directory
+}
+
+// Assets returns the Swagger UI files
+func Assets() fs.FS {
+	dist, err := fs.Sub(assets, "dist")
+	if err != nil {
+		panic(err)
+	}
+	return dist
+}
+
+// WriteTo writes the Swagger UI files into dir, the index lists the documents already in dir.
+// The files are kept in memory in dry-run mode, see generated.DryRun.
+func WriteTo(dir string) error {
+	dist := Assets()
+	return fs.WalkDir(dist, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(dist, name)
+		if err != nil {
+			return err
+		}
+		if name == "index.html" {
+			if docs := Documents(dir); len(docs) > 0 {
+				data = SetURLs(data, docs)
+			}
+		}
+		return generated.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), data)
+	})
+}
+
+// Documents returns the swagger.json and openapi.json documents in dir and in its subdirectories
+func Documents(dir string) []URL {
+	var docs []URL
+	filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if info.Name() != "swagger.json" && info.Name() != "openapi.json" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		// the documents are named by their namespace directory
+		name := path.Dir(rel)
+		if name == "." {
+			name = "default"
+		}
+		docs = append(docs, URL{Name: name, URL: rel})
+		return nil
+	})
+	count := map[string]int{}
+	for _, doc := range docs {
+		count[doc.Name]++
+	}
+	for i, doc := range docs {
+		if count[doc.Name] > 1 {
+			// both swagger.json and openapi.json
+			docs[i].Name += " (" + strings.TrimSuffix(path.Base(doc.URL), ".json") + ")"
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	return docs
+}
+
+var (
+	bundleSign = []byte("SwaggerUIBundle(")
+	urlRegexp  = regexp.MustCompile(`^\s*urls?\s*:`)
+)
+
+// SetURLs points the SwaggerUIBundle configuration of the index at the
+// documents: url for a single document, urls for several ones.
+func SetURLs(index []byte, docs []URL) []byte {
+	var config string
+	if len(docs) == 1 {
+		config = fmt.Sprintf(`        url: %q,`, docs[0].URL)
+	} else {
+		urls := make([]string, 0, len(docs))
+		for _, doc := range docs {
+			urls = append(urls, fmt.Sprintf(`{url: %q, name: %q}`, doc.URL, doc.Name))
+		}
+		config = fmt.Sprintf(`        urls: [%s],`, strings.Join(urls, ", "))
+	}
+
+	var out bytes.Buffer
+	inBundle, replaced, inList := false, false, false
+	for _, line := range bytes.SplitAfter(index, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if inList {
+			// the rest of a urls list written on several lines
+			inList = !bytes.Contains(line, []byte("]"))
+			continue
+		}
+		if bytes.Contains(line, bundleSign) {
+			inBundle = true
+		}
+		if !inBundle || !urlRegexp.Match(line) {
+			out.Write(line)
+			continue
+		}
+		if bytes.Contains(line, []byte("[")) && !bytes.Contains(line, []byte("]")) {
+			inList = true
+		}
+		if !replaced {
+			out.WriteString(config + "\n")
+			replaced = true
+		}
+	}
+	return out.Bytes()
+}
+
+// Handler serves Swagger UI with the documents of the docs directory
+func Handler(docs string) http.Handler {
+	dist := Assets()
+	files := http.FileServer(http.Dir(docs))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" || name == "index.html" {
+			index, err := fs.ReadFile(dist, "index.html")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if urls := Documents(docs); len(urls) > 0 {
+				index = SetURLs(index, urls)
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(index)
+			return
+		}
+		if _, err := fs.Stat(dist, name); err == nil {
+			http.FileServer(http.FS(dist)).ServeHTTP(w, r)
+			return
+		}
+		// the documents are read from the disk, so they are up to date after bee generate docs
+		w.Header().Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}
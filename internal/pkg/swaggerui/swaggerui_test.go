@@ -0,0 +1,239 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package swaggerui
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/beego/bee/v2/internal/pkg/generated"
+)
+
+// bundle returns the SwaggerUIBundle call of an index with the given url configuration
+func bundle(config string) string {
+	return `<script>
+    window.onload = function() {
+      const ui = SwaggerUIBundle({
+` + config + `
+        dom_id: '#swagger-ui',
+        layout: "StandaloneLayout"
+      });
+    };
+</script>
+`
+}
+
+func TestSetURLs(t *testing.T) {
+	several := []URL{{Name: "v1", URL: "v1/swagger.json"}, {Name: "v2", URL: "v2/swagger.json"}, {Name: "v3", URL: "v3/swagger.json"}}
+	listed := `        urls: [{url: "v1/swagger.json", name: "v1"}, {url: "v2/swagger.json", name: "v2"}, {url: "v3/swagger.json", name: "v3"}],`
+
+	testCases := []struct {
+		name     string
+		index    string
+		docs     []URL
+		expected string
+	}{
+		{
+			name:     "single document",
+			index:    bundle(`        url: "swagger.json",`),
+			docs:     []URL{{URL: "openapi.json"}},
+			expected: bundle(`        url: "openapi.json",`),
+		},
+		{
+			name:     "single document to several namespaces",
+			index:    bundle(`        url: "swagger.json",`),
+			docs:     several,
+			expected: bundle(listed),
+		},
+		{
+			name:     "several namespaces to a single document",
+			index:    bundle(listed),
+			docs:     []URL{{URL: "swagger.json"}},
+			expected: bundle(`        url: "swagger.json",`),
+		},
+		{
+			name: "urls list written on several lines",
+			index: bundle(`        urls: [
+          {url: "v1/swagger.json", name: "v1"},
+          {url: "old/swagger.json", name: "old"}
+        ],`),
+			docs:     several,
+			expected: bundle(listed),
+		},
+		{
+			name:     "url and urls both set",
+			index:    bundle("        url: \"swagger.json\",\n        urls: [{url: \"v1/swagger.json\", name: \"v1\"}],"),
+			docs:     several[:2],
+			expected: bundle(`        urls: [{url: "v1/swagger.json", name: "v1"}, {url: "v2/swagger.json", name: "v2"}],`),
+		},
+		{
+			name:     "url outside of the bundle",
+			index:    "<p>\nurl: kept\n</p>\n" + bundle(`        url: "swagger.json",`),
+			docs:     several[:1],
+			expected: "<p>\nurl: kept\n</p>\n" + bundle(`        url: "v1/swagger.json",`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := string(SetURLs([]byte(tc.index), tc.docs))
+			if actual != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+			// setting the same documents again changes nothing
+			if again := string(SetURLs([]byte(actual), tc.docs)); again != actual {
+				t.Errorf("expected the same index, got:\n%s", again)
+			}
+		})
+	}
+
+	// the vendored index
+	index, err := fs.ReadFile(Assets(), "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := string(SetURLs(index, several))
+	if !strings.Contains(actual, listed+"\n        dom_id") || strings.Contains(actual, `url: "swagger.json"`) {
+		t.Errorf("expected the urls in the vendored index, got:\n%s", actual)
+	}
+}
+
+// writeDocs writes the documents of three namespaces, v2 having both specifications
+func writeDocs(t *testing.T, dir string) {
+	t.Helper()
+	for _, name := range []string{"v1/swagger.json", "v2/swagger.json", "v2/openapi.json", "admin/api/swagger.json", "v1/notes.json"} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(`{"swagger": "2.0"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	dir := t.TempDir()
+	if docs := Documents(dir); len(docs) != 0 {
+		t.Errorf("expected no document, got %v", docs)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "swagger.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if docs, expected := Documents(dir), []URL{{Name: "default", URL: "swagger.json"}}; !reflect.DeepEqual(docs, expected) {
+		t.Errorf("expected %v, got %v", expected, docs)
+	}
+
+	dir = t.TempDir()
+	writeDocs(t, dir)
+	expected := []URL{
+		{Name: "admin/api", URL: "admin/api/swagger.json"},
+		{Name: "v1", URL: "v1/swagger.json"},
+		{Name: "v2 (openapi)", URL: "v2/openapi.json"},
+		{Name: "v2 (swagger)", URL: "v2/swagger.json"},
+	}
+	if docs := Documents(dir); !reflect.DeepEqual(docs, expected) {
+		t.Errorf("expected %v, got %v", expected, docs)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	docs := t.TempDir()
+	writeDocs(t, docs)
+	mux := http.NewServeMux()
+	mux.Handle("/swagger/", http.StripPrefix("/swagger", Handler(docs)))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	testCases := []struct {
+		path     string
+		status   int
+		contains string
+		noCache  bool
+	}{
+		{path: "/swagger/", status: http.StatusOK, contains: `urls: [{url: "admin/api/swagger.json", name: "admin/api"}, {url: "v1/swagger.json", name: "v1"}`},
+		{path: "/swagger/index.html", status: http.StatusOK, contains: `{url: "v2/openapi.json", name: "v2 (openapi)"}`},
+		{path: "/swagger/swagger-ui.css", status: http.StatusOK, contains: ".swagger-ui"},
+		{path: "/swagger/swagger-ui-bundle.js", status: http.StatusOK},
+		{path: "/swagger/v1/swagger.json", status: http.StatusOK, contains: `"swagger": "2.0"`, noCache: true},
+		{path: "/swagger/admin/api/swagger.json", status: http.StatusOK, noCache: true},
+		{path: "/swagger/missing.json", status: http.StatusNotFound, noCache: true},
+	}
+	for _, tc := range testCases {
+		resp, err := http.Get(server.URL + tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s: expected the status %d, got %d", tc.path, tc.status, resp.StatusCode)
+		}
+		if !strings.Contains(string(body), tc.contains) {
+			t.Errorf("%s: expected %q in:\n%.500s", tc.path, tc.contains, body)
+		}
+		if noCache := resp.Header.Get("Cache-Control") == "no-cache"; noCache != tc.noCache {
+			t.Errorf("%s: expected no-cache %v, got the headers %v", tc.path, tc.noCache, resp.Header)
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	dir := t.TempDir()
+	writeDocs(t, dir)
+
+	// nothing is written in dry-run mode
+	generated.DryRun = true
+	err := WriteTo(dir)
+	generated.DryRun = false
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index.html")); !os.IsNotExist(err) {
+		t.Errorf("expected no index in dry-run mode, got %v", err)
+	}
+
+	if err := WriteTo(dir); err != nil {
+		t.Fatal(err)
+	}
+	err = fs.WalkDir(Assets(), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("expected %s: %s", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `{url: "v1/swagger.json", name: "v1"}`) {
+		t.Errorf("expected the documents in the index, got:\n%s", index)
+	}
+}
@@ -19,10 +19,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/beego/bee/v2/logger/colors"
 )
@@ -53,10 +56,21 @@ var logLevel = levelInfo
 // BeeLogger logs logging records to the specified io.Writer
 // 该结构体包含了日志记录的核心方法，比如设置输出、日志格式化等
 type BeeLogger struct {
-	mu     sync.Mutex // 互斥锁，确保日志记录是线程安全的
-	output io.Writer  // 日志输出目标，可以是 os.Stdout、os.Stderr 或其他 io.Writer
+	mu        sync.Mutex   // 互斥锁，确保日志记录是线程安全的
+	output    io.Writer    // 日志输出目标，可以是 os.Stdout、os.Stderr 或其他 io.Writer
+	secretsMu sync.RWMutex // 保护 secrets 的读写锁
+	secrets   []string     // 需要在日志输出中屏蔽的敏感值
+	onFatal   []func()     // Fatal 和 Fatalf 退出进程前调用的函数
 }
 
+// secretMask replaces secret values in log messages
+const secretMask = "******"
+
+// minSecretLength is the minimum length of a value masked wherever it appears.
+// Shorter values are only masked where they are not part of a longer word, so
+// that they do not mask unrelated parts of the log messages.
+const minSecretLength = 4
+
 // LogRecord represents a log record and contains the timestamp when the record
 // was created, an increasing id, level and the actual formatted log line.
 // LogRecord 用于存储单个日志记录的内容
@@ -116,6 +130,65 @@ func (l *BeeLogger) SetOutput(w io.Writer) {
 	l.output = colors.NewColorWriter(w)
 }
 
+// AddSecret registers a sensitive value, such as a password, which is
+// replaced by a mask in every log message written afterwards.
+// 注册敏感值（例如数据库密码），之后输出的日志中该值会被替换为掩码
+func (l *BeeLogger) AddSecret(secret string) {
+	if secret == "" {
+		return
+	}
+	l.secretsMu.Lock()
+	defer l.secretsMu.Unlock()
+	for _, s := range l.secrets {
+		if s == secret {
+			return
+		}
+	}
+	l.secrets = append(l.secrets, secret)
+	// 优先替换较长的值，避免某个敏感值是另一个的子串时只被部分屏蔽
+	sort.Slice(l.secrets, func(i, j int) bool { return len(l.secrets[i]) > len(l.secrets[j]) })
+}
+
+// Mask replaces all registered secrets in the message
+func (l *BeeLogger) Mask(message string) string {
+	l.secretsMu.RLock()
+	defer l.secretsMu.RUnlock()
+	for _, s := range l.secrets {
+		if len(s) < minSecretLength {
+			message = maskWord(message, s)
+		} else {
+			message = strings.Replace(message, s, secretMask, -1)
+		}
+	}
+	return message
+}
+
+// maskWord replaces the occurrences of secret in message which are not
+// preceded nor followed by a letter or a digit
+func maskWord(message, secret string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(message, secret)
+		if i < 0 {
+			b.WriteString(message)
+			return b.String()
+		}
+		end := i + len(secret)
+		if (i > 0 && isWordByte(message[i-1])) || (end < len(message) && isWordByte(message[end])) {
+			b.WriteString(message[:i+1])
+			message = message[i+1:]
+			continue
+		}
+		b.WriteString(message[:i])
+		b.WriteString(secretMask)
+		message = message[end:]
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= utf8.RuneSelf
+}
+
 // Now returns the current local time in the specified layout
 func Now(layout string) string {
 	return time.Now().Format(layout)
@@ -189,7 +262,7 @@ func (l *BeeLogger) mustLog(level int, message string, args ...interface{}) {
 	record := LogRecord{
 		ID:      fmt.Sprintf("%04d", atomic.AddUint64(&sequenceNo, 1)),
 		Level:   l.getColorLevel(level),
-		Message: fmt.Sprintf(message, args...),
+		Message: l.Mask(fmt.Sprintf(message, args...)),
 	}
 
 	err := logRecordTemplate.Execute(l.output, record)
@@ -213,7 +286,7 @@ func (l *BeeLogger) mustLogDebug(message string, file string, line int, args ...
 	record := LogRecord{
 		ID:       fmt.Sprintf("%04d", atomic.AddUint64(&sequenceNo, 1)),
 		Level:    l.getColorLevel(levelDebug),
-		Message:  fmt.Sprintf(message, args...),
+		Message:  l.Mask(fmt.Sprintf(message, args...)),
 		LineNo:   line,
 		Filename: filepath.Base(file),
 	}
@@ -266,12 +339,33 @@ func (l *BeeLogger) Errorf(message string, vars ...interface{}) {
 // Fatal outputs a fatal log message and exists
 func (l *BeeLogger) Fatal(message string) {
 	l.mustLog(levelFatal, message)
-	os.Exit(255)
+	l.exit()
 }
 
 // Fatalf outputs a formatted log message and exists
 func (l *BeeLogger) Fatalf(message string, vars ...interface{}) {
 	l.mustLog(levelFatal, message, vars...)
+	l.exit()
+}
+
+// OnFatal registers a function called by Fatal and Fatalf before the process
+// exits, e.g. to print the output pending at the end of the command
+func (l *BeeLogger) OnFatal(f func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onFatal = append(l.onFatal, f)
+}
+
+// exit calls the OnFatal functions and exits with the status of the fatal messages
+func (l *BeeLogger) exit() {
+	l.mu.Lock()
+	// a fatal message of one of the functions exits at once
+	funcs := l.onFatal
+	l.onFatal = nil
+	l.mu.Unlock()
+	for _, f := range funcs {
+		f()
+	}
 	os.Exit(255)
 }
 
@@ -0,0 +1,72 @@
+// Copyright 2013 bee authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License"): you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package beeLogger
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	l := &BeeLogger{}
+	for _, secret := range []string{"", "s3cr3t", "ab", "x"} {
+		l.AddSecret(secret)
+	}
+
+	testCases := []struct {
+		message  string
+		expected string
+	}{
+		{"root:s3cr3t@tcp(127.0.0.1:3306)/test", "root:******@tcp(127.0.0.1:3306)/test"},
+		{"password=s3cr3tive", "password=******ive"},
+		{"root:ab@tcp(127.0.0.1:3306)/tab", "root:******@tcp(127.0.0.1:3306)/tab"},
+		{"ab", "******"},
+		{"abc about a b x ab", "abc about a b ****** ******"},
+		{"no secret", "no secret"},
+	}
+	for _, tc := range testCases {
+		if got := l.Mask(tc.message); got != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.message, tc.expected, got)
+		}
+	}
+}
+
+func TestOnFatal(t *testing.T) {
+	if os.Getenv("BEE_TEST_FATAL") == "1" {
+		l := &BeeLogger{output: os.Stdout}
+		l.OnFatal(func() {
+			fmt.Println("first")
+			// exits at once
+			l.Fatal("again")
+		})
+		l.OnFatal(func() { fmt.Println("second") })
+		l.Fatalf("the %s failed", "generator")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestOnFatal$")
+	cmd.Env = append(os.Environ(), "BEE_TEST_FATAL=1")
+	out, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 255 {
+		t.Fatalf("expected the exit status 255, got %v: %s", err, out)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "the generator failed") || lines[1] != "first" || !strings.Contains(lines[2], "again") {
+		t.Errorf("expected the fatal message, then the first function exiting, got:\n%s", out)
+	}
+}
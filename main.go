@@ -21,6 +21,8 @@ import (
 	"github.com/beego/bee/v2/cmd"
 	"github.com/beego/bee/v2/cmd/commands"
 	"github.com/beego/bee/v2/config"
+	"github.com/beego/bee/v2/internal/pkg/generated"
+	beeLogger "github.com/beego/bee/v2/logger"
 	"github.com/beego/bee/v2/utils"
 )
 
@@ -77,8 +79,18 @@ func main() {
 
 			// 加载配置文件（config.LoadConfig()）
 			config.LoadConfig() // 加载一些全局配置，确保命令执行时使用正确的配置信息
+			// 指定了 -dry-run 时，文件只写入了内存，命令结束时打印将要新建的文件和已存在文件的 diff；
+			// 生成器通过 beeLogger.Log.Fatalf 出错退出时，也先打印出错前的修改
+			preview := func() {
+				if generated.DryRun {
+					generated.Preview(os.Stdout)
+				}
+			}
+			beeLogger.Log.OnFatal(preview)
 			// 执行 Run 方法，完成命令逻辑
-			os.Exit(c.Run(c, args))
+			code := c.Run(c, args)
+			preview()
+			os.Exit(code)
 			return
 		}
 	}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"go/format"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -33,6 +34,7 @@ import (
 	"unicode"
 
 	"github.com/beego/bee/v2/config"
+	"github.com/beego/bee/v2/internal/pkg/generated"
 	"github.com/beego/bee/v2/internal/pkg/system"
 	beeLogger "github.com/beego/bee/v2/logger"
 	"github.com/beego/bee/v2/logger/colors"
@@ -251,9 +253,15 @@ func CamelCase(in string) string {
 
 // formatSourceCode formats source files
 func FormatSourceCode(filename string) {
-	cmd := exec.Command("gofmt", "-w", filename)
-	if err := cmd.Run(); err != nil {
-		beeLogger.Log.Warnf("Error while running gofmt: %s", err)
+	src, err := generated.ReadFile(filename)
+	if err == nil {
+		src, err = format.Source(src)
+	}
+	if err == nil {
+		err = generated.WriteFile(filename, src)
+	}
+	if err != nil {
+		beeLogger.Log.Warnf("Could not format '%s': %s", filename, err)
 	}
 }
 
@@ -273,11 +281,7 @@ func MustCheck(err error) {
 
 // WriteToFile creates a file and writes content to it
 func WriteToFile(filename, content string) {
-	f, err := os.Create(filename)
-	MustCheck(err)
-	defer CloseFile(f)
-	_, err = f.WriteString(content)
-	MustCheck(err)
+	MustCheck(generated.WriteFile(filename, []byte(content)))
 }
 
 // __FILE__ returns the file name in which the function was invoked